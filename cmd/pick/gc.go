@@ -0,0 +1,66 @@
+package pick
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/pick"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func NewGCCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "gc",
+		Usage: "delete the temporary branches left behind by crashed or timed out picks",
+		Flags: append(providerFlags(), []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "older-than",
+				Usage: "Only delete the branches of runs started longer ago, the branches of running picks are kept",
+				Value: time.Hour,
+			},
+			&cli.BoolFlag{
+				Name:  "include-legacy",
+				Usage: "Delete the pick-<branch>-<sha> branches of older norn versions too, regardless of --older-than as their age is unknown",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "List the branches to delete, nothing is deleted",
+				Value: false,
+			},
+		}...),
+		Action: func(c *cli.Context) error {
+			logrus.Debugf("Start deleting temporary branches")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			provider, err := newProvider(ctx, c)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			service := pick.NewPickService(provider)
+			result, err := service.GC(ctx, &pick.GCOption{
+				Repo:          c.String("repo"),
+				MaxAge:        c.Duration("older-than"),
+				IncludeLegacy: c.Bool("include-legacy"),
+				DryRun:        c.Bool("dry-run"),
+			})
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			if len(result) == 0 {
+				logrus.Infof("No temporary branch found")
+				return cli.Exit("", 0)
+			}
+			pick.PrintGC(c.App.Writer, result)
+			for _, r := range result {
+				if r.Status == pick.FailedStatus {
+					return cli.Exit("failed to delete some temporary branches", 1)
+				}
+			}
+			return cli.Exit("", 0)
+		},
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"github.com/kentio/norn/internal"
 	"github.com/kentio/norn/pkg/common"
 	"github.com/kentio/norn/pkg/pick"
+	"github.com/kentio/norn/pkg/sign"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"os"
+	"os/signal"
+	"syscall"
+	"text/template"
 )
 
 type CliInfo struct {
@@ -34,6 +38,10 @@ func NewApp(info *CliInfo) *cli.App {
 		Usage:   "Norns is a CLI tool for cherry-picking commits from one ref to another",
 		Commands: []*cli.Command{
 			NewPickCommand(),
+			NewRevertCommand(),
+			NewRollbackCommand(),
+			NewGCCommand(),
+			NewProvidersCommand(),
 		},
 		Before: func(context *cli.Context) error {
 			debug := os.Getenv("NORN_DEBUG")
@@ -49,7 +57,7 @@ func NewPickCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "pick",
 		Usage: "pick commits from one branch to another",
-		Flags: []cli.Flag{
+		Flags: append(providerFlags(), []cli.Flag{
 			&cli.PathFlag{
 				Name:     "path",
 				Usage:    "RepoPath to the git repo",
@@ -58,27 +66,52 @@ func NewPickCommand() *cli.Command {
 				Value:    ".cherry-pick-path.yml",
 			},
 			&cli.StringFlag{
-				Name:    "vendor",
-				Usage:   "Git vendor, such as gh(github)",
-				Value:   "gh",
-				Aliases: []string{"v"},
+				Name:     "sha",
+				Usage:    "Commit sha, not required with --all-commits",
+				Aliases:  []string{"s"},
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:    "mainline",
+				Usage:   "Parent number of the merge commit to pick against, like git cherry-pick -m, merge commits are refused without it",
+				Aliases: []string{"m"},
+			},
+			&cli.BoolFlag{
+				Name:  "backport",
+				Usage: "Push the picks to backport/<merge request id>-<branch> and open a merge request against the branch, for protected branches",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "cascade",
+				Usage: "Pick to the branches in order of the profile, each branch picks the commit picked to the previous one, stop at the first failure",
+				Value: false,
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Number of target branches picked in parallel",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Check the picks and print the plan, no branch is updated and no comment is posted",
+				Value: false,
 			},
 			&cli.StringFlag{
-				Name:     "repo",
-				Usage:    "Git repo, such as kentio/norn",
-				Aliases:  []string{"r"},
-				Required: true,
+				Name:  "committer-name",
+				Usage: "Name of the committer of the picks, overrides the profile, the committer of the picked commit if not set",
 			},
 			&cli.StringFlag{
-				Name:     "token",
-				Usage:    "Personal access token",
-				Required: true,
+				Name:  "committer-email",
+				Usage: "Email of the committer of the picks, overrides the profile",
 			},
 			&cli.StringFlag{
-				Name:     "sha",
-				Usage:    "Commit sha",
-				Aliases:  []string{"s"},
-				Required: true,
+				Name:  "date-mode",
+				Usage: "Committer date of the picks, now or original, overrides the profile",
+			},
+			&cli.BoolFlag{
+				Name:  "all-commits",
+				Usage: "Pick every commit of the merge request in order, instead of the commit sha",
+				Value: false,
 			},
 			&cli.StringFlag{
 				Name:     "for",
@@ -95,30 +128,21 @@ func NewPickCommand() *cli.Command {
 				Usage: "Add Cherry-pick summary to the merge request",
 				Value: false,
 			},
-			&cli.StringFlag{
-				Name:  "repo-path",
-				Usage: "RepoPath to the git repo",
-				Value: ".",
-			},
-		},
+		}...),
 		Action: func(c *cli.Context) error {
 			logrus.Debugf("Start picking commits")
-			ctx := context.Background()
+			// stop starting picks on interrupt, the picks in progress are canceled
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
 			profile, err := internal.NewProfile(c.String("path"))
 			if err != nil {
 				return cli.Exit(err.Error(), 1)
 			}
 
-			vendor, token, mrId := c.String("vendor"), c.String("token"), c.String("merge-request-id")
-			logrus.Debugf("Vendor: %s, Token: %s Merge Request ID: %s", vendor, token, mrId)
-
-			if vendor == "" || token == "" {
-				return cli.Exit("Vendor or token is empty", 1)
-			}
-
-			provider, err := common.NewProvider(ctx, vendor, &tp.CreateProviderOption{Token: token})
+			mrId := c.String("merge-request-id")
+			provider, err := newProvider(ctx, c)
 			if err != nil {
-				return cli.Exit("Unknown provider", 1)
+				return cli.Exit(err.Error(), 1)
 			}
 
 			repo, from := c.String("repo"), c.String("for")
@@ -131,16 +155,44 @@ func NewPickCommand() *cli.Command {
 			sha, isSummary := c.String("sha"), c.Bool("is-summary")
 			logrus.Debugf("SHA: %s, IsSummary: %t", sha, isSummary)
 
+			mode := pick.CheeryPick
+			if c.Bool("all-commits") {
+				mode = pick.MergeRequest
+			} else if sha == "" && !isSummary {
+				return cli.Exit("SHA is empty", 1)
+			}
+
 			p := pick.NewPickService(provider)
 
+			var message *template.Template
+			if profile.Message != "" {
+				if message, err = pick.NewMessageTemplate(profile.Message); err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+			}
+
+			committer, dateMode, err := pickCommitter(c, profile)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
 			pickOpt := &pick.Task{
-				Repo:           repo,
-				Branches:       profile.Branches,
-				From:           from,
-				SHA:            &sha,
-				MergeRequestID: mrId,
-				IsSummary:      isSummary,
-				RepoPath:       c.String("repo-path"),
+				Repo:            repo,
+				Branches:        profile.Branches,
+				From:            from,
+				SHA:             &sha,
+				MergeRequestID:  mrId,
+				IsSummary:       isSummary,
+				PickMode:        mode,
+				Mainline:        c.Int("mainline"),
+				Backport:        c.Bool("backport"),
+				DryRun:          c.Bool("dry-run"),
+				Concurrency:     c.Int("concurrency"),
+				Cascade:         c.Bool("cascade"),
+				MessageTemplate: message,
+				Committer:       committer,
+				DateMode:        dateMode,
+				RepoPath:        c.String("repo-path"),
 			}
 
 			err = p.ProcessPick(ctx, pickOpt)
@@ -151,3 +203,167 @@ func NewPickCommand() *cli.Command {
 		},
 	}
 }
+
+// pickCommitter returns the committer and the date mode of the picks, the flags override the profile
+func pickCommitter(c *cli.Context, profile *internal.Profile) (*tp.Signature, tp.DateMode, error) {
+	var committer *tp.Signature
+	if profile.Committer != nil {
+		committer = &tp.Signature{Name: profile.Committer.Name, Email: profile.Committer.Email}
+	}
+	if name, email := c.String("committer-name"), c.String("committer-email"); name != "" || email != "" {
+		committer = &tp.Signature{Name: name, Email: email}
+	}
+	if committer != nil && (committer.Name == "" || committer.Email == "") {
+		return nil, "", fmt.Errorf("%w: committer requires both name and email", tp.ErrInvalidOptions)
+	}
+
+	date := profile.Date
+	if c.IsSet("date-mode") {
+		date = c.String("date-mode")
+	}
+	mode, err := tp.ParseDateMode(date)
+	if err != nil {
+		return nil, "", err
+	}
+	return committer, mode, nil
+}
+
+// providerFlags returns the flags of the repository and the vendor, shared by the commands
+func providerFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "vendor",
+			Usage:   "Git vendor, such as gh(github), gl(gitlab), gitea(forgejo), local, see `norn providers`",
+			Value:   "gh",
+			Aliases: []string{"v"},
+		},
+		&cli.StringFlag{
+			Name:     "repo",
+			Usage:    "Git repo, such as kentio/norn",
+			Aliases:  []string{"r"},
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:    "base-url",
+			Usage:   "API base url of a self-hosted instance, such as https://ghe.example.com/api/v3, https://gitlab.example.com/api/v4 or https://gitea.example.com",
+			EnvVars: []string{"NORN_BASE_URL"},
+		},
+		&cli.StringFlag{
+			Name:    "upload-url",
+			Usage:   "Upload url of GitHub Enterprise Server, derived from the base url when omitted",
+			EnvVars: []string{"NORN_UPLOAD_URL"},
+		},
+		&cli.StringFlag{
+			Name:     "token",
+			Usage:    "Personal access token, not required for the local vendor",
+			Required: false,
+		},
+		&cli.Int64Flag{
+			Name:    "app-id",
+			Usage:   "GitHub App id, authenticate as the app instead of the token",
+			EnvVars: []string{"NORN_GITHUB_APP_ID"},
+		},
+		&cli.Int64Flag{
+			Name:    "app-installation-id",
+			Usage:   "GitHub App installation id",
+			EnvVars: []string{"NORN_GITHUB_APP_INSTALLATION_ID"},
+		},
+		&cli.PathFlag{
+			Name:    "app-private-key",
+			Usage:   "Path to the PEM private key of the GitHub App",
+			EnvVars: []string{"NORN_GITHUB_APP_PRIVATE_KEY_PATH"},
+		},
+		&cli.PathFlag{
+			Name:    "signing-key",
+			Usage:   "Path to the armored OpenPGP or the OpenSSH private key signing the picks, github and local only",
+			EnvVars: []string{"NORN_SIGNING_KEY_PATH"},
+		},
+		&cli.StringFlag{
+			Name:    "signing-key-passphrase",
+			Usage:   "Passphrase of the encrypted signing key",
+			EnvVars: []string{"NORN_SIGNING_KEY_PASSPHRASE"},
+		},
+		&cli.StringSliceFlag{
+			Name:  "provider-option",
+			Usage: "Extra option of the vendor as key=value, can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "repo-path",
+			Usage: "RepoPath to the git repo",
+			Value: ".",
+		},
+		&cli.StringFlag{
+			Name:  "engine",
+			Usage: "How the picks are made, api by the API of the vendor, local by git cherry-pick in the clone at --repo-path pushed to --remote, merge by a merge in norn writing only the pick commit (github only, renames are not detected)",
+			Value: string(tp.EngineAPI),
+		},
+		&cli.StringFlag{
+			Name:  "remote",
+			Usage: "Remote of the clone the local engine fetches from and pushes to",
+			Value: "origin",
+		},
+		&cli.StringSliceFlag{
+			Name:  "strategy-option",
+			Usage: "Option of the merge strategy passed to git cherry-pick -X, such as find-renames=30%, local engine and local vendor only, can be repeated",
+		},
+	}
+}
+
+// newProvider creates the provider of the vendor from the flags of providerFlags
+func newProvider(ctx context.Context, c *cli.Context) (tp.Provider, error) {
+	vendor, token := c.String("vendor"), c.String("token")
+	logrus.Debugf("Vendor: %s, Token: %s", vendor, token)
+
+	if vendor == "" {
+		return nil, fmt.Errorf("vendor is empty")
+	}
+
+	extra, err := parseProviderOptions(c.StringSlice("provider-option"))
+	if err != nil {
+		return nil, err
+	}
+	engine, err := tp.ParsePickEngine(c.String("engine"))
+	if err != nil {
+		return nil, err
+	}
+	providerOpt := &tp.CreateProviderOption{
+		Token:           token,
+		RepoPath:        c.String("repo-path"),
+		Extra:           extra,
+		Engine:          engine,
+		Remote:          c.String("remote"),
+		StrategyOptions: c.StringSlice("strategy-option"),
+	}
+	if appId := c.Int64("app-id"); appId != 0 {
+		keyPath := c.Path("app-private-key")
+		if keyPath == "" {
+			return nil, fmt.Errorf("GitHub App private key is empty")
+		}
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("read GitHub App private key: %w", err)
+		}
+		providerOpt.AppID, providerOpt.AppInstallationID, providerOpt.AppPrivateKey = appId, c.Int64("app-installation-id"), key
+	}
+	if keyPath := c.Path("signing-key"); keyPath != "" {
+		signer, err := sign.LoadSigner(keyPath, []byte(c.String("signing-key-passphrase")))
+		if err != nil {
+			return nil, err
+		}
+		providerOpt.Signer = signer
+	}
+	if baseUrl := c.String("base-url"); baseUrl != "" {
+		providerOpt.BaseUrl = &baseUrl
+	}
+	if uploadUrl := c.String("upload-url"); uploadUrl != "" {
+		if providerOpt.BaseUrl == nil {
+			return nil, fmt.Errorf("upload url requires the base url")
+		}
+		providerOpt.UploadUrl = &uploadUrl
+	}
+	provider, err := common.NewProvider(ctx, vendor, providerOpt)
+	if err != nil {
+		return nil, fmt.Errorf("create provider %s: %w", vendor, err)
+	}
+	return provider, nil
+}
@@ -0,0 +1,50 @@
+package pick
+
+import (
+	"fmt"
+	"github.com/kentio/norn/pkg/common"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+	"strings"
+)
+
+func NewProvidersCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "providers",
+		Usage: "list the available vendors and their options",
+		Action: func(c *cli.Context) error {
+			table := tablewriter.NewWriter(c.App.Writer)
+			table.SetHeader([]string{"Vendor", "Aliases", "Options", "Description"})
+			table.SetAutoWrapText(false)
+			for _, spec := range common.Providers() {
+				var options []string
+				for _, o := range spec.Options {
+					option := o.Name
+					if o.Required {
+						option += " (required)"
+					}
+					options = append(options, option)
+				}
+				table.Append([]string{spec.Name, strings.Join(spec.Aliases, ", "), strings.Join(options, ", "), spec.Description})
+			}
+			table.Render()
+			return nil
+		},
+	}
+}
+
+// parseProviderOptions parse the key=value options
+func parseProviderOptions(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	options := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid provider option %q, expected key=value", v)
+		}
+		options[key] = value
+	}
+	return options, nil
+}
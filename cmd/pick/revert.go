@@ -0,0 +1,81 @@
+package pick
+
+import (
+	"context"
+	"github.com/kentio/norn/internal"
+	"github.com/kentio/norn/pkg/pick"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func NewRevertCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "revert",
+		Usage: "revert the picks of a commit reverted on its source branch",
+		Flags: append(providerFlags(), []cli.Flag{
+			&cli.PathFlag{
+				Name:    "path",
+				Usage:   "Path to the profile of the branches",
+				Aliases: []string{"p"},
+				Value:   ".cherry-pick-path.yml",
+			},
+			&cli.StringFlag{
+				Name:     "sha",
+				Usage:    "The revert commit, or the reverted commit itself",
+				Aliases:  []string{"s"},
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:    "mainline",
+				Usage:   "Parent number of a merge commit to revert against, like git revert -m",
+				Aliases: []string{"m"},
+			},
+			&cli.StringFlag{
+				Name:  "for",
+				Usage: "The source branch the commit is reverted on, it is not reverted again",
+			},
+			&cli.StringFlag{
+				Name:     "merge-request-id",
+				Usage:    "The merge request the result is commented on",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Check the reverts and print the plan, no branch is updated and no comment is posted",
+				Value: false,
+			},
+		}...),
+		Action: func(c *cli.Context) error {
+			logrus.Debugf("Start reverting commits")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			profile, err := internal.NewProfile(c.String("path"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			provider, err := newProvider(ctx, c)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			sha := c.String("sha")
+			task := &pick.Task{
+				Repo:           c.String("repo"),
+				Branches:       profile.Branches,
+				From:           c.String("for"),
+				SHA:            &sha,
+				MergeRequestID: c.String("merge-request-id"),
+				Mainline:       c.Int("mainline"),
+				DryRun:         c.Bool("dry-run"),
+				RepoPath:       c.String("repo-path"),
+			}
+			if err = pick.NewPickService(provider).ProcessRevert(ctx, task); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			return cli.Exit("", 0)
+		},
+	}
+}
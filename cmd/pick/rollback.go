@@ -0,0 +1,82 @@
+package pick
+
+import (
+	"context"
+	"errors"
+	"github.com/kentio/norn/internal"
+	"github.com/kentio/norn/pkg/pick"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func NewRollbackCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rollback",
+		Usage: "undo the pick of a commit on a target branch, by a reset if it is the unshared head or a revert",
+		Flags: append(providerFlags(), []cli.Flag{
+			&cli.PathFlag{
+				Name:    "path",
+				Usage:   "Path to the profile of the branches, a pick found on another branch of the profile is reverted instead of reset",
+				Aliases: []string{"p"},
+				Value:   ".cherry-pick-path.yml",
+			},
+			&cli.StringFlag{
+				Name:     "sha",
+				Usage:    "The pick, or the commit it is picked from",
+				Aliases:  []string{"s"},
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:     "branch",
+				Usage:    "The branch the pick is rolled back on, can be repeated",
+				Aliases:  []string{"b"},
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "merge-request-id",
+				Usage: "The merge request the result is commented on",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Check the rollback and print the plan, no branch is updated and no comment is posted",
+				Value: false,
+			},
+		}...),
+		Action: func(c *cli.Context) error {
+			logrus.Debugf("Start rolling back picks")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			// the profile is optional, without it only the target branches are known
+			var shared []string
+			profile, err := internal.NewProfile(c.String("path"))
+			switch {
+			case err == nil:
+				shared = profile.Branches
+			case !errors.Is(err, fs.ErrNotExist) || c.IsSet("path"):
+				return cli.Exit(err.Error(), 1)
+			}
+			provider, err := newProvider(ctx, c)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			opt := &pick.RollbackOption{
+				Repo:           c.String("repo"),
+				SHA:            c.String("sha"),
+				Branches:       c.StringSlice("branch"),
+				Shared:         shared,
+				MergeRequestID: c.String("merge-request-id"),
+				RepoPath:       c.String("repo-path"),
+				DryRun:         c.Bool("dry-run"),
+			}
+			if err = pick.NewPickService(provider).ProcessRollback(ctx, opt); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			return cli.Exit("", 0)
+		},
+	}
+}
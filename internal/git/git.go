@@ -0,0 +1,119 @@
+// Package git runs the git commands of the providers and the engines in a local clone
+package git
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Git runs git commands in the local clone
+type Git struct {
+	Path string // path of the local clone
+}
+
+func NewGit(path string) *Git {
+	if path == "" {
+		path = "."
+	}
+	return &Git{Path: path}
+}
+
+// Run runs git with args in the clone, returns the trimmed stdout
+func (g *Git) Run(ctx context.Context, args ...string) (string, error) {
+	return g.RunIn(ctx, g.Path, nil, args...)
+}
+
+// RunIn runs git with args in dir with extra environment variables
+func (g *Git) RunIn(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	out, err := g.run(ctx, dir, env, nil, args...)
+	return strings.TrimSpace(out), err
+}
+
+// Output runs git with args in the clone with the input on stdin, returns the stdout as is
+func (g *Git) Output(ctx context.Context, input string, args ...string) (string, error) {
+	return g.run(ctx, g.Path, nil, strings.NewReader(input), args...)
+}
+
+func (g *Git) run(ctx context.Context, dir string, env []string, stdin io.Reader, args ...string) (string, error) {
+	var stdout, stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		logrus.Debugf("git %s: %s", strings.Join(args, " "), stderr.String())
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// SignCommit writes the commit signed by the signer like git commit -S, returns the signed commit.
+// The signature is added as the gpgsig header of the commit object.
+func (g *Git) SignCommit(ctx context.Context, signer tp.Signer, sha string) (string, error) {
+	payload, err := g.Output(ctx, "", "cat-file", "commit", sha)
+	if err != nil {
+		return "", err
+	}
+	header, message, ok := strings.Cut(payload, "\n\n")
+	if !ok {
+		return "", fmt.Errorf("unexpected commit object %s", sha)
+	}
+	var signature strings.Builder
+	if err = signer.Sign(&signature, strings.NewReader(payload)); err != nil {
+		return "", fmt.Errorf("sign commit %s: %w", sha, err)
+	}
+	// the continuation lines of a header start with a space
+	gpgsig := "gpgsig " + strings.ReplaceAll(strings.TrimSuffix(signature.String(), "\n"), "\n", "\n ")
+	signed, err := g.Output(ctx, header+"\n"+gpgsig+"\n\n"+message, "hash-object", "-t", "commit", "-w", "--stdin")
+	return strings.TrimSpace(signed), err
+}
+
+// GitDir returns the absolute path of the common git dir, it is shared by all worktrees
+func (g *Git) GitDir(ctx context.Context) (string, error) {
+	dir, err := g.Run(ctx, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.Path, dir)
+	}
+	return filepath.Abs(dir)
+}
+
+// ResolveCommit returns the full sha of the commit, NotFound if not exists
+func (g *Git) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	return g.Run(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+// CheckoutRemote checks out the branch of the remote in the clone, "origin" if the remote is empty.
+// A local branch of the same name is replaced and the changes of the work tree are dropped.
+func (g *Git) CheckoutRemote(ctx context.Context, remote, branch string) error {
+	if remote == "" {
+		remote = "origin"
+	}
+	// -B resets the local branch, even if it is the current branch
+	if _, err := g.Run(ctx, "checkout", "-f", "-B", branch, "remotes/"+remote+"/"+branch); err != nil {
+		logrus.Errorf("Checkout %s: %v", branch, err)
+		return err
+	}
+	return nil
+}
+
+// CheckPatch checks if the patch applies to the work tree of the clone, nothing is applied. It
+// returns ErrConflict if the patch does not apply.
+func (g *Git) CheckPatch(ctx context.Context, patch string) error {
+	if _, err := g.Output(ctx, patch, "apply", "--check"); err != nil {
+		logrus.Warnf("Apply patch: %v", err)
+		return tp.ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testIdentity = []string{
+	"GIT_AUTHOR_NAME=norn", "GIT_AUTHOR_EMAIL=norn@example.com",
+	"GIT_COMMITTER_NAME=norn", "GIT_COMMITTER_EMAIL=norn@example.com",
+}
+
+// run runs git in the clone of g, the test fails on an error
+func run(t *testing.T, g *Git, args ...string) string {
+	out, err := g.RunIn(context.Background(), g.Path, testIdentity, args...)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return out
+}
+
+// newTestRepo creates a repo with a.txt committed on master
+func newTestRepo(t *testing.T) *Git {
+	g := NewGit(t.TempDir())
+	run(t, g, "init", "-q", "-b", "master")
+	commit(t, g, "a.txt", "a\nb\nc\n", "init")
+	return g
+}
+
+// commit writes the file on the current branch and commits it, returns the commit sha
+func commit(t *testing.T, g *Git, file, content, message string) string {
+	if err := os.WriteFile(filepath.Join(g.Path, file), []byte(content), 0o644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	run(t, g, "add", file)
+	run(t, g, "commit", "-q", "-m", message)
+	return run(t, g, "rev-parse", "HEAD")
+}
+
+func TestGit_CheckoutRemote(t *testing.T) {
+	origin := newTestRepo(t)
+	run(t, origin, "checkout", "-q", "-b", "r1")
+	head := commit(t, origin, "b.txt", "b\n", "feat: b")
+	run(t, origin, "checkout", "-q", "master")
+	clone := NewGit(filepath.Join(t.TempDir(), "clone"))
+	run(t, NewGit("."), "clone", "-q", "-o", "upstream", origin.Path, clone.Path)
+	ctx := context.Background()
+
+	// a stale local branch is replaced by the branch of the remote
+	run(t, clone, "branch", "r1", "master")
+	if err := clone.CheckoutRemote(ctx, "upstream", "r1"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if run(t, clone, "rev-parse", "HEAD") != head || run(t, clone, "symbolic-ref", "--short", "HEAD") != "r1" {
+		t.Fatalf("r1 is not checked out")
+	}
+	// the clone has no origin
+	if err := clone.CheckoutRemote(ctx, "", "r1"); err == nil {
+		t.Fatalf("r1 of origin is checked out")
+	}
+	if err := clone.CheckoutRemote(ctx, "upstream", "missing"); err == nil {
+		t.Fatalf("missing branch is checked out")
+	}
+}
+
+func TestGit_CheckPatch(t *testing.T) {
+	repo := newTestRepo(t)
+	run(t, repo, "branch", "r1")
+	sha := commit(t, repo, "a.txt", "a\nfix\nc\n", "fix: a")
+	patch := run(t, repo, "format-patch", "-1", "--stdout", sha) + "\n"
+	ctx := context.Background()
+
+	run(t, repo, "checkout", "-q", "r1")
+	if err := repo.CheckPatch(ctx, patch); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if run(t, repo, "show", "r1:a.txt") != "a\nb\nc" || run(t, repo, "status", "--porcelain") != "" {
+		t.Fatalf("the patch is applied")
+	}
+	commit(t, repo, "a.txt", "a\nB\nc\n", "change b")
+	if err := repo.CheckPatch(ctx, patch); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestGit_ResolveCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	head := run(t, repo, "rev-parse", "HEAD")
+	if sha, err := repo.ResolveCommit(context.Background(), "master"); err != nil || sha != head {
+		t.Fatalf("sha: %s err: %v", sha, err)
+	}
+	if _, err := repo.ResolveCommit(context.Background(), "missing"); err == nil {
+		t.Fatalf("missing commit is resolved")
+	}
+}
@@ -10,6 +10,17 @@ import (
 type Profile struct {
 	Branches []string `yaml:"branches"`
 	tags     []string `yaml:"tags"`
+	// Message is the text/template of the message of the pick commits, see pick.MessageData
+	Message string `yaml:"message"`
+	// Committer commits the picks instead of the committer of the picked commits
+	Committer *Committer `yaml:"committer"`
+	// Date is the committer date of the picks, "now" or "original"
+	Date string `yaml:"date"`
+}
+
+type Committer struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
 }
 
 func NewProfile(path string) (*Profile, error) {
@@ -2,19 +2,96 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"github.com/kentio/norn/pkg/gitea"
 	"github.com/kentio/norn/pkg/github"
+	"github.com/kentio/norn/pkg/gitlab"
+	"github.com/kentio/norn/pkg/local"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
 )
 
+// names of the options mapped to CreateProviderOption fields
+const (
+	OptionToken     = "token"
+	OptionBaseUrl   = "base-url"
+	OptionUploadUrl = "upload-url"
+	OptionRepoPath  = "repo-path"
+
+	OptionAppID             = "app-id"
+	OptionAppInstallationID = "app-installation-id"
+	OptionAppPrivateKey     = "app-private-key"
+)
+
+func init() {
+	MustRegister(string(tp.GitHubProvider), func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		if opt.Token == "" && opt.AppID == 0 {
+			return nil, fmt.Errorf("%w: token or GitHub App is required for github", tp.ErrInvalidOptions)
+		}
+		return github.NewProvider(ctx, opt)
+	}, &RegisterOption{
+		Aliases:     []string{"gh"},
+		Description: "GitHub and GitHub Enterprise Server",
+		Options: []OptionSpec{
+			{Name: OptionToken, Usage: "personal access token, not required with GitHub App"},
+			{Name: OptionBaseUrl, Usage: "GitHub Enterprise API url"},
+			{Name: OptionUploadUrl, Usage: "GitHub Enterprise upload url"},
+			{Name: OptionAppID, Usage: "GitHub App id"},
+			{Name: OptionAppInstallationID, Usage: "GitHub App installation id"},
+			{Name: OptionAppPrivateKey, Usage: "GitHub App private key"},
+		},
+	})
+	MustRegister(string(tp.GitlabProvider), func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		return gitlab.NewProvider(ctx, opt)
+	}, &RegisterOption{
+		Aliases:     []string{"gl"},
+		Description: "GitLab.com and self-hosted GitLab",
+		Options: []OptionSpec{
+			{Name: OptionToken, Usage: "personal or project access token", Required: true},
+			{Name: OptionBaseUrl, Usage: "API url of a self-hosted instance"},
+		},
+	})
+	MustRegister(string(tp.GiteaProvider), func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		return gitea.NewProvider(ctx, opt)
+	}, &RegisterOption{
+		Aliases:     []string{"forgejo"},
+		Description: "Gitea and Forgejo",
+		Options: []OptionSpec{
+			{Name: OptionToken, Usage: "access token", Required: true},
+			{Name: OptionBaseUrl, Usage: "url of the instance", Required: true},
+		},
+	})
+	MustRegister(string(tp.LocalProvider), func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		return local.NewProvider(ctx, opt)
+	}, &RegisterOption{
+		Description: "local clone, no forge API needed",
+		Options: []OptionSpec{
+			{Name: OptionRepoPath, Usage: "path of the clone", Required: true},
+		},
+	})
+}
+
 // NewProvider NewClient returns a new client for the given vendor.
 func NewProvider(ctx context.Context, vendor string, opt *tp.CreateProviderOption) (tp.Provider, error) {
 	logrus.Debugf("New provider: %s", vendor)
 
-	switch vendor {
-	case "gh", "github":
-		return github.NewProvider(ctx, opt), nil
-	default:
+	spec, ok := Lookup(vendor)
+	if !ok {
 		return nil, tp.ErrUnknownProvider
 	}
+	if opt == nil {
+		opt = &tp.CreateProviderOption{}
+	}
+	if err := spec.Validate(opt); err != nil {
+		return nil, err
+	}
+	provider, err := spec.factory(ctx, opt)
+	if err == nil && opt.Engine == tp.EngineMerge && provider.ProviderID() != tp.GitHubProvider {
+		return nil, fmt.Errorf("%w: the merge engine is GitHub only", tp.ErrNotSupported)
+	}
+	if err != nil || opt.Engine != tp.EngineLocal || provider.ProviderID() == tp.LocalProvider {
+		return provider, err
+	}
+	// the local provider always picks in its clone
+	return local.WithEngine(ctx, provider, opt)
 }
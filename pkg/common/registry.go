@@ -0,0 +1,152 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Factory creates a provider with the given options.
+type Factory func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error)
+
+// OptionSpec describes an option accepted by a provider.
+// The names of the Option constants map to the fields of CreateProviderOption,
+// other names are read from CreateProviderOption.Extra.
+type OptionSpec struct {
+	Name     string
+	Usage    string
+	Required bool
+}
+
+// RegisterOption describes a provider, all fields are optional.
+type RegisterOption struct {
+	Aliases     []string
+	Description string
+	Options     []OptionSpec
+}
+
+// ProviderSpec is a registered provider.
+type ProviderSpec struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Options     []OptionSpec
+	factory     Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*ProviderSpec{} // name and aliases -> spec
+)
+
+// Register registers a provider factory, the name and aliases can be used as the vendor of NewProvider.
+// It returns an error if the name or an alias is already registered.
+func Register(name string, factory Factory, opt *RegisterOption) error {
+	if name == "" || factory == nil {
+		return tp.ErrInvalidOptions
+	}
+	if opt == nil {
+		opt = &RegisterOption{}
+	}
+	spec := &ProviderSpec{
+		Name:        name,
+		Aliases:     opt.Aliases,
+		Description: opt.Description,
+		Options:     opt.Options,
+		factory:     factory,
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := append([]string{name}, opt.Aliases...)
+	for _, n := range names {
+		if _, ok := registry[n]; ok {
+			return fmt.Errorf("provider %s is already registered", n)
+		}
+	}
+	for _, n := range names {
+		registry[n] = spec
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error, it is meant to be called in init.
+func MustRegister(name string, factory Factory, opt *RegisterOption) {
+	if err := Register(name, factory, opt); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the provider registered with the name or alias.
+func Lookup(vendor string) (*ProviderSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	spec, ok := registry[vendor]
+	return spec, ok
+}
+
+// Providers returns the registered providers sorted by name.
+func Providers() []*ProviderSpec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	var specs []*ProviderSpec
+	for name, spec := range registry {
+		if name == spec.Name {
+			specs = append(specs, spec)
+		}
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
+}
+
+// Validate check the required options of the provider are set.
+func (s *ProviderSpec) Validate(opt *tp.CreateProviderOption) error {
+	for _, o := range s.Options {
+		if o.Required && optionValue(opt, o.Name) == "" {
+			return fmt.Errorf("%w: %s is required for %s", tp.ErrInvalidOptions, o.Name, s.Name)
+		}
+	}
+	return nil
+}
+
+// optionValue returns the value of the named option
+func optionValue(opt *tp.CreateProviderOption, name string) string {
+	if opt == nil {
+		return ""
+	}
+	switch name {
+	case OptionToken:
+		return opt.Token
+	case OptionBaseUrl:
+		if opt.BaseUrl != nil {
+			return *opt.BaseUrl
+		}
+		return ""
+	case OptionUploadUrl:
+		if opt.UploadUrl != nil {
+			return *opt.UploadUrl
+		}
+		return ""
+	case OptionRepoPath:
+		return opt.RepoPath
+	case OptionAppID:
+		if opt.AppID != 0 {
+			return strconv.FormatInt(opt.AppID, 10)
+		}
+		return ""
+	case OptionAppInstallationID:
+		if opt.AppInstallationID != 0 {
+			return strconv.FormatInt(opt.AppInstallationID, 10)
+		}
+		return ""
+	case OptionAppPrivateKey:
+		return string(opt.AppPrivateKey)
+	default:
+		return opt.Extra[name]
+	}
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"github.com/kentio/norn/pkg/fake"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	var got *tp.CreateProviderOption
+	err := Register("test-vendor", func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		got = opt
+		return fake.NewProvider(), nil
+	}, &RegisterOption{
+		Aliases: []string{"tv"},
+		Options: []OptionSpec{{Name: "project", Required: true}},
+	})
+	assert.NoError(t, err)
+
+	_, err = NewProvider(context.Background(), "tv", &tp.CreateProviderOption{})
+	assert.True(t, errors.Is(err, tp.ErrInvalidOptions))
+
+	provider, err := NewProvider(context.Background(), "tv", &tp.CreateProviderOption{Extra: map[string]string{"project": "p"}})
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+	assert.Equal(t, "p", got.Extra["project"])
+
+	spec, ok := Lookup("test-vendor")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"tv"}, spec.Aliases)
+
+	// name and aliases can not be registered twice
+	assert.Error(t, Register("tv", func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		return nil, nil
+	}, nil))
+	assert.Error(t, Register("other", func(ctx context.Context, opt *tp.CreateProviderOption) (tp.Provider, error) {
+		return nil, nil
+	}, &RegisterOption{Aliases: []string{"gh"}}))
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(context.Background(), "unknown", &tp.CreateProviderOption{})
+	assert.Equal(t, tp.ErrUnknownProvider, err)
+
+	// token is required for github
+	_, err = NewProvider(context.Background(), "gh", &tp.CreateProviderOption{})
+	assert.True(t, errors.Is(err, tp.ErrInvalidOptions))
+
+	provider, err := NewProvider(context.Background(), "gh", &tp.CreateProviderOption{Token: "token"})
+	assert.NoError(t, err)
+	assert.Equal(t, tp.GitHubProvider, provider.ProviderID())
+
+	_, err = NewProvider(context.Background(), "gh", &tp.CreateProviderOption{Token: "token", Engine: tp.EngineMerge})
+	assert.NoError(t, err)
+	// the merge engine is GitHub only
+	_, err = NewProvider(context.Background(), "gitlab", &tp.CreateProviderOption{Token: "token", Engine: tp.EngineMerge})
+	assert.True(t, errors.Is(err, tp.ErrNotSupported))
+}
+
+func TestProviders(t *testing.T) {
+	var names []string
+	for _, spec := range Providers() {
+		names = append(names, spec.Name)
+	}
+	assert.Subset(t, names, []string{"gitea", "github", "gitlab", "local"})
+}
@@ -0,0 +1,120 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+)
+
+type PickService struct {
+	p *Provider
+}
+
+// Pick cherry-pick the commit onto the branch like the GitHub provider does.
+func (s *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return s.apply(opt, OpPick)
+}
+
+// Revert reverts the commit on the branch, the change from the commit to its parent is merged.
+func (s *PickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return s.apply(opt, OpRevert)
+}
+
+func (s *PickService) apply(opt *tp.PickOption, op Operation) (*tp.PickResult, error) {
+	if opt == nil || opt.SHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(op); err != nil {
+		return nil, err
+	}
+	if err := s.p.pickErrors[opt.Branch]; err != nil {
+		return nil, err
+	}
+	branch := branchName(opt.Branch)
+	head, ok := s.p.branches[branch]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	source := s.p.resolve(opt.SHA)
+	if source == nil {
+		return nil, tp.NotFound
+	}
+
+	parent, err := tp.MainlineParent(len(source.parents), opt.Mainline)
+	if err != nil {
+		return nil, err
+	}
+	target := s.p.commits[head]
+	base, theirs := s.p.commits[source.parents[parent]].tree, source.tree
+	message := opt.Message
+	if message == "" {
+		message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", source.message, source.sha[:7])
+	}
+	if op == OpRevert {
+		base, theirs = theirs, base
+		message = tp.RevertMessage(source.sha, source.message)
+	}
+	tree, err := s.p.mergeTrees(base, theirs, target.tree)
+	if err != nil {
+		return nil, err
+	}
+	if tree == target.tree {
+		return nil, tp.ErrEmptyPick
+	}
+	if opt.DryRun {
+		return &tp.PickResult{Tree: tree}, nil
+	}
+	sha := s.p.writeCommit(tree, message, []string{head})
+	s.p.branches[branch] = sha
+	return &tp.PickResult{SHA: sha, Tree: tree}, nil
+}
+
+// merge applies the change of source onto target with a three-way merge of each file,
+// returns the merged tree or ErrConflict if a file is changed on both sides.
+func (p *Provider) merge(source, target *commitObject, mainline int) (string, error) {
+	parent, err := tp.MainlineParent(len(source.parents), mainline)
+	if err != nil {
+		return "", err
+	}
+	return p.mergeTrees(p.commits[source.parents[parent]].tree, source.tree, target.tree)
+}
+
+// mergeTrees applies the change from the base tree to theirs onto ours
+func (p *Provider) mergeTrees(baseTree, theirsTree, oursTree string) (string, error) {
+	base := p.trees[baseTree]
+	theirs := p.trees[theirsTree]
+	ours := p.trees[oursTree]
+
+	paths := map[string]bool{}
+	for _, files := range []map[string]string{base, theirs, ours} {
+		for path := range files {
+			paths[path] = true
+		}
+	}
+
+	merged := map[string]string{}
+	for path := range paths {
+		b, inBase := base[path]
+		t, inTheirs := theirs[path]
+		o, inOurs := ours[path]
+		switch {
+		case inBase == inTheirs && b == t: // unchanged by source
+			if inOurs {
+				merged[path] = o
+			}
+		case inBase == inOurs && b == o: // unchanged on target
+			if inTheirs {
+				merged[path] = t
+			}
+		case inOurs == inTheirs && o == t: // same change on both sides
+			if inOurs {
+				merged[path] = o
+			}
+		default:
+			return "", tp.ErrConflict
+		}
+	}
+	return p.writeTree(merged), nil
+}
@@ -0,0 +1,138 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestPickService_Pick(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a", "b.txt": "b"})
+	p.CreateBranch("r1", root)
+	p.CommitFiles("r1", "change b on r1", map[string]string{"b.txt": "r1"})
+	sha := p.CommitFiles("master", "add c", map[string]string{"c.txt": "c"})
+	ctx := context.Background()
+
+	// dry run leaves the branch
+	head := p.Branch("r1")
+	planned, err := p.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r1", DryRun: true})
+	if err != nil || planned.SHA != "" || p.Branch("r1") != head {
+		t.Fatalf("dry run: %v %+v", err, planned)
+	}
+
+	result, err := p.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != p.Branch("r1") || result.Tree != planned.Tree {
+		t.Fatalf("result: %+v, planned: %+v", result, planned)
+	}
+	if content, _ := p.File("r1", "c.txt"); content != "c" {
+		t.Fatalf("c.txt: %q", content)
+	}
+	if content, _ := p.File("r1", "b.txt"); content != "r1" {
+		t.Fatalf("b.txt: %q", content)
+	}
+	if message := p.CommitMessage("r1"); !strings.HasSuffix(message, "(cherry picked from commit "+sha[:7]+")") {
+		t.Fatalf("message: %q", message)
+	}
+}
+
+func TestPickService_PickConflict(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	p.CreateBranch("r1", root)
+	head := p.CommitFiles("r1", "change a on r1", map[string]string{"a.txt": "r1"})
+	sha := p.CommitFiles("master", "change a on master", map[string]string{"a.txt": "master"})
+	ctx := context.Background()
+
+	if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1"}); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if err := p.Commit().CheckConflict(ctx, &tp.CheckConflictOption{Commit: sha, Target: "r1"}); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if p.Branch("r1") != head {
+		t.Fatalf("branch is changed")
+	}
+	if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "missing"}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+
+	// injected failures
+	p.SetPickError("master", tp.ErrConflict)
+	if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: head, Branch: "master"}); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestPickService_PickMergeCommit(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	p.CreateBranch("r1", root)
+	p.CreateBranch("feature", root)
+	p.CommitFiles("feature", "add b", map[string]string{"b.txt": "b"})
+	p.CommitFiles("master", "add c", map[string]string{"c.txt": "c"})
+	merge := p.MergeFiles("master", "feature", "Merge feature", map[string]string{"b.txt": "b"})
+	ctx := context.Background()
+
+	if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: merge, Branch: "r1"}); err != tp.ErrMainlineRequired {
+		t.Fatalf("err = %v, want mainline required", err)
+	}
+
+	// against master, the merge brings b.txt
+	if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: merge, Branch: "r1", Mainline: 1}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := p.File("r1", "b.txt"); !ok {
+		t.Fatalf("b.txt is not picked")
+	}
+	if _, ok := p.File("r1", "c.txt"); ok {
+		t.Fatalf("c.txt of the mainline is picked")
+	}
+}
+
+func TestPickService_PickEmpty(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	p.CreateBranch("r1", root)
+	head := p.CommitFiles("r1", "fix a", map[string]string{"a.txt": "fixed"})
+	sha := p.CommitFiles("master", "fix a", map[string]string{"a.txt": "fixed"})
+	ctx := context.Background()
+
+	for _, dryRun := range []bool{true, false} {
+		if _, err := p.Pick().Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1", DryRun: dryRun}); err != tp.ErrEmptyPick {
+			t.Fatalf("dry run %v: err = %v, want empty pick", dryRun, err)
+		}
+	}
+	if p.Branch("r1") != head {
+		t.Fatalf("branch is changed")
+	}
+}
+
+func TestPickService_Revert(t *testing.T) {
+	p := NewProvider()
+	p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	sha := p.CommitFiles("master", "add b", map[string]string{"b.txt": "b"})
+	p.CommitFiles("master", "change a", map[string]string{"a.txt": "master"})
+	ctx := context.Background()
+
+	result, err := p.Pick().Revert(ctx, "", &tp.PickOption{SHA: sha, Branch: "master"})
+	if err != nil || result.SHA != p.Branch("master") {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if _, ok := p.File("master", "b.txt"); ok {
+		t.Fatalf("b.txt is not reverted")
+	}
+	if content, _ := p.File("master", "a.txt"); content != "master" {
+		t.Fatalf("a.txt: %q", content)
+	}
+	if message := p.CommitMessage("master"); message != tp.RevertMessage(sha, "add b") {
+		t.Fatalf("message: %q", message)
+	}
+	if _, err = p.Pick().Revert(ctx, "", &tp.PickOption{SHA: sha, Branch: "master"}); err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+}
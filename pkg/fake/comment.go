@@ -0,0 +1,102 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strconv"
+)
+
+type CommentService struct {
+	p *Provider
+}
+
+type Comment struct {
+	commentId string
+	body      string
+}
+
+// Create Comment creates a new comment on the given merge request.
+func (s *CommentService) Create(ctx context.Context, opt *tp.CreateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommentCreate); err != nil {
+		return nil, err
+	}
+	s.p.seq++
+	comment := &Comment{commentId: strconv.Itoa(s.p.seq), body: opt.Body}
+	mr := s.p.mergeRequest(opt.MergeRequestID)
+	mr.comments = append(mr.comments, comment)
+	return &Comment{commentId: comment.commentId, body: comment.body}, nil
+}
+
+// Find Comment finds comments on the given merge request.
+func (s *CommentService) Find(ctx context.Context, opt *tp.FindCommentOption) ([]tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommentFind); err != nil {
+		return nil, err
+	}
+	var comments []tp.Comment
+	if mr, ok := s.p.mergeRequests[opt.MergeRequestID]; ok {
+		for _, c := range mr.comments {
+			comments = append(comments, &Comment{commentId: c.commentId, body: c.body})
+		}
+	}
+	return comments, nil
+}
+
+// Update Comment updates a comment.
+func (s *CommentService) Update(ctx context.Context, opt *tp.UpdateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommentUpdate); err != nil {
+		return nil, err
+	}
+	for _, mr := range s.p.mergeRequests {
+		for _, c := range mr.comments {
+			if c.commentId == opt.CommentID {
+				c.body = opt.Body
+				return &Comment{commentId: c.commentId, body: c.body}, nil
+			}
+		}
+	}
+	return nil, tp.NotFound
+}
+
+// Delete Comment deletes a comment.
+func (s *CommentService) Delete(ctx context.Context, opt *tp.DeleteCommentOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommentDelete); err != nil {
+		return err
+	}
+	for _, mr := range s.p.mergeRequests {
+		for i, c := range mr.comments {
+			if c.commentId == opt.CommentID {
+				mr.comments = append(mr.comments[:i], mr.comments[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return tp.NotFound
+}
+
+func (c *Comment) CommentID() string {
+	return c.commentId
+}
+
+func (c *Comment) Body() string {
+	return c.body
+}
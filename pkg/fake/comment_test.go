@@ -0,0 +1,30 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestCommentService(t *testing.T) {
+	p := NewProvider()
+	ctx := context.Background()
+
+	comment, err := p.Comment().Create(ctx, &tp.CreateCommentOption{MergeRequestID: "1", Body: "hello"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = p.Comment().Update(ctx, &tp.UpdateCommentOption{CommentID: comment.CommentID(), Body: "updated"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if bodies := p.Comments("1"); len(bodies) != 1 || bodies[0] != "updated" {
+		t.Fatalf("comments: %+v", bodies)
+	}
+	if err = p.Comment().Delete(ctx, &tp.DeleteCommentOption{CommentID: comment.CommentID()}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	comments, err := p.Comment().Find(ctx, &tp.FindCommentOption{MergeRequestID: "1"})
+	if err != nil || len(comments) != 0 {
+		t.Fatalf("find: %v %+v", err, comments)
+	}
+}
@@ -0,0 +1,275 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"sort"
+	"strings"
+)
+
+type CommitService struct {
+	p *Provider
+}
+
+type Commit struct {
+	sha     string
+	tree    *Tree
+	message string
+	author  tp.Signature
+	parents []string
+}
+
+type Tree struct {
+	sha     string
+	entries []tp.TreeEntry
+}
+
+type TreeEntry struct {
+	path    string
+	content string
+}
+
+// Get Commit returns the commit for the given sha or branch.
+func (s *CommitService) Get(ctx context.Context, opt *tp.GetCommitOption) (tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommitGet); err != nil {
+		return nil, err
+	}
+	commit := s.p.resolve(opt.SHA)
+	if commit == nil {
+		return nil, tp.NotFound
+	}
+	return s.p.newCommit(commit), nil
+}
+
+// List returns the commits reachable from the branch, breadth first from the head.
+func (s *CommitService) List(ctx context.Context, opt *tp.ListCommitOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommitList); err != nil {
+		return nil, err
+	}
+	head, ok := s.p.branches[branchName(opt.Branch)]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	var result []tp.Commit
+	queue := []string{head}
+	seen := map[string]bool{head: true}
+	for len(queue) > 0 && len(result) < opt.Limit {
+		commit := s.p.commits[queue[0]]
+		queue = queue[1:]
+		result = append(result, s.p.newCommit(commit))
+		for _, parent := range commit.parents {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Diff returns a diff of the files changed against the first parent, each file is a hunk
+// replacing the old content with the new one.
+func (s *CommitService) Diff(ctx context.Context, opt *tp.GetCommitOption) (string, error) {
+	if opt == nil {
+		return "", tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommitDiff); err != nil {
+		return "", err
+	}
+	commit := s.p.resolve(opt.SHA)
+	if commit == nil {
+		return "", tp.NotFound
+	}
+	var base map[string]string
+	if len(commit.parents) > 0 {
+		base = s.p.trees[s.p.commits[commit.parents[0]].tree]
+	}
+	files := s.p.trees[commit.tree]
+
+	paths := map[string]bool{}
+	for _, tree := range []map[string]string{base, files} {
+		for path := range tree {
+			paths[path] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var diff strings.Builder
+	for _, path := range sorted {
+		old, inBase := base[path]
+		content, inFiles := files[path]
+		if inBase == inFiles && old == content {
+			continue
+		}
+		fmt.Fprintf(&diff, "diff --git a/%s b/%s\n", path, path)
+		if inBase {
+			fmt.Fprintf(&diff, "-%s\n", old)
+		}
+		if inFiles {
+			fmt.Fprintf(&diff, "+%s\n", content)
+		}
+	}
+	return diff.String(), nil
+}
+
+// Create Commit creates a new commit with an existing tree, no ref is updated.
+func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption) (tp.Commit, error) {
+	if opt == nil || opt.Tree == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommitCreate); err != nil {
+		return nil, err
+	}
+	if _, ok := s.p.trees[opt.Tree.SHA()]; !ok {
+		return nil, tp.NotFound
+	}
+	for _, parent := range opt.Parents {
+		if _, ok := s.p.commits[parent]; !ok {
+			return nil, tp.NotFound
+		}
+	}
+	sha := s.p.writeCommit(opt.Tree.SHA(), opt.PickMessage, opt.Parents)
+	return s.p.newCommit(s.p.commits[sha]), nil
+}
+
+// CheckConflict check if the commit can be picked onto the target, the mode is ignored.
+func (s *CommitService) CheckConflict(ctx context.Context, opt *tp.CheckConflictOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCheckConflict); err != nil {
+		return err
+	}
+	if err := s.p.pickErrors[opt.Target]; err != nil {
+		return err
+	}
+	target := s.p.resolve(opt.Target)
+	source := s.p.resolve(opt.Commit)
+	if target == nil || source == nil {
+		return tp.NotFound
+	}
+	_, err := s.p.merge(source, target, opt.Mainline)
+	return err
+}
+
+// IsAncestor reports whether the commit is reachable from the head of the branch
+func (s *CommitService) IsAncestor(ctx context.Context, opt *tp.AncestorOption) (bool, error) {
+	if opt == nil {
+		return false, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpCommitIsAncestor); err != nil {
+		return false, err
+	}
+	head, ok := s.p.branches[branchName(opt.Branch)]
+	if !ok {
+		return false, tp.NotFound
+	}
+	return s.p.isAncestor(opt.SHA, head), nil
+}
+
+func (p *Provider) newCommit(commit *commitObject) *Commit {
+	content := p.trees[commit.tree]
+	paths := make([]string, 0, len(content))
+	for path := range content {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	entries := make([]tp.TreeEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, &TreeEntry{path: path, content: content[path]})
+	}
+	return &Commit{
+		sha:     commit.sha,
+		tree:    &Tree{sha: commit.tree, entries: entries},
+		message: commit.message,
+		author:  Author,
+		parents: commit.parents,
+	}
+}
+
+// SHA Commit returns the commit sha.
+func (c *Commit) SHA() string {
+	return c.sha
+}
+
+func (c *Commit) Tree() tp.Tree {
+	return c.tree
+}
+
+func (c *Commit) Message() string {
+	return c.message
+}
+
+func (c *Commit) Author() tp.Signature {
+	return c.author
+}
+
+// Parents returns the shas of the parents, the first parent first
+func (c *Commit) Parents() []string {
+	return c.parents
+}
+
+// SHA Tree returns the tree sha.
+func (t *Tree) SHA() string {
+	return t.sha
+}
+
+func (t *Tree) Entries() []tp.TreeEntry {
+	return t.entries
+}
+
+func (t *Tree) Truncated() bool {
+	return false
+}
+
+// SHA TreeEntry the fake provider has no blob objects, the content is returned instead.
+func (t *TreeEntry) SHA() string {
+	return ""
+}
+
+func (t *TreeEntry) Path() string {
+	return t.path
+}
+
+func (t *TreeEntry) Mode() string {
+	return "100644"
+}
+
+func (t *TreeEntry) Type() string {
+	return "blob"
+}
+
+func (t *TreeEntry) Size() int {
+	return len(t.content)
+}
+
+func (t *TreeEntry) Content() string {
+	return t.content
+}
+
+func (t *TreeEntry) Url() string {
+	return ""
+}
@@ -0,0 +1,25 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestCommitService_ListDiff(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	head := p.CommitFiles("master", "change a", map[string]string{"a.txt": "b", "c.txt": "c"})
+	ctx := context.Background()
+
+	commits, err := p.Commit().List(ctx, &tp.ListCommitOption{Branch: "master", Limit: 10})
+	if err != nil || len(commits) != 2 || commits[0].SHA() != head || commits[1].SHA() != root {
+		t.Fatalf("err: %v commits: %+v", err, commits)
+	}
+
+	diff, err := p.Commit().Diff(ctx, &tp.GetCommitOption{SHA: head})
+	want := "diff --git a/a.txt b/a.txt\n-a\n+b\ndiff --git a/c.txt b/c.txt\n+c\n"
+	if err != nil || diff != want {
+		t.Fatalf("err: %v diff: %q", err, diff)
+	}
+}
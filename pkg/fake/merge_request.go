@@ -0,0 +1,124 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strconv"
+)
+
+type MergeRequestService struct {
+	p *Provider
+}
+
+type MergeRequest struct {
+	id          string
+	title       string
+	description string
+	state       tp.MergeRequestState
+	labels      []string
+}
+
+func (s *MergeRequest) MergeId() string {
+	return s.id
+}
+
+func (s *MergeRequest) Title() string {
+	return s.title
+}
+
+func (s *MergeRequest) Description() string {
+	return s.description
+}
+
+func (s *MergeRequest) State() tp.MergeRequestState {
+	return s.state
+}
+
+func (s *MergeRequest) Labels() []string {
+	return s.labels
+}
+
+func (s *MergeRequest) WebUrl() string {
+	return "https://fake.example.com/merge_requests/" + s.id
+}
+
+func (s *MergeRequestService) Get(ctx context.Context, opt *tp.GetMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpMergeRequestGet); err != nil {
+		return nil, err
+	}
+	mr, ok := s.p.mergeRequests[opt.MergeID]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	return newMergeRequest(mr), nil
+}
+
+func (s *MergeRequestService) Create(ctx context.Context, opt *tp.CreateMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpMergeRequestCreate); err != nil {
+		return nil, err
+	}
+	head, base := branchName(opt.Head), branchName(opt.Base)
+	for _, branch := range []string{head, base} {
+		if _, ok := s.p.branches[branch]; !ok {
+			return nil, tp.NotFound
+		}
+	}
+	for _, mr := range s.p.mergeRequests {
+		if mr.state == tp.MergeRequestStateOpen && mr.head == head && mr.base == base {
+			return nil, tp.ErrAlreadyExists
+		}
+	}
+	id := ""
+	for id == "" || s.p.mergeRequests[id] != nil {
+		s.p.seq++
+		id = strconv.Itoa(1000 + s.p.seq)
+	}
+	mr := s.p.mergeRequest(id)
+	mr.title, mr.description, mr.state = opt.Title, opt.Description, tp.MergeRequestStateOpen
+	mr.head, mr.base, mr.labels = head, base, opt.Labels
+	return newMergeRequest(mr), nil
+}
+
+func newMergeRequest(mr *mergeRequestObject) *MergeRequest {
+	return &MergeRequest{
+		id:          mr.id,
+		title:       mr.title,
+		description: mr.description,
+		state:       mr.state,
+		labels:      mr.labels,
+	}
+}
+
+func (s *MergeRequestService) ListCommits(ctx context.Context, opt *tp.ListMergeRequestCommitsOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpMergeRequestListCommits); err != nil {
+		return nil, err
+	}
+	mr, ok := s.p.mergeRequests[opt.MergeID]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	var commits []tp.Commit
+	for _, sha := range mr.commits {
+		commit, ok := s.p.commits[sha]
+		if !ok {
+			return nil, tp.NotFound
+		}
+		commits = append(commits, s.p.newCommit(commit))
+	}
+	return commits, nil
+}
@@ -0,0 +1,351 @@
+package fake
+
+import (
+	"crypto/sha1"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Operation identifies a provider call, it is used to inject failures
+type Operation string
+
+const (
+	OpCommitGet               Operation = "Commit.Get"
+	OpCommitCreate            Operation = "Commit.Create"
+	OpCommitList              Operation = "Commit.List"
+	OpCommitDiff              Operation = "Commit.Diff"
+	OpCheckConflict           Operation = "Commit.CheckConflict"
+	OpCommitIsAncestor        Operation = "Commit.IsAncestor"
+	OpReferenceGet            Operation = "Reference.Get"
+	OpReferenceUpdate         Operation = "Reference.Update"
+	OpReferenceCreate         Operation = "Reference.Create"
+	OpReferenceList           Operation = "Reference.List"
+	OpReferenceDelete         Operation = "Reference.Delete"
+	OpMergeRequestGet         Operation = "MergeRequest.Get"
+	OpMergeRequestListCommits Operation = "MergeRequest.ListCommits"
+	OpMergeRequestCreate      Operation = "MergeRequest.Create"
+	OpCommentFind             Operation = "Comment.Find"
+	OpCommentCreate           Operation = "Comment.Create"
+	OpCommentUpdate           Operation = "Comment.Update"
+	OpCommentDelete           Operation = "Comment.Delete"
+	OpRepositoryGet           Operation = "Repository.Get"
+	OpPick                    Operation = "Pick.Pick"
+	OpRevert                  Operation = "Pick.Revert"
+)
+
+// Author is the author of every commit of the provider
+var Author = tp.Signature{Name: "norn", Email: "norn@example.com"}
+
+// Provider is an in-memory provider for tests, it implements all of types.Provider.
+// The repo argument of the services is ignored, the provider holds a single repository.
+type Provider struct {
+	mu sync.Mutex
+
+	commits       map[string]*commitObject
+	trees         map[string]map[string]string // tree sha -> path -> content
+	branches      map[string]string            // branch -> head sha
+	mergeRequests map[string]*mergeRequestObject
+	errors        map[Operation]error
+	pickErrors    map[string]error // branch -> error
+	seq           int
+
+	commitService       *CommitService
+	referenceService    *ReferenceService
+	mergeRequestService *MergeRequestService
+	commentService      *CommentService
+	pickService         *PickService
+	repositoryService   *RepositoryService
+}
+
+type commitObject struct {
+	sha     string
+	tree    string
+	message string
+	parents []string
+}
+
+type mergeRequestObject struct {
+	id          string
+	title       string
+	description string
+	state       tp.MergeRequestState
+	commits     []string
+	comments    []*Comment
+	head        string
+	base        string
+	labels      []string
+}
+
+func NewProvider() *Provider {
+	p := &Provider{
+		commits:       map[string]*commitObject{},
+		trees:         map[string]map[string]string{},
+		branches:      map[string]string{},
+		mergeRequests: map[string]*mergeRequestObject{},
+		errors:        map[Operation]error{},
+		pickErrors:    map[string]error{},
+	}
+	p.commitService = &CommitService{p: p}
+	p.referenceService = &ReferenceService{p: p}
+	p.mergeRequestService = &MergeRequestService{p: p}
+	p.commentService = &CommentService{p: p}
+	p.pickService = &PickService{p: p}
+	p.repositoryService = &RepositoryService{p: p}
+	return p
+}
+
+func (p *Provider) Commit() tp.CommitService {
+	return p.commitService
+}
+
+func (p *Provider) Reference() tp.ReferenceService {
+	return p.referenceService
+}
+
+func (p *Provider) MergeRequest() tp.MergeRequestService {
+	return p.mergeRequestService
+}
+
+func (p *Provider) Comment() tp.CommentService {
+	return p.commentService
+}
+
+func (p *Provider) Repository() tp.RepositoryService {
+	return p.repositoryService
+}
+
+// ProviderID the fake provider behaves like GitHub
+func (p *Provider) ProviderID() tp.ProviderType {
+	return tp.GitHubProvider
+}
+
+func (p *Provider) Pick() tp.PickService {
+	return p.pickService
+}
+
+// SetError makes every call of the operation fail with err, a nil err removes the failure.
+func (p *Provider) SetError(op Operation, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.errors, op)
+		return
+	}
+	p.errors[op] = err
+}
+
+// SetPickError makes picks and reverts onto the branch fail with err, such as types.ErrConflict or types.NotFound.
+func (p *Provider) SetPickError(branch string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.pickErrors, branch)
+		return
+	}
+	p.pickErrors[branch] = err
+}
+
+// CommitFiles commits the files onto the branch and returns the commit sha.
+// The branch is created with a root commit if not exists, an empty content deletes the file.
+func (p *Provider) CommitFiles(branch, message string, files map[string]string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.commitFiles(branch, message, files)
+}
+
+// MergeFiles creates a merge commit of the from branch on the branch. The tree is the tree of
+// the branch with the files written, like a merge resolved by hand.
+func (p *Provider) MergeFiles(branch, from, message string, files map[string]string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.commitFiles(branch, message, files, p.branches[from])
+}
+
+func (p *Provider) commitFiles(branch, message string, files map[string]string, merged ...string) string {
+	content := map[string]string{}
+	var parents []string
+	if head, ok := p.branches[branch]; ok {
+		for path, c := range p.trees[p.commits[head].tree] {
+			content[path] = c
+		}
+		parents = []string{head}
+	}
+	parents = append(parents, merged...)
+	for path, c := range files {
+		if c == "" {
+			delete(content, path)
+			continue
+		}
+		content[path] = c
+	}
+	sha := p.writeCommit(p.writeTree(content), message, parents)
+	p.branches[branch] = sha
+	return sha
+}
+
+// CreateBranch creates or resets the branch to the commit.
+func (p *Provider) CreateBranch(branch, sha string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.branches[branch] = sha
+}
+
+// Branch returns the head of the branch, empty if not exists.
+func (p *Provider) Branch(branch string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.branches[branch]
+}
+
+// File returns the content of the file at the branch or commit.
+func (p *Provider) File(rev, path string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	commit := p.resolve(rev)
+	if commit == nil {
+		return "", false
+	}
+	content, ok := p.trees[commit.tree][path]
+	return content, ok
+}
+
+// CommitMessage returns the message of the branch head or commit.
+func (p *Provider) CommitMessage(rev string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if commit := p.resolve(rev); commit != nil {
+		return commit.message
+	}
+	return ""
+}
+
+// AddMergeRequest adds a merge request, comments can be added to unknown merge requests too.
+func (p *Provider) AddMergeRequest(id, title, description string, state tp.MergeRequestState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	mr := p.mergeRequest(id)
+	mr.title, mr.description, mr.state = title, description, state
+}
+
+// SetMergeRequestLabels sets the labels of the merge request.
+func (p *Provider) SetMergeRequestLabels(id string, labels ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mergeRequest(id).labels = labels
+}
+
+// MergeRequests returns the ids of the merge requests opened from head to base.
+func (p *Provider) MergeRequests(head, base string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ids []string
+	for id, mr := range p.mergeRequests {
+		if mr.head == head && mr.base == base {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SetMergeRequestCommits sets the commits of the merge request, oldest first.
+func (p *Provider) SetMergeRequestCommits(id string, shas ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mergeRequest(id).commits = shas
+}
+
+// Comments returns the body of the comments on the merge request.
+func (p *Provider) Comments(mergeRequestID string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var bodies []string
+	if mr, ok := p.mergeRequests[mergeRequestID]; ok {
+		for _, c := range mr.comments {
+			bodies = append(bodies, c.body)
+		}
+	}
+	return bodies
+}
+
+// fail returns the injected error of the operation
+func (p *Provider) fail(op Operation) error {
+	return p.errors[op]
+}
+
+// resolve returns the commit of a branch name or sha, the sha can be abbreviated
+func (p *Provider) resolve(rev string) *commitObject {
+	rev = strings.TrimPrefix(strings.TrimPrefix(rev, "refs/"), "heads/")
+	if sha, ok := p.branches[rev]; ok {
+		return p.commits[sha]
+	}
+	if commit, ok := p.commits[rev]; ok {
+		return commit
+	}
+	if len(rev) < 4 {
+		return nil
+	}
+	for sha, commit := range p.commits {
+		if strings.HasPrefix(sha, rev) {
+			return commit
+		}
+	}
+	return nil
+}
+
+func (p *Provider) mergeRequest(id string) *mergeRequestObject {
+	mr, ok := p.mergeRequests[id]
+	if !ok {
+		mr = &mergeRequestObject{id: id, state: tp.MergeRequestStateOpen}
+		p.mergeRequests[id] = mr
+	}
+	return mr
+}
+
+// writeTree stores the tree and returns its sha, equal contents have the same sha
+func (p *Provider) writeTree(content map[string]string) string {
+	paths := make([]string, 0, len(content))
+	for path := range content {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	h := sha1.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%s\x00", path, content[path])
+	}
+	sha := fmt.Sprintf("%x", h.Sum(nil))
+	p.trees[sha] = content
+	return sha
+}
+
+// writeCommit stores the commit and returns its sha, every commit has a unique sha
+func (p *Provider) writeCommit(tree, message string, parents []string) string {
+	p.seq++
+	sha := fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s", p.seq, tree, message, parents))))
+	p.commits[sha] = &commitObject{sha: sha, tree: tree, message: message, parents: parents}
+	return sha
+}
+
+// isAncestor check if ancestor is reachable from sha
+func (p *Provider) isAncestor(ancestor, sha string) bool {
+	queue := []string{sha}
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == ancestor {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		if commit, ok := p.commits[current]; ok {
+			queue = append(queue, commit.parents...)
+		}
+	}
+	return false
+}
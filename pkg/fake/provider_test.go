@@ -0,0 +1,42 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestProvider_CommitFiles(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a", "b.txt": "b"})
+	head := p.CommitFiles("master", "delete b", map[string]string{"b.txt": ""})
+	if p.Branch("master") != head {
+		t.Fatalf("branch head = %s, want %s", p.Branch("master"), head)
+	}
+	if _, ok := p.File("master", "b.txt"); ok {
+		t.Fatalf("b.txt is not deleted")
+	}
+	if content, _ := p.File(root, "b.txt"); content != "b" {
+		t.Fatalf("content: %s", content)
+	}
+	if !p.isAncestor(root, head) || p.isAncestor(head, root) {
+		t.Fatalf("ancestry is wrong")
+	}
+}
+
+func TestProvider_SetError(t *testing.T) {
+	p := NewProvider()
+	p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	ctx := context.Background()
+	injected := errors.New("boom")
+
+	p.SetError(OpReferenceGet, injected)
+	if _, err := p.Reference().Get(ctx, &tp.GetRefOption{Ref: "master"}); err != injected {
+		t.Fatalf("err = %v, want injected", err)
+	}
+	p.SetError(OpReferenceGet, nil)
+	if _, err := p.Reference().Get(ctx, &tp.GetRefOption{Ref: "master"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
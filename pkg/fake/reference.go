@@ -0,0 +1,128 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"sort"
+	"strings"
+)
+
+type ReferenceService struct {
+	p *Provider
+}
+
+// Get reference, "main", "heads/main" and "refs/heads/main" are the same branch
+func (s *ReferenceService) Get(ctx context.Context, opt *tp.GetRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpReferenceGet); err != nil {
+		return nil, err
+	}
+	branch := branchName(opt.Ref)
+	sha, ok := s.p.branches[branch]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	return &tp.Reference{Ref: "refs/heads/" + branch, SHA: sha}, nil
+}
+
+// Update updates the reference, only fast-forward is allowed without Force.
+func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	if opt.Force && opt.ExpectedSHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpReferenceUpdate); err != nil {
+		return nil, err
+	}
+	branch := branchName(opt.Ref)
+	current, ok := s.p.branches[branch]
+	if !ok {
+		return nil, tp.NotFound
+	}
+	if _, ok = s.p.commits[opt.SHA]; !ok {
+		return nil, tp.NotFound
+	}
+	if opt.ExpectedSHA != "" && current != opt.ExpectedSHA {
+		return nil, tp.ErrStaleRef
+	}
+	if !opt.Force && !s.p.isAncestor(current, opt.SHA) {
+		return nil, fmt.Errorf("reference: %s update is not a fast forward", opt.Ref)
+	}
+	s.p.branches[branch] = opt.SHA
+	return &tp.Reference{Ref: "refs/heads/" + branch, SHA: opt.SHA}, nil
+}
+
+// branchName trims the ref prefix, "refs/heads/main" and "heads/main" both return "main"
+func branchName(ref string) string {
+	ref = strings.TrimPrefix(ref, "refs/")
+	return strings.TrimPrefix(ref, "heads/")
+}
+
+// Create creates the branch at the commit
+func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpReferenceCreate); err != nil {
+		return nil, err
+	}
+	branch := branchName(opt.Ref)
+	if _, ok := s.p.branches[branch]; ok {
+		return nil, tp.ErrAlreadyExists
+	}
+	commit := s.p.resolve(opt.SHA)
+	if commit == nil {
+		return nil, tp.NotFound
+	}
+	s.p.branches[branch] = commit.sha
+	return &tp.Reference{Ref: "refs/heads/" + branch, SHA: commit.sha}, nil
+}
+
+// List returns the branches whose name starts with the prefix, sorted by name
+func (s *ReferenceService) List(ctx context.Context, opt *tp.ListRefOption) ([]*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpReferenceList); err != nil {
+		return nil, err
+	}
+	var refs []*tp.Reference
+	for branch, sha := range s.p.branches {
+		if strings.HasPrefix(branch, opt.Prefix) {
+			refs = append(refs, &tp.Reference{Ref: "refs/heads/" + branch, SHA: sha})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Ref < refs[j].Ref })
+	return refs, nil
+}
+
+// Delete deletes the branch
+func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteRefOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	if err := s.p.fail(OpReferenceDelete); err != nil {
+		return err
+	}
+	branch := branchName(opt.Ref)
+	if _, ok := s.p.branches[branch]; !ok {
+		return tp.NotFound
+	}
+	delete(s.p.branches, branch)
+	return nil
+}
@@ -0,0 +1,57 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestReferenceService_Update(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	head := p.CommitFiles("master", "change a", map[string]string{"a.txt": "b"})
+	p.CreateBranch("r1", root)
+	ctx := context.Background()
+
+	if _, err := p.Reference().Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: head, ExpectedSHA: head}); err != tp.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	if _, err := p.Reference().Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: head, ExpectedSHA: root}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := p.Reference().Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: root}); err == nil {
+		t.Fatalf("update is not a fast forward")
+	}
+	ref, err := p.Reference().Get(ctx, &tp.GetRefOption{Ref: "heads/r1"})
+	if err != nil || ref.SHA != head {
+		t.Fatalf("get: %v %+v", err, ref)
+	}
+	// reset
+	if _, err = p.Reference().Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: root, Force: true}); err != tp.ErrInvalidOptions {
+		t.Fatalf("err = %v, want invalid options", err)
+	}
+	if _, err = p.Reference().Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: root, ExpectedSHA: head, Force: true}); err != nil || p.Branch("r1") != root {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestReferenceService_ListDelete(t *testing.T) {
+	p := NewProvider()
+	root := p.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	p.CreateBranch("norn/tmp/run/pick-r1-012345678", root)
+	ctx := context.Background()
+
+	refs, err := p.Reference().List(ctx, &tp.ListRefOption{Prefix: tp.TempRefPrefix})
+	if err != nil || len(refs) != 1 || refs[0].Ref != "refs/heads/norn/tmp/run/pick-r1-012345678" || refs[0].SHA != root {
+		t.Fatalf("err: %v refs: %+v", err, refs)
+	}
+	if err = p.Reference().Delete(ctx, &tp.DeleteRefOption{Ref: refs[0].Ref}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if p.Branch("norn/tmp/run/pick-r1-012345678") != "" {
+		t.Fatalf("branch is not deleted")
+	}
+	if err = p.Reference().Delete(ctx, &tp.DeleteRefOption{Ref: refs[0].Ref}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
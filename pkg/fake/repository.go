@@ -0,0 +1,62 @@
+package fake
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+)
+
+type RepositoryService struct {
+	p *Provider
+}
+
+type Repository struct {
+	name     string
+	fullName string
+}
+
+// Get returns a repository named after the request.
+func (r *RepositoryService) Get(ctx context.Context, opt *tp.GetRepositoryOption) (tp.Repository, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	r.p.mu.Lock()
+	defer r.p.mu.Unlock()
+	if err := r.p.fail(OpRepositoryGet); err != nil {
+		return nil, err
+	}
+	name := opt.Repo[strings.LastIndex(opt.Repo, "/")+1:]
+	return &Repository{name: name, fullName: opt.Repo}, nil
+}
+
+func (r *Repository) Name() string {
+	return r.name
+}
+
+func (r *Repository) FullName() string {
+	return r.fullName
+}
+
+func (r *Repository) GitUrl() string {
+	return ""
+}
+
+func (r *Repository) DefaultBranch() string {
+	return "master"
+}
+
+func (r *Repository) AllowSquashMerge() *bool {
+	return nil
+}
+
+func (r *Repository) DeleteBranchOnMerge() *bool {
+	return nil
+}
+
+func (r *Repository) AllowRebaseMerge() *bool {
+	return nil
+}
+
+func (r *Repository) Private() *bool {
+	return nil
+}
@@ -0,0 +1,294 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type PickService struct {
+	client *Client
+	runID  string // names the dry run branches, see tp.TempRef
+}
+
+type applyOption struct {
+	SHA       string
+	Diff      string // the diff of SHA, fetched when empty
+	Branch    string
+	NewBranch string // apply to a new branch created from Branch
+	Message   string
+	// Author, Committer and Dates of the new commit, the user of the token now if nil
+	Author    *identity
+	Committer *identity
+	Dates     *commitDates
+}
+
+type diffPatchOption struct {
+	Branch    string       `json:"branch,omitempty"`
+	NewBranch string       `json:"new_branch,omitempty"`
+	Content   string       `json:"content"`
+	Message   string       `json:"message,omitempty"`
+	Author    *identity    `json:"author,omitempty"`
+	Committer *identity    `json:"committer,omitempty"`
+	Dates     *commitDates `json:"dates,omitempty"`
+}
+
+type identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type commitDates struct {
+	Author    time.Time `json:"author"`
+	Committer time.Time `json:"committer"`
+}
+
+type fileCommit struct {
+	SHA  string `json:"sha"`
+	Tree struct {
+		SHA string `json:"sha"`
+	} `json:"tree"`
+}
+
+type fileResponse struct {
+	Commit fileCommit `json:"commit"`
+}
+
+func NewPickService(client *Client) *PickService {
+	return &PickService{
+		client: client,
+		runID:  tp.NewRunID(),
+	}
+}
+
+// Pick cherry-pick the commit to the target branch
+// Gitea has no cherry-pick API, the diff of the commit is applied with the diffpatch API.
+func (c *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	repoOpt, sourceCommit, diff, err := c.source(ctx, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+	message := opt.Message
+	if message == "" {
+		message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", sourceCommit.Commit.Message, shortSHA(sourceCommit.SHA, 7))
+	}
+	apply := &applyOption{
+		SHA:     opt.SHA,
+		Diff:    diff,
+		Branch:  opt.Branch,
+		Message: message,
+	}
+	apply.pickIdentity(sourceCommit, opt.Committer, opt.DateMode)
+	return c.apply(ctx, repoOpt, opt, apply)
+}
+
+// pickIdentity keeps the author of the source and sets the committer, the committer of the source
+// if committer is nil, with the committer date of the mode
+func (o *applyOption) pickIdentity(source *giteaCommit, committer *tp.Signature, mode tp.DateMode) {
+	author, original := source.Commit.Author, source.Commit.Committer
+	o.Author = &identity{Name: author.Name, Email: author.Email}
+	o.Committer = &identity{Name: original.Name, Email: original.Email}
+	if committer != nil {
+		o.Committer = &identity{Name: committer.Name, Email: committer.Email}
+	}
+	o.Dates = &commitDates{Author: author.Date, Committer: mode.CommitterDate(original.Date)}
+}
+
+// Revert reverts the commit on the target branch, the reversed diff of the commit is applied
+func (c *PickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	repoOpt, sourceCommit, diff, err := c.source(ctx, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+	return c.apply(ctx, repoOpt, opt, &applyOption{
+		SHA:     opt.SHA,
+		Diff:    reverseDiff(diff),
+		Branch:  opt.Branch,
+		Message: tp.RevertMessage(sourceCommit.SHA, sourceCommit.Commit.Message),
+	})
+}
+
+// source checks the target branch and returns the commit to pick or revert with its diff
+func (c *PickService) source(ctx context.Context, repo string, opt *tp.PickOption) (*RepoOption, *giteaCommit, string, error) {
+	repoOpt, err := parseRepo(repo)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if opt == nil || opt.SHA == "" {
+		return nil, nil, "", tp.ErrInvalidOptions
+	}
+
+	// get target branch details
+	_, err = c.client.Do(ctx, http.MethodGet, repoOpt.repoPath()+"/branches/"+escapeBranch(opt.Branch), nil, nil)
+	if err != nil {
+		logrus.Warnf("Get target branch %s: %v", opt.Branch, err)
+		return nil, nil, "", tp.NotFound
+	}
+
+	sourceCommit, err := getCommit(ctx, c.client, repoOpt, opt.SHA)
+	if err != nil {
+		logrus.Errorf("Get source commit %s: %v", opt.SHA, err)
+		return nil, nil, "", err
+	}
+
+	// the diff of a merge commit is against its first parent
+	parent, err := tp.MainlineParent(len(sourceCommit.Parents), opt.Mainline)
+	if err != nil {
+		logrus.Warnf("Pick %s: %v", opt.SHA, err)
+		return nil, nil, "", err
+	}
+	if parent != 0 {
+		return nil, nil, "", fmt.Errorf("%w: Gitea picks merge commits against the first parent only", tp.ErrNotSupported)
+	}
+
+	diff, err := getDiff(ctx, c.client, repoOpt, opt.SHA)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	// the diffpatch API commits an empty diff as an empty commit, a diff already applied
+	// to the branch does not apply again and is reported as a conflict
+	if strings.TrimSpace(diff) == "" {
+		return nil, nil, "", tp.ErrEmptyPick
+	}
+	return repoOpt, sourceCommit, diff, nil
+}
+
+// apply applies the diff to the branch, or to a disposable branch in dry run
+func (c *PickService) apply(ctx context.Context, repoOpt *RepoOption, opt *tp.PickOption, apply *applyOption) (*tp.PickResult, error) {
+	if opt.DryRun {
+		// the diffpatch API can not dry run, apply to a disposable branch instead
+		apply.NewBranch = branchName(tp.TempRef(c.runID, fmt.Sprintf("dry-run-%s-%s", branchName(opt.Branch), shortSHA(opt.SHA, 9))))
+	}
+	commit, err := applyCommit(ctx, c.client, repoOpt, apply)
+	if err != nil {
+		logrus.Warnf("Apply %s to %s: %v", opt.SHA, opt.Branch, err)
+		return nil, err
+	}
+	if opt.DryRun {
+		_, err = c.client.Do(ctx, http.MethodDelete, repoOpt.repoPath()+"/branches/"+escapeBranch(apply.NewBranch), nil, nil)
+		if err != nil {
+			logrus.Warnf("Failed to delete branch %s: %v", apply.NewBranch, err)
+		}
+		return &tp.PickResult{Tree: commit.Tree.SHA}, nil
+	}
+	logrus.Debugf("Apply %s to %s: %s", opt.SHA, opt.Branch, commit.SHA)
+	return &tp.PickResult{SHA: commit.SHA, Tree: commit.Tree.SHA}, nil
+}
+
+// applyCommit apply the diff of the commit to the branch, returns the new commit
+func applyCommit(ctx context.Context, client *Client, repoOpt *RepoOption, opt *applyOption) (*fileCommit, error) {
+	diff := opt.Diff
+	if diff == "" {
+		var err error
+		if diff, err = getDiff(ctx, client, repoOpt, opt.SHA); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &fileResponse{}
+	_, err := client.Do(ctx, http.MethodPost, repoOpt.repoPath()+"/diffpatch", &diffPatchOption{
+		Branch:    branchName(opt.Branch),
+		NewBranch: opt.NewBranch,
+		Content:   diff,
+		Message:   opt.Message,
+		Author:    opt.Author,
+		Committer: opt.Committer,
+		Dates:     opt.Dates,
+	}, resp)
+	if err != nil {
+		return nil, applyError(err)
+	}
+	return &resp.Commit, nil
+}
+
+// applyError converts the diffpatch API error to provider error
+func applyError(err error) error {
+	switch statusCode(err) {
+	case http.StatusNotFound:
+		return tp.NotFound
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		// the patch does not apply to the branch
+		return tp.ErrConflict
+	default:
+		return err
+	}
+}
+
+// reverseDiff returns the diff undoing the diff, the sides of the files and hunks are swapped.
+// Binary diffs are kept, they do not apply and are reported as a conflict.
+func reverseDiff(diff string) string {
+	var out strings.Builder
+	var oldFile string // the --- line waiting for its +++ line
+	inHunk := false
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		body := strings.TrimSuffix(line, "\n")
+		eol := line[len(body):]
+		switch {
+		case strings.HasPrefix(body, "diff --git "):
+			inHunk = false
+			if a, b, ok := strings.Cut(strings.TrimPrefix(body, "diff --git "), " b/"); ok {
+				body = "diff --git a/" + b + " b/" + strings.TrimPrefix(a, "a/")
+			}
+		case inHunk && strings.HasPrefix(body, "+"):
+			body = "-" + body[1:]
+		case inHunk && strings.HasPrefix(body, "-"):
+			body = "+" + body[1:]
+		case inHunk:
+			// context and "\ No newline at end of file"
+		case strings.HasPrefix(body, "@@ "):
+			inHunk = true
+			fields := strings.SplitN(body, " ", 4)
+			if len(fields) >= 3 {
+				fields[1], fields[2] = "-"+strings.TrimPrefix(fields[2], "+"), "+"+strings.TrimPrefix(fields[1], "-")
+				body = strings.Join(fields, " ")
+			}
+		case strings.HasPrefix(body, "--- "):
+			oldFile = strings.TrimPrefix(body, "--- ")
+			continue
+		case strings.HasPrefix(body, "+++ "):
+			newFile := strings.TrimPrefix(body, "+++ ")
+			out.WriteString("--- " + swapSide(newFile, "b/", "a/") + eol)
+			body = "+++ " + swapSide(oldFile, "a/", "b/")
+		case strings.HasPrefix(body, "new file mode "):
+			body = "deleted file mode " + strings.TrimPrefix(body, "new file mode ")
+		case strings.HasPrefix(body, "deleted file mode "):
+			body = "new file mode " + strings.TrimPrefix(body, "deleted file mode ")
+		case strings.HasPrefix(body, "old mode "):
+			body = "new mode " + strings.TrimPrefix(body, "old mode ")
+		case strings.HasPrefix(body, "new mode "):
+			body = "old mode " + strings.TrimPrefix(body, "new mode ")
+		case strings.HasPrefix(body, "rename from "):
+			body = "rename to " + strings.TrimPrefix(body, "rename from ")
+		case strings.HasPrefix(body, "rename to "):
+			body = "rename from " + strings.TrimPrefix(body, "rename to ")
+		case strings.HasPrefix(body, "index "):
+			if ids, mode, ok := strings.Cut(strings.TrimPrefix(body, "index "), " "); ok {
+				body = "index " + swapRange(ids) + " " + mode
+			} else {
+				body = "index " + swapRange(ids)
+			}
+		}
+		out.WriteString(body + eol)
+	}
+	return out.String()
+}
+
+// swapSide replaces the prefix of a path of a diff header, /dev/null is kept
+func swapSide(path, from, to string) string {
+	if strings.HasPrefix(path, from) {
+		return to + strings.TrimPrefix(path, from)
+	}
+	return path
+}
+
+// swapRange swaps the blob ids of an index line
+func swapRange(ids string) string {
+	if a, b, ok := strings.Cut(ids, ".."); ok {
+		return b + ".." + a
+	}
+	return ids
+}
@@ -0,0 +1,182 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPickService_Pick(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "base"
+	f.commits["0123456789abcdef"] = "fix: bug"
+	f.diffs["0123456789abcdef"] = "diff --git a/a.txt b/a.txt\n"
+	ctx := context.Background()
+	service := NewPickService(setup(t, f))
+
+	result, err := service.Pick(ctx, "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != "patched-1" || result.SHA != f.branches["release/1.0"] || result.Tree != "tree-patched-1" {
+		t.Fatalf("result: %+v", result)
+	}
+	if len(f.patches) != 1 || f.patches[0].Content != f.diffs["0123456789abcdef"] {
+		t.Fatalf("patches: %+v", f.patches)
+	}
+	if !strings.HasSuffix(f.patches[0].Message, "(cherry picked from commit 0123456)") {
+		t.Fatalf("message: %q", f.patches[0].Message)
+	}
+
+	// conflict
+	f.conflict["release/1.0"] = true
+	_, err = service.Pick(ctx, "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0"})
+	if err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+
+	// target not found
+	_, err = service.Pick(ctx, "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "missing"})
+	if err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestPickService_PickDryRun(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "base"
+	f.commits["0123456789abcdef"] = "fix: bug"
+	f.diffs["0123456789abcdef"] = "diff --git a/a.txt b/a.txt\n"
+	service := NewPickService(setup(t, f))
+
+	result, err := service.Pick(context.Background(), "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != "" || result.Tree != "tree-patched-1" {
+		t.Fatalf("result: %+v", result)
+	}
+	// applied to a disposable branch which is deleted, the target is untouched
+	if len(f.patches) != 1 || !strings.HasPrefix(f.patches[0].NewBranch, tp.TempRefPrefix) || len(f.branches) != 1 || f.branches["release/1.0"] != "base" {
+		t.Fatalf("patches: %+v branches: %v", f.patches, f.branches)
+	}
+}
+
+func TestPickService_PickCommitter(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "base"
+	f.commits["0123456789abcdef"] = "fix: bug"
+	f.diffs["0123456789abcdef"] = "diff --git a/a.txt b/a.txt\n"
+	ctx := context.Background()
+	service := NewPickService(setup(t, f))
+
+	_, err := service.Pick(ctx, "kentio/norn", &tp.PickOption{
+		SHA:       "0123456789abcdef",
+		Branch:    "release/1.0",
+		Committer: &tp.Signature{Name: "bot", Email: "bot@example.com"},
+		DateMode:  tp.DateOriginal,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	patch := f.patches[0]
+	if *patch.Author != (identity{Name: "author", Email: "author@example.com"}) || *patch.Committer != (identity{Name: "bot", Email: "bot@example.com"}) {
+		t.Fatalf("author: %+v committer: %+v", patch.Author, patch.Committer)
+	}
+	if !patch.Dates.Author.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || !patch.Dates.Committer.Equal(time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("dates: %+v", patch.Dates)
+	}
+
+	// the committer of the source is kept with the current date by default
+	if _, err = service.Pick(ctx, "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	patch = f.patches[1]
+	if patch.Committer.Name != "committer" || time.Since(patch.Dates.Committer) > time.Hour {
+		t.Fatalf("committer: %+v dates: %+v", patch.Committer, patch.Dates)
+	}
+}
+
+func TestPickService_PickEmpty(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "base"
+	f.commits["0123456789abcdef"] = "chore: empty"
+	f.diffs["0123456789abcdef"] = ""
+	service := NewPickService(setup(t, f))
+
+	_, err := service.Pick(context.Background(), "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0"})
+	if err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+	if len(f.patches) != 0 || f.branches["release/1.0"] != "base" {
+		t.Fatalf("patches: %+v branches: %v", f.patches, f.branches)
+	}
+}
+
+func TestPickService_Revert(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "base"
+	f.commits["0123456789abcdef"] = "fix: bug"
+	f.diffs["0123456789abcdef"] = "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n+b\n"
+	service := NewPickService(setup(t, f))
+
+	result, err := service.Revert(context.Background(), "kentio/norn", &tp.PickOption{SHA: "0123456789abcdef", Branch: "release/1.0"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != "patched-1" || len(f.patches) != 1 {
+		t.Fatalf("result: %+v patches: %+v", result, f.patches)
+	}
+	want := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n+a\n-b\n"
+	if f.patches[0].Content != want || f.patches[0].Message != tp.RevertMessage("0123456789abcdef", "fix: bug") {
+		t.Fatalf("patch: %+v", f.patches[0])
+	}
+}
+
+func TestReverseDiff(t *testing.T) {
+	diff := "diff --git a/old.txt b/new.txt\n" +
+		"similarity index 90%\n" +
+		"rename from old.txt\n" +
+		"rename to new.txt\n" +
+		"index 0ddd0f3..f0af9b5 100644\n" +
+		"--- a/old.txt\n" +
+		"+++ b/new.txt\n" +
+		"@@ -1,3 +1,4 @@ func main() {\n" +
+		" l1\n" +
+		"--- l2\n" +
+		"+l3\n" +
+		"+l4\n" +
+		"\\ No newline at end of file\n" +
+		"diff --git a/c.txt b/c.txt\n" +
+		"new file mode 100644\n" +
+		"index 0000000..3e75765\n" +
+		"--- /dev/null\n" +
+		"+++ b/c.txt\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+c\n"
+	want := "diff --git a/new.txt b/old.txt\n" +
+		"similarity index 90%\n" +
+		"rename to old.txt\n" +
+		"rename from new.txt\n" +
+		"index f0af9b5..0ddd0f3 100644\n" +
+		"--- a/new.txt\n" +
+		"+++ b/old.txt\n" +
+		"@@ -1,4 +1,3 @@ func main() {\n" +
+		" l1\n" +
+		"+-- l2\n" +
+		"-l3\n" +
+		"-l4\n" +
+		"\\ No newline at end of file\n" +
+		"diff --git a/c.txt b/c.txt\n" +
+		"deleted file mode 100644\n" +
+		"index 3e75765..0000000\n" +
+		"--- a/c.txt\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-c\n"
+	if got := reverseDiff(diff); got != want {
+		t.Fatalf("reverseDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const apiPath = "/api/v1"
+
+// Client is a minimal Gitea/Forgejo API client, only the endpoints used by norn are supported.
+type Client struct {
+	baseUrl    string
+	token      string
+	httpClient *http.Client
+}
+
+// APIError is returned when the API responds a non 2xx status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+}
+
+// NewClient returns a new client, baseUrl can be the instance url or the api url.
+func NewClient(baseUrl, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+	if !strings.HasSuffix(baseUrl, apiPath) {
+		baseUrl += apiPath
+	}
+	return &Client{
+		baseUrl:    baseUrl,
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// Do send the request, body is encoded as json and the response is decoded into out if not nil
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var message struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &message) == nil && message.Message != "" {
+			apiErr.Message = message.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(content))
+		}
+		return resp, apiErr
+	}
+
+	if out == nil || len(content) == 0 {
+		return resp, nil
+	}
+	if raw, ok := out.(*string); ok {
+		*raw = string(content)
+		return resp, nil
+	}
+	return resp, json.Unmarshal(content, out)
+}
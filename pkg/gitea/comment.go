@@ -0,0 +1,163 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+type CommentService struct {
+	client *Client
+}
+
+type Comment struct {
+	commentId string
+	body      string
+}
+
+type giteaComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+type commentOption struct {
+	Body string `json:"body"`
+}
+
+// commentPageSize is the page size used to list comments
+const commentPageSize = 50
+
+func NewCommentService(client *Client) *CommentService {
+	return &CommentService{
+		client: client,
+	}
+}
+
+// Create Comment creates a new comment on the given pull request.
+func (s *CommentService) Create(ctx context.Context, opt *tp.CreateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		logrus.Errorf("Failed to parse repo: %v", err)
+		return nil, err
+	}
+	mergeId, err := strconv.Atoi(opt.MergeRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	comment := &giteaComment{}
+	path := fmt.Sprintf("%s/issues/%d/comments", repoOpt.repoPath(), mergeId)
+	_, err = s.client.Do(ctx, http.MethodPost, path, &commentOption{Body: opt.Body}, comment)
+	if err != nil {
+		logrus.Warnf("Failed to add comment: %v", err)
+		return nil, err
+	}
+	return newComment(comment), nil
+}
+
+// Find Comment finds comments on the given pull request.
+func (s *CommentService) Find(ctx context.Context, opt *tp.FindCommentOption) ([]tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Find Comment Opt: %+v", *opt)
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		logrus.Errorf("Failed to parse repo: %v", err)
+		return nil, err
+	}
+	mergeId, err := strconv.Atoi(opt.MergeRequestID)
+	if err != nil {
+		logrus.Errorf("failed to convert merge id to int: %v", err)
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	var result []tp.Comment
+	for page := 1; ; page++ {
+		var comments []*giteaComment
+		path := fmt.Sprintf("%s/issues/%d/comments?page=%d&limit=%d", repoOpt.repoPath(), mergeId, page, commentPageSize)
+		_, err = s.client.Do(ctx, http.MethodGet, path, nil, &comments)
+		if err != nil {
+			logrus.Warnf("Failed to list comments: %v", err)
+			return nil, err
+		}
+		for _, c := range comments {
+			result = append(result, newComment(c))
+		}
+		if len(comments) < commentPageSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Update Comment updates a comment on the given pull request.
+func (s *CommentService) Update(ctx context.Context, opt *tp.UpdateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Update Comment Opt: %+v", *opt)
+	commentID, err := strconv.ParseInt(opt.CommentID, 10, 64)
+	if err != nil {
+		logrus.Errorf("failed to convert comment id to int: %v", err)
+		return nil, err
+	}
+
+	comment := &giteaComment{}
+	path := fmt.Sprintf("%s/issues/comments/%d", repoOpt.repoPath(), commentID)
+	_, err = s.client.Do(ctx, http.MethodPatch, path, &commentOption{Body: opt.Body}, comment)
+	if err != nil {
+		logrus.Warnf("Failed to update comment: %v", err)
+		return nil, err
+	}
+	return newComment(comment), nil
+}
+
+// Delete Comment deletes a comment on the given pull request.
+func (s *CommentService) Delete(ctx context.Context, opt *tp.DeleteCommentOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return err
+	}
+	commentID, err := strconv.ParseInt(opt.CommentID, 10, 64)
+	if err != nil {
+		logrus.Errorf("failed to convert comment id to int: %v", err)
+		return err
+	}
+
+	path := fmt.Sprintf("%s/issues/comments/%d", repoOpt.repoPath(), commentID)
+	_, err = s.client.Do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		logrus.Warnf("Failed to delete comment: %v", err)
+		return err
+	}
+	return nil
+}
+
+func newComment(comment *giteaComment) *Comment {
+	return &Comment{
+		commentId: strconv.FormatInt(comment.ID, 10),
+		body:      comment.Body,
+	}
+}
+
+func (c *Comment) CommentID() string {
+	return c.commentId
+}
+
+func (c *Comment) Body() string {
+	return c.body
+}
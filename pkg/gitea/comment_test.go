@@ -0,0 +1,38 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestCommentService(t *testing.T) {
+	f := newFakeGitea()
+	ctx := context.Background()
+	service := NewCommentService(setup(t, f))
+
+	// more than one page
+	for i := 0; i < commentPageSize+1; i++ {
+		_, err := service.Create(ctx, &tp.CreateCommentOption{Repo: "kentio/norn", MergeRequestID: "1", Body: fmt.Sprintf("c%d", i)})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	comments, err := service.Find(ctx, &tp.FindCommentOption{Repo: "kentio/norn", MergeRequestID: "1"})
+	if err != nil || len(comments) != commentPageSize+1 {
+		t.Fatalf("find: %v %d", err, len(comments))
+	}
+
+	comment, err := service.Update(ctx, &tp.UpdateCommentOption{Repo: "kentio/norn", CommentID: "1", Body: "updated"})
+	if err != nil || comment.Body() != "updated" {
+		t.Fatalf("update: %v %+v", err, comment)
+	}
+
+	if err = service.Delete(ctx, &tp.DeleteCommentOption{Repo: "kentio/norn", CommentID: "1"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(f.comments) != commentPageSize {
+		t.Fatalf("comments: %d", len(f.comments))
+	}
+}
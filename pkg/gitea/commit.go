@@ -0,0 +1,280 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Commit struct {
+	sha     string
+	tree    *Tree
+	message string
+	author  tp.Signature
+	parents []string
+}
+
+type Tree struct {
+	sha string
+}
+
+type giteaCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Tree    struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+		Author    giteaSignature `json:"author"`
+		Committer giteaSignature `json:"committer"`
+	} `json:"commit"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+}
+
+type giteaSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type CommitService struct {
+	client *Client
+	runID  string // names the check branches, see tp.TempRef
+}
+
+func NewCommitService(client *Client) *CommitService {
+	return &CommitService{
+		client: client,
+		runID:  tp.NewRunID(),
+	}
+}
+
+// Get Commit returns the commit for the given sha.
+func (s *CommitService) Get(ctx context.Context, opt *tp.GetCommitOption) (tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Get Commit Opt: %+v", *opt)
+	commit, err := getCommit(ctx, s.client, repoOpt, opt.SHA)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Debugf("Get Commit Error: %+v", err)
+		return nil, err
+	}
+	return newCommit(commit), nil
+}
+
+// List returns the recent commits of the branch, newest first
+func (s *CommitService) List(ctx context.Context, opt *tp.ListCommitOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("List Commits Opt: %+v", *opt)
+	var result []tp.Commit
+	for page := 1; len(result) < opt.Limit; page++ {
+		var commits []*giteaCommit
+		path := fmt.Sprintf("%s/commits?sha=%s&page=%d&limit=%d&stat=false&files=false",
+			repoOpt.repoPath(), url.QueryEscape(branchName(opt.Branch)), page, commentPageSize)
+		if _, err = s.client.Do(ctx, http.MethodGet, path, nil, &commits); err != nil {
+			if isNotFound(err) {
+				return nil, tp.NotFound
+			}
+			logrus.Debugf("List Commits Error: %+v", err)
+			return nil, err
+		}
+		for _, c := range commits {
+			result = append(result, newCommit(c))
+		}
+		if len(commits) < commentPageSize {
+			break
+		}
+	}
+	if len(result) > opt.Limit {
+		result = result[:opt.Limit]
+	}
+	return result, nil
+}
+
+// Diff returns the diff of the commit
+func (s *CommitService) Diff(ctx context.Context, opt *tp.GetCommitOption) (string, error) {
+	if opt == nil {
+		return "", tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return "", err
+	}
+	return getDiff(ctx, s.client, repoOpt, opt.SHA)
+}
+
+// Create Commit creates a new commit.
+// Gitea has no git data API to create a commit from a tree, so the commit is created by
+// applying the diff of SHA onto Target with PickMessage.
+func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption) (tp.Commit, error) {
+	if opt == nil || opt.SHA == "" || opt.Target == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Create Commit Opt: %+v", *opt)
+	source, err := getCommit(ctx, s.client, repoOpt, opt.SHA)
+	if err != nil {
+		logrus.Errorf("Get source commit %s: %v", opt.SHA, err)
+		return nil, err
+	}
+	apply := &applyOption{
+		SHA:     opt.SHA,
+		Branch:  opt.Target,
+		Message: opt.PickMessage,
+	}
+	apply.pickIdentity(source, opt.Committer, opt.DateMode)
+	commit, err := applyCommit(ctx, s.client, repoOpt, apply)
+	if err != nil {
+		logrus.Errorf("Create Commit Error: %v", err)
+		return nil, err
+	}
+	return &Commit{sha: commit.SHA, message: opt.PickMessage}, nil
+}
+
+// IsAncestor Gitea has no API telling if a commit is in the history of a branch
+func (s *CommitService) IsAncestor(ctx context.Context, opt *tp.AncestorOption) (bool, error) {
+	if opt == nil {
+		return false, tp.ErrInvalidOptions
+	}
+	return false, tp.ErrNotSupported
+}
+
+// CheckConflict check conflict by applying the commit to a disposable branch
+func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflictOption) error {
+	if opts == nil {
+		return tp.ErrInvalidOptions
+	}
+	if opts.Mode != tp.WithAPI {
+		return errors.New("not support check conflict with command")
+	}
+	repoOpt, err := parseRepo(opts.Repo)
+	if err != nil {
+		return err
+	}
+
+	checkBranch := branchName(tp.TempRef(s.runID, fmt.Sprintf("check-%s-%s", branchName(opts.Target), shortSHA(opts.Commit, 9))))
+	_, err = applyCommit(ctx, s.client, repoOpt, &applyOption{
+		SHA:       opts.Commit,
+		Branch:    opts.Target,
+		NewBranch: checkBranch,
+		Message:   fmt.Sprintf("Check conflict of %s", opts.Commit),
+	})
+	if err != nil {
+		logrus.Warnf("Check conflict %s to %s: %v", opts.Commit, opts.Target, err)
+		return err
+	}
+
+	// delete the disposable branch
+	_, err = s.client.Do(ctx, http.MethodDelete, repoOpt.repoPath()+"/branches/"+escapeBranch(checkBranch), nil, nil)
+	if err != nil {
+		logrus.Warnf("Failed to delete branch %s: %v", checkBranch, err)
+	}
+	return nil
+}
+
+// getDiff returns the diff of the commit in the git diff format
+func getDiff(ctx context.Context, client *Client, repoOpt *RepoOption, sha string) (string, error) {
+	var diff string
+	_, err := client.Do(ctx, http.MethodGet, fmt.Sprintf("%s/git/commits/%s.diff", repoOpt.repoPath(), sha), nil, &diff)
+	if err != nil {
+		if isNotFound(err) {
+			return "", tp.NotFound
+		}
+		return "", err
+	}
+	return diff, nil
+}
+
+func getCommit(ctx context.Context, client *Client, repoOpt *RepoOption, sha string) (*giteaCommit, error) {
+	commit := &giteaCommit{}
+	_, err := client.Do(ctx, http.MethodGet, repoOpt.repoPath()+"/git/commits/"+sha, nil, commit)
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+func newCommit(commit *giteaCommit) *Commit {
+	if commit == nil {
+		return nil
+	}
+	parents := make([]string, 0, len(commit.Parents))
+	for _, p := range commit.Parents {
+		parents = append(parents, p.SHA)
+	}
+	return &Commit{
+		sha:     commit.SHA,
+		tree:    &Tree{sha: commit.Commit.Tree.SHA},
+		message: commit.Commit.Message,
+		author:  tp.Signature{Name: commit.Commit.Author.Name, Email: commit.Commit.Author.Email},
+		parents: parents,
+	}
+}
+
+// shortSHA returns the first n characters of the sha
+func shortSHA(sha string, n int) string {
+	if len(sha) < n {
+		return sha
+	}
+	return sha[:n]
+}
+
+// SHA Commit returns the commit sha.
+func (c *Commit) SHA() string {
+	return c.sha
+}
+
+func (c *Commit) Tree() tp.Tree {
+	return c.tree
+}
+
+func (c *Commit) Message() string {
+	return c.message
+}
+
+func (c *Commit) Author() tp.Signature {
+	return c.author
+}
+
+// Parents returns the shas of the parents, the first parent first
+func (c *Commit) Parents() []string {
+	return c.parents
+}
+
+// SHA Tree returns the tree sha.
+func (t *Tree) SHA() string {
+	return t.sha
+}
+
+// Entries Gitea commit API does not return the tree entries.
+func (t *Tree) Entries() []tp.TreeEntry {
+	return nil
+}
+
+func (t *Tree) Truncated() bool {
+	return false
+}
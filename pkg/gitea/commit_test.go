@@ -0,0 +1,63 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestCommitService_Get(t *testing.T) {
+	f := newFakeGitea()
+	f.commits["abc"] = "fix: bug"
+	commit, err := NewCommitService(setup(t, f)).Get(context.Background(), &tp.GetCommitOption{Repo: "kentio/norn", SHA: "abc"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if commit.SHA() != "abc" || commit.Message() != "fix: bug" || commit.Tree().SHA() != "tree-abc" {
+		t.Fatalf("commit: %+v", commit)
+	}
+}
+
+func TestCommitService_CheckConflict(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["master"] = "base"
+	f.diffs["abc"] = "diff"
+	service := NewCommitService(setup(t, f))
+	opt := &tp.CheckConflictOption{Repo: "kentio/norn", Commit: "abc", Target: "master", Mode: tp.WithAPI}
+
+	if err := service.CheckConflict(context.Background(), opt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(f.branches) != 1 || f.branches["master"] != "base" {
+		t.Fatalf("check branch is not deleted or target is changed: %+v", f.branches)
+	}
+	if len(f.patches) != 1 || !strings.HasPrefix(f.patches[0].NewBranch, tp.TempRefPrefix) {
+		t.Fatalf("check branch is not a temporary branch: %+v", f.patches)
+	}
+
+	f.conflict["master"] = true
+	if err := service.CheckConflict(context.Background(), opt); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestCommitService_ListDiff(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "abc"
+	f.commits["abc"] = "fix: bug"
+	f.diffs["abc"] = "diff --git a/a.txt b/a.txt\n"
+	service := NewCommitService(setup(t, f))
+
+	commits, err := service.List(context.Background(), &tp.ListCommitOption{Repo: "kentio/norn", Branch: "refs/heads/release/1.0", Limit: 10})
+	if err != nil || len(commits) != 1 || commits[0].SHA() != "abc" || commits[0].Message() != "fix: bug" {
+		t.Fatalf("err: %v commits: %+v", err, commits)
+	}
+	diff, err := service.Diff(context.Background(), &tp.GetCommitOption{Repo: "kentio/norn", SHA: "abc"})
+	if err != nil || diff != f.diffs["abc"] {
+		t.Fatalf("err: %v diff: %q", err, diff)
+	}
+	if _, err = service.List(context.Background(), &tp.ListCommitOption{Repo: "kentio/norn", Branch: "missing", Limit: 10}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,68 @@
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type RepoOption struct {
+	Owner string
+	Repo  string
+}
+
+// NewGiteaClient returns a new client, BaseUrl is required because Gitea is always self-hosted
+func NewGiteaClient(opt *tp.CreateProviderOption) (*Client, error) {
+	if opt == nil || opt.BaseUrl == nil || *opt.BaseUrl == "" {
+		return nil, fmt.Errorf("%w: base url is required for gitea", tp.ErrInvalidOptions)
+	}
+	return NewClient(*opt.BaseUrl, opt.Token, nil), nil
+}
+
+func parseRepo(repo string) (*RepoOption, error) {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repo: %s", repo)
+	}
+	return &RepoOption{
+		Owner: parts[0],
+		Repo:  parts[1],
+	}, nil
+}
+
+// repoPath returns the api path of the repo, such as /repos/owner/name
+func (r *RepoOption) repoPath() string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(r.Owner), url.PathEscape(r.Repo))
+}
+
+// branchName trims the ref prefix, "refs/heads/main" and "heads/main" both return "main"
+func branchName(ref string) string {
+	ref = strings.TrimPrefix(ref, "refs/")
+	return strings.TrimPrefix(ref, "heads/")
+}
+
+// escapeBranch escape each segment of the branch, the slash is kept
+func escapeBranch(branch string) string {
+	segments := strings.Split(branchName(branch), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// statusCode returns the http status code of the api error, 0 if unknown
+func statusCode(err error) int {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// isNotFound check if the error is a 404 response
+func isNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
@@ -0,0 +1,282 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitea is a stand-in of the Gitea API, only the endpoints used by the provider are served
+type fakeGitea struct {
+	mu       sync.Mutex
+	branches map[string]string // branch -> head sha
+	commits  map[string]string // sha -> message
+	diffs    map[string]string // sha -> diff
+	conflict map[string]bool   // branch -> diffpatch conflicts
+	comments []*giteaComment
+	pulls    []*giteaCommit // commits of the pull requests
+	patches  []diffPatchOption
+	nextID   int64
+}
+
+func newFakeGitea() *fakeGitea {
+	return &fakeGitea{
+		branches: map[string]string{},
+		commits:  map[string]string{},
+		diffs:    map[string]string{},
+		conflict: map[string]bool{},
+	}
+}
+
+// setup starts the stand-in server and returns a client connected to it
+func setup(t *testing.T, f *fakeGitea) *Client {
+	mux := http.NewServeMux()
+	prefix := "/api/v1/repos/{owner}/{repo}"
+	mux.HandleFunc("GET "+prefix+"/branches/{branch...}", f.getBranch)
+	mux.HandleFunc("POST "+prefix+"/branches", f.createBranch)
+	mux.HandleFunc("DELETE "+prefix+"/branches/{branch...}", f.deleteBranch)
+	mux.HandleFunc("GET "+prefix+"/branches", f.listBranches)
+	mux.HandleFunc("GET "+prefix+"/git/commits/{sha}", f.getCommit)
+	mux.HandleFunc("GET "+prefix+"/commits", f.listCommits)
+	mux.HandleFunc("POST "+prefix+"/diffpatch", f.diffPatch)
+	mux.HandleFunc("GET "+prefix+"/pulls/{index}", f.getPull)
+	mux.HandleFunc("GET "+prefix+"/pulls/{index}/commits", f.listPullCommits)
+	mux.HandleFunc("GET "+prefix+"/issues/{index}/comments", f.listComments)
+	mux.HandleFunc("POST "+prefix+"/issues/{index}/comments", f.createComment)
+	mux.HandleFunc("PATCH "+prefix+"/issues/comments/{id}", f.editComment)
+	mux.HandleFunc("DELETE "+prefix+"/issues/comments/{id}", f.deleteComment)
+	mux.HandleFunc("GET "+prefix, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"name":           r.PathValue("repo"),
+			"full_name":      r.PathValue("owner") + "/" + r.PathValue("repo"),
+			"default_branch": "master",
+			"private":        true,
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "token", server.Client())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+}
+
+func (f *fakeGitea) getBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sha, ok := f.branches[r.PathValue("branch")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"name": r.PathValue("branch"), "commit": map[string]string{"id": sha}})
+}
+
+func (f *fakeGitea) createBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	opt := map[string]string{}
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+	name := opt["new_branch_name"]
+	if _, ok := f.branches[name]; ok {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "branch already exists"})
+		return
+	}
+	f.branches[name] = opt["old_ref_name"]
+	writeJSON(w, http.StatusCreated, map[string]any{"name": name, "commit": map[string]string{"id": opt["old_ref_name"]}})
+}
+
+func (f *fakeGitea) deleteBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.branches[r.PathValue("branch")]; !ok {
+		notFound(w)
+		return
+	}
+	delete(f.branches, r.PathValue("branch"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeGitea) listBranches(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	branches := []map[string]any{}
+	for i := (page - 1) * limit; i < len(names) && i < page*limit; i++ {
+		branches = append(branches, map[string]any{"name": names[i], "commit": map[string]string{"id": f.branches[names[i]]}})
+	}
+	writeJSON(w, http.StatusOK, branches)
+}
+
+func (f *fakeGitea) getCommit(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sha := r.PathValue("sha")
+	if strings.HasSuffix(sha, ".diff") {
+		diff, ok := f.diffs[strings.TrimSuffix(sha, ".diff")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		_, _ = fmt.Fprint(w, diff)
+		return
+	}
+	message, ok := f.commits[sha]
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sha": sha,
+		"commit": map[string]any{
+			"message":   message,
+			"tree":      map[string]string{"sha": "tree-" + sha},
+			"author":    map[string]string{"name": "author", "email": "author@example.com", "date": "2024-01-02T03:04:05Z"},
+			"committer": map[string]string{"name": "committer", "email": "committer@example.com", "date": "2024-01-03T03:04:05Z"},
+		},
+		"parents": []map[string]string{{"sha": "parent-" + sha}},
+	})
+}
+
+// listCommits returns the head of the branch, the stand-in has no history
+func (f *fakeGitea) listCommits(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sha, ok := f.branches[r.URL.Query().Get("sha")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, []map[string]any{{
+		"sha":    sha,
+		"commit": map[string]any{"message": f.commits[sha], "tree": map[string]string{"sha": "tree-" + sha}},
+	}})
+}
+
+func (f *fakeGitea) diffPatch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	opt := diffPatchOption{}
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+	if _, ok := f.branches[opt.Branch]; !ok {
+		notFound(w)
+		return
+	}
+	if f.conflict[opt.Branch] {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "patch does not apply"})
+		return
+	}
+	f.patches = append(f.patches, opt)
+	sha := fmt.Sprintf("patched-%d", len(f.patches))
+	f.commits[sha] = opt.Message
+	branch := opt.Branch
+	if opt.NewBranch != "" {
+		branch = opt.NewBranch
+	}
+	f.branches[branch] = sha
+	writeJSON(w, http.StatusCreated, map[string]any{"commit": map[string]any{"sha": sha, "tree": map[string]string{"sha": "tree-" + sha}}})
+}
+
+func (f *fakeGitea) getPull(w http.ResponseWriter, r *http.Request) {
+	index, _ := strconv.Atoi(r.PathValue("index"))
+	writeJSON(w, http.StatusOK, map[string]any{"number": index, "title": "fix", "state": "closed", "merged": true})
+}
+
+func (f *fakeGitea) listPullCommits(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if page, _ := strconv.Atoi(r.URL.Query().Get("page")); page > 1 {
+		writeJSON(w, http.StatusOK, []*giteaCommit{})
+		return
+	}
+	writeJSON(w, http.StatusOK, f.pulls)
+}
+
+func (f *fakeGitea) listComments(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	start, end := (page-1)*limit, page*limit
+	if start > len(f.comments) {
+		start = len(f.comments)
+	}
+	if end > len(f.comments) {
+		end = len(f.comments)
+	}
+	writeJSON(w, http.StatusOK, f.comments[start:end])
+}
+
+func (f *fakeGitea) createComment(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	opt := commentOption{}
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+	f.nextID++
+	comment := &giteaComment{ID: f.nextID, Body: opt.Body}
+	f.comments = append(f.comments, comment)
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (f *fakeGitea) editComment(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	opt := commentOption{}
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+	for _, c := range f.comments {
+		if c.ID == id {
+			c.Body = opt.Body
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	notFound(w)
+}
+
+func (f *fakeGitea) deleteComment(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	for i, c := range f.comments {
+		if c.ID == id {
+			f.comments = append(f.comments[:i], f.comments[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	notFound(w)
+}
+
+func TestNewClient(t *testing.T) {
+	for _, u := range []string{"https://gitea.example.com", "https://gitea.example.com/", "https://gitea.example.com/api/v1"} {
+		if c := NewClient(u, "", nil); c.baseUrl != "https://gitea.example.com/api/v1" {
+			t.Errorf("NewClient(%s) base url = %s", u, c.baseUrl)
+		}
+	}
+}
+
+func TestEscapeBranch(t *testing.T) {
+	if b := escapeBranch("refs/heads/release/1.0 rc"); b != "release/1.0%20rc" {
+		t.Errorf("escapeBranch() = %s", b)
+	}
+}
@@ -0,0 +1,72 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+type Provider struct {
+	providerID tp.ProviderType
+	client     *Client
+
+	commitService       *CommitService
+	referenceService    *ReferenceService
+	mergeRequestService *PullRequestService
+	commentService      *CommentService
+	pickService         *PickService
+	repositoryService   *RepositoryService
+}
+
+func NewProvider(ctx context.Context, opt *tp.CreateProviderOption) (*Provider, error) {
+	client, err := NewGiteaClient(opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Signer != nil {
+		logrus.Warnf("The signing key is ignored, Gitea signs the commits by the key of the instance when it is configured")
+	}
+	return NewProviderWithClient(client), nil
+}
+
+// NewProviderWithClient creates a new provider with the given client.
+func NewProviderWithClient(client *Client) *Provider {
+	return &Provider{
+		providerID:          tp.GiteaProvider,
+		client:              client,
+		commitService:       NewCommitService(client),
+		referenceService:    NewReferenceService(client),
+		mergeRequestService: NewPullRequestService(client),
+		commentService:      NewCommentService(client),
+		pickService:         NewPickService(client),
+		repositoryService:   NewRepositoryService(client),
+	}
+}
+
+func (p *Provider) Commit() tp.CommitService {
+	return p.commitService
+}
+
+func (p *Provider) Reference() tp.ReferenceService {
+	return p.referenceService
+}
+
+func (p *Provider) MergeRequest() tp.MergeRequestService {
+	return p.mergeRequestService
+}
+
+func (p *Provider) Comment() tp.CommentService {
+	return p.commentService
+}
+
+func (p *Provider) Repository() tp.RepositoryService {
+	return p.repositoryService
+}
+
+func (p *Provider) ProviderID() tp.ProviderType {
+	return p.providerID
+}
+
+func (p *Provider) Pick() tp.PickService {
+	return p.pickService
+}
@@ -0,0 +1,67 @@
+package gitea
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/pick"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(context.Background(), &tp.CreateProviderOption{Token: ""}); err == nil {
+		t.Fatalf("base url is required")
+	}
+	baseUrl := "https://gitea.example.com"
+	provider, err := NewProvider(context.Background(), &tp.CreateProviderOption{BaseUrl: &baseUrl})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.ProviderID() != tp.GiteaProvider {
+		t.Fatalf("provider id = %s", provider.ProviderID())
+	}
+}
+
+// TestProvider_ProcessPick runs the summary and result comment workflow against the stand-in
+func TestProvider_ProcessPick(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["r1"] = "r1-head"
+	f.branches["r2"] = "r2-head"
+	f.branches["master"] = "master-head"
+	f.conflict["master"] = true
+	f.commits["0123456789abcdef"] = "fix: bug"
+	f.diffs["0123456789abcdef"] = "diff"
+	ctx := context.Background()
+	service := pick.NewPickService(NewProviderWithClient(setup(t, f)))
+
+	sha := "0123456789abcdef"
+	task := &pick.Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            &sha,
+		MergeRequestID: "1",
+		IsSummary:      true,
+	}
+	if err := service.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("summary err: %v", err)
+	}
+	if len(f.comments) != 1 || !strings.Contains(f.comments[0].Body, tp.CherryPickSummaryFlag) {
+		t.Fatalf("summary comment: %+v", f.comments)
+	}
+
+	task.IsSummary = false
+	if err := service.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("pick err: %v", err)
+	}
+	if len(f.comments) != 2 || !strings.Contains(f.comments[1].Body, tp.CherryPickResultFlag) {
+		t.Fatalf("result comment: %+v", f.comments)
+	}
+	result := f.comments[1].Body
+	if !strings.Contains(result, "r2") || !strings.Contains(result, pick.SucceedStatus) || !strings.Contains(result, pick.FailedStatus) {
+		t.Fatalf("result comment: %s", result)
+	}
+	if f.branches["r2"] == "r2-head" || f.branches["master"] != "master-head" {
+		t.Fatalf("branches: %+v", f.branches)
+	}
+}
@@ -0,0 +1,261 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+type PullRequestService struct {
+	client *Client
+}
+
+type PullRequest struct {
+	id          int
+	title       string
+	description string
+	state       tp.MergeRequestState
+	labels      []string
+	webUrl      string
+}
+
+type giteaPullRequest struct {
+	Number  int           `json:"number"`
+	Title   string        `json:"title"`
+	Body    string        `json:"body"`
+	State   string        `json:"state"`
+	Merged  bool          `json:"merged"`
+	HTMLURL string        `json:"html_url"`
+	Labels  []*giteaLabel `json:"labels"`
+}
+
+type giteaLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type createPullRequestOption struct {
+	Head   string  `json:"head"`
+	Base   string  `json:"base"`
+	Title  string  `json:"title"`
+	Body   string  `json:"body"`
+	Labels []int64 `json:"labels,omitempty"`
+}
+
+func (s *PullRequest) MergeId() string {
+	return strconv.Itoa(s.id)
+}
+
+func (s *PullRequest) Title() string {
+	return s.title
+}
+
+func (s *PullRequest) Description() string {
+	return s.description
+}
+
+func (s *PullRequest) State() tp.MergeRequestState {
+	return s.state
+}
+
+func (s *PullRequest) Labels() []string {
+	return s.labels
+}
+
+func (s *PullRequest) WebUrl() string {
+	return s.webUrl
+}
+
+func NewPullRequestService(client *Client) *PullRequestService {
+	return &PullRequestService{
+		client: client,
+	}
+}
+
+func (s *PullRequestService) Get(ctx context.Context, opt *tp.GetMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Get Pull Request Opt: %+v", *opt)
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeId, err := strconv.Atoi(opt.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+	pr := &giteaPullRequest{}
+	_, err = s.client.Do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", repoOpt.repoPath(), mergeId), nil, pr)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Get PR Error: %+v", err)
+		return nil, err
+	}
+	return newPullRequest(pr), nil
+}
+
+// ListCommits returns the commits of the pull request, ordered so that parents come first
+func (s *PullRequestService) ListCommits(ctx context.Context, opt *tp.ListMergeRequestCommitsOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	mergeId, err := strconv.Atoi(opt.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	var commits []*giteaCommit
+	for page := 1; ; page++ {
+		var list []*giteaCommit
+		path := fmt.Sprintf("%s/pulls/%d/commits?page=%d&limit=%d", repoOpt.repoPath(), mergeId, page, commentPageSize)
+		_, err = s.client.Do(ctx, http.MethodGet, path, nil, &list)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, tp.NotFound
+			}
+			logrus.Errorf("List PR Commits Error: %+v", err)
+			return nil, err
+		}
+		commits = append(commits, list...)
+		if len(list) < commentPageSize {
+			break
+		}
+	}
+
+	var result []tp.Commit
+	for _, c := range sortCommits(commits) {
+		result = append(result, newCommit(c))
+	}
+	return result, nil
+}
+
+// sortCommits orders the commits so that a commit comes after its parents in the list,
+// the order of the API differs between Gitea versions.
+func sortCommits(commits []*giteaCommit) []*giteaCommit {
+	pending := make(map[string]*giteaCommit, len(commits))
+	for _, c := range commits {
+		pending[c.SHA] = c
+	}
+	sorted := make([]*giteaCommit, 0, len(commits))
+	var visit func(c *giteaCommit)
+	visit = func(c *giteaCommit) {
+		if _, ok := pending[c.SHA]; !ok {
+			return
+		}
+		delete(pending, c.SHA)
+		for _, parent := range c.Parents {
+			if p, ok := pending[parent.SHA]; ok {
+				visit(p)
+			}
+		}
+		sorted = append(sorted, c)
+	}
+	for _, c := range commits {
+		visit(c)
+	}
+	return sorted
+}
+
+// Create opens a pull request, Gitea takes label ids so the names are looked up in the repo labels
+func (s *PullRequestService) Create(ctx context.Context, opt *tp.CreateMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Pull Request Opt: %+v", *opt)
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	createOpt := &createPullRequestOption{
+		Head:  branchName(opt.Head),
+		Base:  branchName(opt.Base),
+		Title: opt.Title,
+		Body:  opt.Description,
+	}
+	if len(opt.Labels) > 0 {
+		createOpt.Labels, err = s.labelIDs(ctx, repoOpt, opt.Labels)
+		if err != nil {
+			// the pull request is still useful without labels
+			logrus.Warnf("Get labels of %s: %v", opt.Repo, err)
+		}
+	}
+
+	pr := &giteaPullRequest{}
+	_, err = s.client.Do(ctx, http.MethodPost, repoOpt.repoPath()+"/pulls", createOpt, pr)
+	if err != nil {
+		// Gitea responds 409 if a pull request of the head to the base is open
+		if statusCode(err) == http.StatusConflict {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create PR Error: %+v", err)
+		return nil, err
+	}
+	return newPullRequest(pr), nil
+}
+
+// labelIDs returns the ids of the named labels, unknown names are skipped
+func (s *PullRequestService) labelIDs(ctx context.Context, repoOpt *RepoOption, names []string) ([]int64, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var ids []int64
+	for page := 1; ; page++ {
+		var labels []*giteaLabel
+		path := fmt.Sprintf("%s/labels?page=%d&limit=%d", repoOpt.repoPath(), page, commentPageSize)
+		if _, err := s.client.Do(ctx, http.MethodGet, path, nil, &labels); err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			if wanted[l.Name] {
+				ids = append(ids, l.ID)
+			}
+		}
+		if len(labels) < commentPageSize {
+			break
+		}
+	}
+	return ids, nil
+}
+
+func newPullRequest(pr *giteaPullRequest) *PullRequest {
+	state := getStateFromGiteaPullRequestState(pr.State)
+	if pr.Merged {
+		state = tp.MergeRequestStateMerged
+	}
+	var labels []string
+	for _, l := range pr.Labels {
+		labels = append(labels, l.Name)
+	}
+	return &PullRequest{
+		id:          pr.Number,
+		title:       pr.Title,
+		description: pr.Body,
+		state:       state,
+		labels:      labels,
+		webUrl:      pr.HTMLURL,
+	}
+}
+
+func getStateFromGiteaPullRequestState(state string) tp.MergeRequestState {
+	switch state {
+	case "open":
+		return tp.MergeRequestStateOpen
+	case "closed":
+		return tp.MergeRequestStateClosed
+	default:
+		return tp.MergeRequestStateUnknown
+	}
+}
@@ -0,0 +1,51 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestPullRequestService_Get(t *testing.T) {
+	pr, err := NewPullRequestService(setup(t, newFakeGitea())).Get(context.Background(), &tp.GetMergeRequestOption{
+		Repo:    "kentio/norn",
+		MergeID: "5",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if pr.MergeId() != "5" || pr.State() != tp.MergeRequestStateMerged {
+		t.Fatalf("pr: %+v state %s", pr, pr.State())
+	}
+}
+
+func TestPullRequestService_ListCommits(t *testing.T) {
+	f := newFakeGitea()
+	newCommit := func(sha string, parents ...string) *giteaCommit {
+		c := &giteaCommit{SHA: sha}
+		for _, p := range parents {
+			c.Parents = append(c.Parents, struct {
+				SHA string `json:"sha"`
+			}{SHA: p})
+		}
+		return c
+	}
+	// newest first, like older Gitea versions
+	f.pulls = []*giteaCommit{newCommit("c3", "c2"), newCommit("c2", "c1"), newCommit("c1", "base")}
+
+	commits, err := NewPullRequestService(setup(t, f)).ListCommits(context.Background(), &tp.ListMergeRequestCommitsOption{
+		Repo:    "kentio/norn",
+		MergeID: "5",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var shas []string
+	for _, c := range commits {
+		shas = append(shas, c.SHA())
+	}
+	if strings.Join(shas, ",") != "c1,c2,c3" {
+		t.Fatalf("commits: %v", shas)
+	}
+}
@@ -0,0 +1,147 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// branchPageSize is the page size used to list branches
+const branchPageSize = 50
+
+type ReferenceService struct {
+	client *Client
+}
+
+type giteaBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+func NewReferenceService(client *Client) *ReferenceService {
+	return &ReferenceService{
+		client: client,
+	}
+}
+
+// Get reference, only branches are supported
+func (s *ReferenceService) Get(ctx context.Context, opt *tp.GetRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Get Reference Opt: %+v", opt)
+
+	branch := &giteaBranch{}
+	_, err = s.client.Do(ctx, http.MethodGet, repoOpt.repoPath()+"/branches/"+escapeBranch(opt.Ref), nil, branch)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Get Reference Error: %v", err)
+		return nil, err
+	}
+	return newBranch(branch), nil
+}
+
+// Update Gitea does not support moving a branch to an arbitrary commit.
+func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	return nil, tp.ErrNotSupported
+}
+
+// Create creates the branch at the commit, old_ref_name requires Gitea 1.21 or Forgejo
+func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Create Reference Opt: %+v", opt)
+
+	branch := &giteaBranch{}
+	_, err = s.client.Do(ctx, http.MethodPost, repoOpt.repoPath()+"/branches", map[string]string{
+		"new_branch_name": branchName(opt.Ref),
+		"old_ref_name":    opt.SHA,
+	}, branch)
+	if err != nil {
+		if statusCode(err) == http.StatusConflict {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create Reference Error: %v", err)
+		return nil, err
+	}
+	return newBranch(branch), nil
+}
+
+// List returns the branches whose name starts with the prefix, Gitea can not filter branches so
+// every branch is listed
+func (s *ReferenceService) List(ctx context.Context, opt *tp.ListRefOption) ([]*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("List Reference Opt: %+v", opt)
+
+	var refs []*tp.Reference
+	for page := 1; ; page++ {
+		var branches []*giteaBranch
+		path := fmt.Sprintf("%s/branches?page=%d&limit=%d", repoOpt.repoPath(), page, branchPageSize)
+		if _, err = s.client.Do(ctx, http.MethodGet, path, nil, &branches); err != nil {
+			logrus.Errorf("List Reference Error: %v", err)
+			return nil, err
+		}
+		for _, branch := range branches {
+			if strings.HasPrefix(branch.Name, opt.Prefix) {
+				refs = append(refs, newBranch(branch))
+			}
+		}
+		if len(branches) < branchPageSize {
+			return refs, nil
+		}
+	}
+}
+
+// Delete deletes the branch
+func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteRefOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Delete Reference Opt: %+v", opt)
+
+	_, err = s.client.Do(ctx, http.MethodDelete, repoOpt.repoPath()+"/branches/"+escapeBranch(opt.Ref), nil, nil)
+	if err != nil {
+		if isNotFound(err) {
+			return tp.NotFound
+		}
+		logrus.Errorf("Delete Reference Error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func newBranch(branch *giteaBranch) *tp.Reference {
+	return &tp.Reference{
+		Ref: "refs/heads/" + branch.Name,
+		SHA: branch.Commit.ID,
+	}
+}
@@ -0,0 +1,68 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestReferenceService_Get(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "abc"
+	service := NewReferenceService(setup(t, f))
+
+	ref, err := service.Get(context.Background(), &tp.GetRefOption{Repo: "kentio/norn", Ref: "refs/heads/release/1.0"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ref.Ref != "refs/heads/release/1.0" || ref.SHA != "abc" {
+		t.Fatalf("reference: %+v", ref)
+	}
+
+	_, err = service.Get(context.Background(), &tp.GetRefOption{Repo: "kentio/norn", Ref: "heads/missing"})
+	if err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestReferenceService_Create(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "abc"
+	service := NewReferenceService(setup(t, f))
+
+	ref, err := service.Create(context.Background(), &tp.CreateRefOption{Repo: "kentio/norn", Ref: "refs/heads/backport/1-release/1.0", SHA: "abc"})
+	if err != nil || ref.Ref != "refs/heads/backport/1-release/1.0" || ref.SHA != "abc" {
+		t.Fatalf("create: %v %+v", err, ref)
+	}
+	if f.branches["backport/1-release/1.0"] != "abc" {
+		t.Fatalf("branches: %v", f.branches)
+	}
+	_, err = service.Create(context.Background(), &tp.CreateRefOption{Repo: "kentio/norn", Ref: "release/1.0", SHA: "abc"})
+	if err != tp.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
+
+func TestReferenceService_ListDelete(t *testing.T) {
+	f := newFakeGitea()
+	f.branches["release/1.0"] = "abc"
+	for i := 0; i < branchPageSize; i++ {
+		f.branches[fmt.Sprintf("norn/tmp/20240102T030405Z-1a2b3c4d/pick-main-%09d", i)] = "def"
+	}
+	service := NewReferenceService(setup(t, f))
+
+	refs, err := service.List(context.Background(), &tp.ListRefOption{Repo: "kentio/norn", Prefix: tp.TempRefPrefix})
+	if err != nil || len(refs) != branchPageSize || refs[0].SHA != "def" {
+		t.Fatalf("err: %v refs: %d", err, len(refs))
+	}
+	if err = service.Delete(context.Background(), &tp.DeleteRefOption{Repo: "kentio/norn", Ref: refs[0].Ref}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := f.branches[branchName(refs[0].Ref)]; ok {
+		t.Fatalf("branch is not deleted")
+	}
+	if err = service.Delete(context.Background(), &tp.DeleteRefOption{Repo: "kentio/norn", Ref: refs[0].Ref}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
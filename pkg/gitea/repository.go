@@ -0,0 +1,105 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+)
+
+type Repository struct {
+	name                string
+	fullName            string
+	gitUrl              string
+	defaultBranch       string
+	allowSquashMerge    *bool
+	deleteBranchOnMerge *bool
+	allowRebaseMerge    *bool
+	private             *bool
+}
+
+type giteaRepository struct {
+	Name                string `json:"name"`
+	FullName            string `json:"full_name"`
+	CloneURL            string `json:"clone_url"`
+	DefaultBranch       string `json:"default_branch"`
+	AllowSquashMerge    *bool  `json:"allow_squash_merge"`
+	DeleteBranchOnMerge *bool  `json:"default_delete_branch_after_merge"`
+	AllowRebase         *bool  `json:"allow_rebase"`
+	Private             *bool  `json:"private"`
+}
+
+type RepositoryService struct {
+	client *Client
+}
+
+func NewRepositoryService(client *Client) *RepositoryService {
+	return &RepositoryService{
+		client: client,
+	}
+}
+
+func (r *RepositoryService) Get(ctx context.Context, opt *tp.GetRepositoryOption) (tp.Repository, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &giteaRepository{}
+	_, err = r.client.Do(ctx, http.MethodGet, repoOpt.repoPath(), nil, repo)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		return nil, err
+	}
+	return newRepository(repo), nil
+}
+
+func newRepository(repo *giteaRepository) *Repository {
+	return &Repository{
+		name:                repo.Name,
+		fullName:            repo.FullName,
+		gitUrl:              repo.CloneURL,
+		defaultBranch:       repo.DefaultBranch,
+		allowSquashMerge:    repo.AllowSquashMerge,
+		deleteBranchOnMerge: repo.DeleteBranchOnMerge,
+		allowRebaseMerge:    repo.AllowRebase,
+		private:             repo.Private,
+	}
+}
+
+func (r *Repository) Name() string {
+	return r.name
+}
+
+func (r *Repository) FullName() string {
+	return r.fullName
+}
+
+func (r *Repository) GitUrl() string {
+	return r.gitUrl
+}
+
+func (r *Repository) DefaultBranch() string {
+	return r.defaultBranch
+}
+
+func (r *Repository) AllowSquashMerge() *bool {
+	return r.allowSquashMerge
+}
+
+func (r *Repository) DeleteBranchOnMerge() *bool {
+	return r.deleteBranchOnMerge
+}
+
+func (r *Repository) AllowRebaseMerge() *bool {
+	return r.allowRebaseMerge
+}
+
+func (r *Repository) Private() *bool {
+	return r.private
+}
@@ -0,0 +1,17 @@
+package gitea
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestRepositoryService_Get(t *testing.T) {
+	repo, err := NewRepositoryService(setup(t, newFakeGitea())).Get(context.Background(), &tp.GetRepositoryOption{Repo: "kentio/norn"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if repo.FullName() != "kentio/norn" || repo.DefaultBranch() != "master" || !*repo.Private() {
+		t.Fatalf("repo: %+v", repo)
+	}
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	gh "github.com/google/go-github/v62/github"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"strconv"
+	"time"
+)
+
+const (
+	// appJWTLifetime GitHub rejects app JWTs that live longer than 10 minutes
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockDrift issue the JWT in the past to allow for clock drift
+	appJWTClockDrift = time.Minute
+	// installationTokenEarlyExpiry refresh the installation token before it expires
+	installationTokenEarlyExpiry = 5 * time.Minute
+)
+
+// ParsePrivateKey parse the PEM encoded private key of a GitHub App, both PKCS#1 and PKCS#8 are supported.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not a RSA key")
+	}
+	return rsaKey, nil
+}
+
+// appTokenSource returns JWTs signed with the private key of the app, it is used to authenticate as the app itself.
+type appTokenSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	now := s.now()
+	expiry := now.Add(appJWTLifetime)
+	token, err := signJWT(s.key, map[string]interface{}{
+		"iat": now.Add(-appJWTClockDrift).Unix(),
+		"exp": expiry.Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// signJWT sign the claims with RS256
+func signJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationTokenSource exchanges the app JWT for an installation access token.
+type installationTokenSource struct {
+	ctx            context.Context
+	installationID int64
+	appClient      *gh.Client
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	logrus.Debugf("Create installation token for installation %d", s.installationID)
+	token, _, err := s.appClient.Apps.CreateInstallationToken(s.ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create installation token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "token",
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
+
+// NewAppTokenSource returns a token source of installation tokens for the GitHub App,
+// the tokens are cached and refreshed before they expire.
+func NewAppTokenSource(ctx context.Context, opt *tp.CreateProviderOption) (oauth2.TokenSource, error) {
+	if opt.AppID == 0 || opt.AppInstallationID == 0 || len(opt.AppPrivateKey) == 0 {
+		return nil, fmt.Errorf("%w: app id, installation id and private key are required for GitHub App", tp.ErrInvalidOptions)
+	}
+	key, err := ParsePrivateKey(opt.AppPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	appTs := oauth2.ReuseTokenSourceWithExpiry(nil, &appTokenSource{appID: opt.AppID, key: key, now: time.Now}, appJWTClockDrift)
+	appClient, err := withBaseUrl(gh.NewClient(oauth2.NewClient(ctx, appTs)), opt)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &installationTokenSource{ctx: ctx, installationID: opt.AppInstallationID, appClient: appClient}
+	return oauth2.ReuseTokenSourceWithExpiry(nil, ts, installationTokenEarlyExpiry), nil
+}
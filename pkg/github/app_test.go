@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// verifyJWT check the signature of the JWT and returns the claims
+func verifyJWT(t *testing.T, key *rsa.PublicKey, token string) map[string]interface{} {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("invalid JWT: %s", token)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("invalid signature: %v", err)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	claims := map[string]interface{}{}
+	_ = json.Unmarshal(payload, &claims)
+	return claims
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, pkcs1 := newAppKey(t)
+	parsed, err := ParsePrivateKey(pkcs1)
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	parsed, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = ParsePrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestNewProvider_App(t *testing.T) {
+	key, keyPEM := newAppKey(t)
+	var issued int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		claims := verifyJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		assert.Equal(t, "7", claims["iss"])
+		n := atomic.AddInt32(&issued, 1)
+		// expire within the refresh window, so every request gets a new token
+		fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":"%s"}`, n, time.Now().Add(time.Minute).Format(time.RFC3339))
+	})
+	mux.HandleFunc("GET /api/v3/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":"r","full_name":"o/r","git_url":"git://o/r","default_branch":"%s"}`, r.Header.Get("Authorization"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	baseUrl := server.URL + "/"
+	provider, err := NewProvider(context.Background(), &tp.CreateProviderOption{
+		BaseUrl:           &baseUrl,
+		AppID:             7,
+		AppInstallationID: 42,
+		AppPrivateKey:     keyPEM,
+	})
+	assert.NoError(t, err)
+
+	repo, err := provider.Repository().Get(context.Background(), &tp.GetRepositoryOption{Repo: "o/r"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token ghs_1", repo.DefaultBranch())
+
+	repo, err = provider.Repository().Get(context.Background(), &tp.GetRepositoryOption{Repo: "o/r"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token ghs_2", repo.DefaultBranch())
+}
+
+func TestNewProvider_AppInvalid(t *testing.T) {
+	_, err := NewProvider(context.Background(), &tp.CreateProviderOption{AppID: 7})
+	assert.ErrorIs(t, err, tp.ErrInvalidOptions)
+
+	_, err = NewProvider(context.Background(), &tp.CreateProviderOption{AppID: 7, AppInstallationID: 42, AppPrivateKey: []byte("bad")})
+	assert.Error(t, err)
+}
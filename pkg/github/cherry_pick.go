@@ -9,11 +9,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
-	"time"
 )
 
 type PickService struct {
 	client *gh.Client
+	signer tp.Signer     // signs the pick and revert commits, unsigned if nil
+	runID  string        // names the temporary refs, see tp.TempRef
+	engine tp.PickEngine // tp.EngineMerge merges in process, the merges API otherwise
 }
 
 type RepoOption struct {
@@ -24,6 +26,7 @@ type RepoOption struct {
 func NewPickService(client *gh.Client) *PickService {
 	return &PickService{
 		client: client,
+		runID:  tp.NewRunID(),
 	}
 }
 
@@ -39,139 +42,261 @@ func parseRepo(repo string) (*RepoOption, error) {
 	}, nil
 }
 
-func (c *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) error {
+func (c *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	pc, err := c.prepare(ctx, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+	message := opt.Message
+	if message == "" {
+		message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", *pc.source.Message, pc.source.GetSHA()[:7])
+	}
+	apply := &applyOption{
+		TempRef:   tp.TempRef(c.runID, fmt.Sprintf("pick-%s-%s", opt.Branch, opt.SHA[:9])),
+		Base:      pc.source.Parents[pc.mainline].GetSHA(),
+		Head:      opt.SHA,
+		Message:   message,
+		Author:    pc.source.Author,
+		Committer: pickCommitter(pc.source, opt.Committer, opt.DateMode),
+	}
+	if c.engine == tp.EngineMerge {
+		return c.mergeApply(ctx, pc, apply)
+	}
+	return c.apply(ctx, pc, apply)
+}
+
+// pickCommitter returns the committer of a pick of the commit, the committer of the commit if
+// committer is nil. The date is set by the date mode.
+func pickCommitter(source *gh.Commit, committer *tp.Signature, mode tp.DateMode) *gh.CommitAuthor {
+	original := source.GetCommitter()
+	pick := &gh.CommitAuthor{Date: &gh.Timestamp{Time: mode.CommitterDate(original.GetDate().Time)}}
+	if original != nil {
+		pick.Name, pick.Email = original.Name, original.Email
+	}
+	if committer != nil {
+		pick.Name, pick.Email = gh.String(committer.Name), gh.String(committer.Email)
+	}
+	return pick
+}
+
+// Revert reverts the commit on the target branch. The change of a commit whose tree is the tree of the
+// parent and whose parent is the commit is merged, which undoes the change like git revert does.
+// The merge engine merges the change from the commit to its parent without the reverse commit.
+func (c *PickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	pc, err := c.prepare(ctx, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+	// the author and committer are the authenticated user
+	apply := &applyOption{
+		TempRef: tp.TempRef(c.runID, fmt.Sprintf("revert-%s-%s", opt.Branch, opt.SHA[:9])),
+		Base:    pc.source.GetSHA(),
+		Message: tp.RevertMessage(pc.source.GetSHA(), pc.source.GetMessage()),
+	}
+	if c.signer != nil {
+		// the identity of a signed commit is in its payload, the user of the token is unknown,
+		// so the revert is committed by the committer of the commit like the local provider
+		apply.Committer = pickCommitter(pc.source, nil, tp.DateNow)
+	}
+	if c.engine == tp.EngineMerge {
+		// the change from the commit to its parent is merged
+		apply.Head = pc.source.Parents[pc.mainline].GetSHA()
+		return c.mergeApply(ctx, pc, apply)
+	}
+
+	parent, _, err := c.client.Git.GetCommit(ctx, pc.repoOpt.Owner, pc.repoOpt.Repo, pc.source.Parents[pc.mainline].GetSHA())
+	if err != nil {
+		logrus.Errorf("Get parent of %s: %v", opt.SHA, err)
+		return nil, err
+	}
+	reverse, _, err := c.client.Git.CreateCommit(ctx, pc.repoOpt.Owner, pc.repoOpt.Repo, &gh.Commit{
+		Message: gh.String(fmt.Sprintf("Reverse of %s", pc.source.GetSHA())),
+		Tree:    &gh.Tree{SHA: parent.Tree.SHA},
+		Parents: []*gh.Commit{{SHA: pc.source.SHA}},
+	}, nil)
+	if err != nil {
+		logrus.Errorf("Failed to create reverse commit of %s: %v", opt.SHA, err)
+		return nil, err
+	}
+	apply.Head = reverse.GetSHA()
+	return c.apply(ctx, pc, apply)
+}
+
+// pickContext is what a pick or a revert is computed from
+type pickContext struct {
+	opt      *tp.PickOption
+	repoOpt  *RepoOption
+	target   *gh.Reference
+	latest   *gh.Commit // the head of the target
+	source   *gh.Commit
+	mainline int // index of the parent the source is picked against
+}
+
+type applyOption struct {
+	TempRef   string
+	Base      string // parent of the sibling commit, the base of the merge
+	Head      string // merged into the sibling commit
+	Message   string
+	Author    *gh.CommitAuthor
+	Committer *gh.CommitAuthor
+}
+
+// prepare reads the target branch and the source commit
+func (c *PickService) prepare(ctx context.Context, repo string, opt *tp.PickOption) (*pickContext, error) {
 	repoOpt, err := parseRepo(repo)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if repoOpt == nil || opt == nil {
-		return types.ErrInvalidOptions
+		return nil, types.ErrInvalidOptions
 	}
 	// get target ref details
 	targetRef, _, err := c.client.Git.GetRef(ctx, repoOpt.Owner, repoOpt.Repo, "refs/heads/"+opt.Branch)
 	if err != nil {
-		return tp.NotFound
+		return nil, tp.NotFound
 	}
 
 	// get target latest commit details
 	latestCommit, _, err := c.client.Git.GetCommit(ctx, repoOpt.Owner, repoOpt.Repo, targetRef.Object.GetSHA())
 	if err != nil {
 		logrus.Errorf("1 get target commit")
-		return err
+		return nil, err
 	}
 
 	// 需要 cherry-pick 的 commit
 	sourceCommit, _, err := c.client.Git.GetCommit(ctx, repoOpt.Owner, repoOpt.Repo, opt.SHA)
 	if err != nil {
 		logrus.Errorf("Error: %v", err)
-		return err
+		return nil, err
 	}
 
-	// create a temporary ref
-	tempRef := fmt.Sprintf("refs/heads/pick-%s-%s", opt.Branch, opt.SHA[:9])
+	// the parent the source is picked against, the sibling commit is based on it
+	mainline, err := tp.MainlineParent(len(sourceCommit.Parents), opt.Mainline)
+	if err != nil {
+		logrus.Warnf("Pick %s: %v", opt.SHA, err)
+		return nil, err
+	}
+	return &pickContext{opt: opt, repoOpt: repoOpt, target: targetRef, latest: latestCommit, source: sourceCommit, mainline: mainline}, nil
+}
+
+// apply merges the change from Base to Head onto the target in a temporary ref, then commits the
+// merged tree on top of the target and fast-forwards the target to it.
+func (c *PickService) apply(ctx context.Context, pc *pickContext, opt *applyOption) (*tp.PickResult, error) {
+	repoOpt, targetRef, latestCommit := pc.repoOpt, pc.target, pc.latest
 	// Delete the temporary ref
 	defer func() {
-		_, err := c.client.Git.DeleteRef(ctx, repoOpt.Owner, repoOpt.Repo, tempRef)
+		_, err := c.client.Git.DeleteRef(ctx, repoOpt.Owner, repoOpt.Repo, opt.TempRef)
 		if err != nil {
-			logrus.Errorf("Failed to delete temporary ref %s: %v", tempRef, err)
+			logrus.Errorf("Failed to delete temporary ref %s: %v", opt.TempRef, err)
 		}
 	}()
-	_, _, err = c.client.Git.CreateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
-		Ref: gh.String(tempRef),
+	_, _, err := c.client.Git.CreateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
+		Ref: gh.String(opt.TempRef),
 		Object: &gh.GitObject{
 			SHA: gh.String(targetRef.Object.GetSHA()),
 		},
 	})
 
 	if err != nil {
-		logrus.Errorf("Failed to create temporary ref %s: %v", tempRef, err)
-		return err
+		logrus.Errorf("Failed to create temporary ref %s: %v", opt.TempRef, err)
+		return nil, err
 	}
 
 	// 创建一个新的 sibling commit
 	siblingCommit, _, err := c.client.Git.CreateCommit(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Commit{
-		Author:    sourceCommit.Author,
-		Committer: sourceCommit.Committer,
-		Message:   gh.String(fmt.Sprintf("Sibling of %s", sourceCommit.GetSHA())),
+		Author:    pc.source.Author,
+		Committer: pc.source.Committer,
+		Message:   gh.String(fmt.Sprintf("Sibling of %s", pc.source.GetSHA())),
 		Tree:      &gh.Tree{SHA: latestCommit.Tree.SHA},
-		Parents:   []*gh.Commit{{SHA: sourceCommit.Parents[0].SHA}},
+		Parents:   []*gh.Commit{{SHA: gh.String(opt.Base)}},
 	}, nil)
 
 	if err != nil {
 		logrus.Errorf("Failed to create new commit: %v with temp ref", err)
-		return err
+		return nil, err
 	}
 
 	// update temp ref to sibling commit
 	_, _, err = c.client.Git.UpdateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
-		Ref: gh.String(tempRef),
+		Ref: gh.String(opt.TempRef),
 		Object: &gh.GitObject{
 			SHA: siblingCommit.SHA,
 		},
 	}, true)
 
 	if err != nil {
-		logrus.Errorf("Failed to update temp ref %s", tempRef)
-		return err
+		logrus.Errorf("Failed to update temp ref %s", opt.TempRef)
+		return nil, err
 	}
 
 	// merge pick commit to temp branch
 	mergeSha, err := c.Merge(ctx, &MergeOption{
 		Owner: repoOpt.Owner,
 		Repo:  repoOpt.Repo,
-		Base:  tempRef,
-		SHA:   opt.SHA,
+		Base:  opt.TempRef,
+		SHA:   opt.Head,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// update commit date
-	_committer := sourceCommit.Committer
-	// to time Timestamp
-	_committer.Date = &gh.Timestamp{
-		Time: time.Now(),
+	// the merge did not change the tree of the target, the pick would be an empty commit
+	if *mergeSha == latestCommit.Tree.GetSHA() {
+		logrus.Infof("Pick %s to %s is empty", pc.opt.SHA, pc.opt.Branch)
+		return nil, tp.ErrEmptyPick
+	}
+	if pc.opt.DryRun {
+		logrus.Infof("Dry run: pick %s to %s results in tree %s", pc.opt.SHA, pc.opt.Branch, *mergeSha)
+		return &tp.PickResult{Tree: *mergeSha}, nil
 	}
 
-	// create the final pick commit
-	message := fmt.Sprintf("%s\n\n(cherry picked from commit %s)", *sourceCommit.Message, sourceCommit.GetSHA()[:7])
-	newCommit, _, err := c.client.Git.CreateCommit(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Commit{
-		Author:    sourceCommit.Author,
-		Committer: _committer,
-		Message:   gh.String(message),
-		Tree:      &gh.Tree{SHA: mergeSha, Truncated: gh.Bool(false)},
-		Parents:   []*gh.Commit{{SHA: latestCommit.SHA}},
-	}, nil)
-
+	newCommit, err := c.createPickCommit(ctx, pc, opt, *mergeSha)
 	if err != nil {
-		logrus.Errorf("creating commit with different tree")
-		return err
+		return nil, err
 	}
 
 	// update the ref to the new commit with temp ref
 	_, _, err = c.client.Git.UpdateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
-		Ref: gh.String(tempRef),
+		Ref: gh.String(opt.TempRef),
 		Object: &gh.GitObject{
 			SHA: newCommit.SHA,
 		},
 	}, true)
 	if err != nil {
 		logrus.Error("update temp branch error")
-		return err
+		return nil, err
 	}
 
-	// update target branch
-	_, _, err = c.client.Git.UpdateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
-		Ref: targetRef.Ref,
-		Object: &gh.GitObject{
-			SHA: newCommit.SHA,
-		},
-	}, true)
+	// update target branch, the pick commit is on top of the target head, so a commit landing
+	// on the target in the meantime makes the update not a fast forward instead of being lost
+	_, err = fastForward(ctx, c.client, repoOpt, targetRef.GetRef(), newCommit.GetSHA())
 	if err != nil {
-		logrus.Errorf("update target branch error %s", *targetRef.Ref)
-		return err
+		logrus.Errorf("update target branch error %s: %v", *targetRef.Ref, err)
+		return nil, err
 	}
 
-	return nil
+	return &tp.PickResult{SHA: newCommit.GetSHA(), Tree: *mergeSha}, nil
+}
+
+// createPickCommit creates the pick commit of the tree on top of the target head, only it is
+// signed, the other commits of a pick are not on a branch
+func (c *PickService) createPickCommit(ctx context.Context, pc *pickContext, opt *applyOption, tree string) (*gh.Commit, error) {
+	pickCommit := &gh.Commit{
+		Author:    opt.Author,
+		Committer: opt.Committer,
+		Message:   gh.String(opt.Message),
+		Tree:      &gh.Tree{SHA: gh.String(tree), Truncated: gh.Bool(false)},
+		Parents:   []*gh.Commit{{SHA: pc.latest.SHA}},
+	}
+	commitOpt, err := createCommitOptions(pickCommit, c.signer)
+	if err != nil {
+		return nil, err
+	}
+	newCommit, _, err := c.client.Git.CreateCommit(ctx, pc.repoOpt.Owner, pc.repoOpt.Repo, pickCommit, commitOpt)
+	if err != nil {
+		logrus.Errorf("creating commit with different tree")
+		return nil, err
+	}
+	return newCommit, nil
 }
 
 type MergeOption struct {
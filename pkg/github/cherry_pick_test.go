@@ -2,8 +2,12 @@ package github
 
 import (
 	"context"
+	"errors"
 	tp "github.com/kentio/norn/pkg/types"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPickClient_Pick(t *testing.T) {
@@ -13,7 +17,7 @@ func TestPickClient_Pick(t *testing.T) {
 	token := ""
 	client := NewGithubClient(ctx, token)
 	pickServuce := NewPickService(client)
-	err := pickServuce.Pick(ctx, "",
+	_, err := pickServuce.Pick(ctx, "",
 		&tp.PickOption{SHA: SHA, Branch: Branch})
 	if err != nil {
 		t.Errorf("err: %v", err)
@@ -22,3 +26,232 @@ func TestPickClient_Pick(t *testing.T) {
 		t.Logf("err: %v", err)
 	}
 }
+
+func TestPickService_PickMainline(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	p1 := f.commit("t2", "main", base)
+	p2 := f.commit("t3", "feature", base)
+	merge := f.commit("t4", "Merge feature", p1, p2)
+	f.refs["refs/heads/release"] = target
+	ctx := context.Background()
+	service := NewPickService(client)
+
+	// a merge commit is refused without mainline
+	_, err := service.Pick(ctx, "o/r", &tp.PickOption{SHA: merge, Branch: "release"})
+	if !errors.Is(err, tp.ErrMainlineRequired) {
+		t.Fatalf("err = %v, want mainline required", err)
+	}
+	if len(f.created) != 0 || f.refs["refs/heads/release"] != target {
+		t.Fatalf("refused pick changed the repo")
+	}
+
+	if _, err = service.Pick(ctx, "o/r", &tp.PickOption{SHA: merge, Branch: "release", Mainline: 2}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the sibling commit has the target tree on top of the mainline parent
+	sibling := f.created[0]
+	if sibling.Tree != "t1" || len(sibling.Parents) != 1 || sibling.Parents[0] != p2 {
+		t.Fatalf("sibling: %+v", sibling)
+	}
+	picked := f.commits[f.refs["refs/heads/release"]]
+	if len(picked.Parents) != 1 || picked.Parents[0] != target || !strings.HasPrefix(picked.Message, "Merge feature") {
+		t.Fatalf("picked: %+v", picked)
+	}
+	if len(f.refs) != 1 {
+		t.Fatalf("temporary ref is not deleted: %v", f.refs)
+	}
+}
+
+func TestPickService_PickDryRun(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	source := f.commit("t2", "fix", base)
+	f.refs["refs/heads/release"] = target
+	service := NewPickService(client)
+
+	result, err := service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != "" || result.Tree != "merged-1" {
+		t.Fatalf("result: %+v", result)
+	}
+	// the merge is done in the temporary ref, which is deleted
+	if f.refs["refs/heads/release"] != target || len(f.refs) != 1 {
+		t.Fatalf("refs: %v", f.refs)
+	}
+
+	f.conflict = true
+	if _, err = service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release", DryRun: true}); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestPickService_PickEmpty(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	source := f.commit("t2", "fix", base)
+	f.refs["refs/heads/release"] = target
+	// the change of the source is already on the target
+	f.merged = "t1"
+
+	_, err := NewPickService(client).Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"})
+	if err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+	// only the sibling commit is created, the target is not moved
+	if len(f.created) != 1 || f.refs["refs/heads/release"] != target || len(f.refs) != 1 {
+		t.Fatalf("created: %d refs: %v", len(f.created), f.refs)
+	}
+}
+
+func TestPickService_PickCommitter(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	source := f.commit("t2", "fix", base)
+	f.refs["refs/heads/release"] = target
+	service := NewPickService(client)
+
+	_, err := service.Pick(context.Background(), "o/r", &tp.PickOption{
+		SHA:       source,
+		Branch:    "release",
+		Committer: &tp.Signature{Name: "bot", Email: "bot@example.com"},
+		DateMode:  tp.DateOriginal,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	picked := f.commits[f.refs["refs/heads/release"]]
+	if picked.Committer["name"] != "bot" || picked.Committer["email"] != "bot@example.com" {
+		t.Fatalf("committer: %v", picked.Committer)
+	}
+	if date, _ := time.Parse(time.RFC3339, picked.Committer["date"]); !date.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("the original date is not kept: %v", picked.Committer)
+	}
+
+	// the committer of the source is kept with the current date by default
+	f.refs["refs/heads/release"] = target
+	if _, err = service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	picked = f.commits[f.refs["refs/heads/release"]]
+	if date, _ := time.Parse(time.RFC3339, picked.Committer["date"]); picked.Committer["name"] != "norn" || time.Since(date) > time.Hour {
+		t.Fatalf("committer: %v", picked.Committer)
+	}
+}
+
+// payloadSigner "signs" by writing the payload, the tests check what is signed
+type payloadSigner struct{}
+
+func (payloadSigner) Sign(w io.Writer, r io.Reader) error {
+	_, err := io.Copy(w, r)
+	return err
+}
+
+func TestPickService_PickSigned(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	source := f.commit("t2", "fix", base)
+	f.refs["refs/heads/release"] = target
+	provider := NewProviderWithClient(client)
+	provider.SetSigner(payloadSigner{})
+
+	_, err := provider.Pick().Pick(context.Background(), "o/r", &tp.PickOption{
+		SHA:       source,
+		Branch:    "release",
+		Committer: &tp.Signature{Name: "bot", Email: "bot@example.com"},
+		DateMode:  tp.DateOriginal,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// only the pick commit is signed
+	if f.created[0].Signature != "" {
+		t.Fatalf("sibling is signed: %+v", f.created[0])
+	}
+	picked := f.commits[f.refs["refs/heads/release"]]
+	want := "tree merged-1\nparent " + target + "\n" +
+		"author norn <norn@example.com> 1704164645 +0000\n" +
+		"committer bot <bot@example.com> 1704164645 +0000\n\n" +
+		"fix\n\n(cherry picked from commit " + source[:7] + ")"
+	if picked.Signature != want {
+		t.Fatalf("signature = %q, want %q", picked.Signature, want)
+	}
+
+	// the revert is signed as the committer of the reverted commit
+	result, err := provider.Pick().Revert(context.Background(), "o/r", &tp.PickOption{SHA: picked.SHA, Branch: "release"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	reverted := f.commits[result.SHA]
+	if reverted.Committer["name"] != "bot" || !strings.Contains(reverted.Signature, "\nauthor bot <bot@example.com> ") {
+		t.Fatalf("reverted: %+v", reverted)
+	}
+}
+
+func TestPickService_Revert(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	picked := f.commit("t1", "fix: bug\n\n(cherry picked from commit 0123456)", base)
+	target := f.commit("t2", "release", picked)
+	f.refs["refs/heads/release"] = target
+
+	result, err := NewPickService(client).Revert(context.Background(), "o/r", &tp.PickOption{SHA: picked, Branch: "release"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the reverse commit has the tree of the parent on top of the reverted commit
+	reverse, sibling := f.created[0], f.created[1]
+	if reverse.Tree != "t0" || len(reverse.Parents) != 1 || reverse.Parents[0] != picked {
+		t.Fatalf("reverse: %+v", reverse)
+	}
+	if sibling.Tree != "t2" || sibling.Parents[0] != picked || f.merges[0]["head"] != reverse.SHA {
+		t.Fatalf("sibling: %+v merges: %v", sibling, f.merges)
+	}
+	reverted := f.commits[f.refs["refs/heads/release"]]
+	if result.SHA != reverted.SHA || reverted.Parents[0] != target || reverted.Message != tp.RevertMessage(picked, "fix: bug") {
+		t.Fatalf("result: %+v reverted: %+v", result, reverted)
+	}
+	if len(f.refs) != 1 {
+		t.Fatalf("temporary ref is not deleted: %v", f.refs)
+	}
+}
+
+func TestPickService_PickStaleRef(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	target := f.commit("t1", "release", base)
+	source := f.commit("t2", "fix", base)
+	f.refs["refs/heads/release"] = target
+	// a commit lands on the target while picking
+	landed := f.commit("t3", "landed", target)
+	f.onMerge = func() { f.refs["refs/heads/release"] = landed }
+
+	_, err := NewPickService(client).Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"})
+	if err != tp.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	if f.refs["refs/heads/release"] != landed {
+		t.Fatalf("the landed commit is lost: %v", f.refs)
+	}
+}
@@ -13,7 +13,10 @@ func TestPullRequestService_FindComment(t *testing.T) {
 	token := ""
 	repo := "kentio/test_cherry_pick"
 	mergeId := "53"
-	client := NewProvider(ctx, &tp.CreateProviderOption{Token: token})
+	client, err := NewProvider(ctx, &tp.CreateProviderOption{Token: token})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
 	comments, err := client.Comment().Find(ctx, &tp.FindCommentOption{Repo: repo, MergeRequestID: mergeId})
 	if err != nil {
@@ -2,18 +2,22 @@ package github
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	gh "github.com/google/go-github/v62/github"
+	"github.com/kentio/norn/internal/git"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
-	"os"
+	"net/http"
+	"time"
 )
 
 type Commit struct {
 	sha     string
 	tree    *Tree
 	message string
+	author  tp.Signature
+	parents []string
 }
 
 type Tree struct {
@@ -34,11 +38,14 @@ type TreeEntry struct {
 
 type CommitService struct {
 	client *gh.Client
+	signer tp.Signer    // signs the created commits, unsigned if nil
+	picks  *PickService // merges the trees to check conflicts WithAPI
 }
 
 func NewCommitService(client *gh.Client) *CommitService {
 	return &CommitService{
 		client: client,
+		picks:  NewPickService(client),
 	}
 }
 
@@ -61,14 +68,70 @@ func (s *CommitService) Get(ctx context.Context, opt *tp.GetCommitOption) (tp.Co
 	return newCommit(commit), nil
 }
 
-// CheckConflict Check Conflict
-func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflictOption) error {
+// List returns the recent commits of the branch, newest first
+func (s *CommitService) List(ctx context.Context, opt *tp.ListCommitOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("List Commits Opt: %+v", *opt)
+	listOpt := &gh.CommitsListOptions{SHA: opt.Branch, ListOptions: gh.ListOptions{PerPage: 100}}
+	var result []tp.Commit
+	for len(result) < opt.Limit {
+		commits, response, err := s.client.Repositories.ListCommits(ctx, repoOpt.Owner, repoOpt.Repo, listOpt)
+		if err != nil {
+			logrus.Debugf("List Commits Error: %+v", err)
+			return nil, err
+		}
+		for _, c := range commits {
+			result = append(result, newCommit(c))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		listOpt.Page = response.NextPage
+	}
+	if len(result) > opt.Limit {
+		result = result[:opt.Limit]
+	}
+	return result, nil
+}
+
+// Diff returns the diff of the commit
+func (s *CommitService) Diff(ctx context.Context, opt *tp.GetCommitOption) (string, error) {
+	if opt == nil {
+		return "", tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return "", err
+	}
+	diff, _, err := s.client.Repositories.GetCommitRaw(ctx, repoOpt.Owner, repoOpt.Repo, opt.SHA, gh.RawOptions{Type: gh.Diff})
+	if err != nil {
+		logrus.Debugf("Get Commit Diff Error: %+v", err)
+		return "", err
+	}
+	return diff, nil
+}
 
-	if opts.Mode != tp.WithCommand {
-		return errors.New("not support check conflict with API")
+// CheckConflict checks if the commit can be picked onto the target. WithAPI merges the trees read by
+// the API like the merge engine and returns a *tp.ConflictError naming the conflicting files, nothing
+// is written. WithCommand applies the patch of the pull request in the clone at RepoPath.
+func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflictOption) error {
+	if opts == nil {
+		return tp.ErrInvalidOptions
+	}
+	if opts.Mode == tp.WithAPI {
+		return s.picks.checkConflict(ctx, opts)
 	}
 
 	repoOpt, err := parseRepo(opts.Repo)
+	if err != nil {
+		return err
+	}
 	// create patch
 	content, err := CreatePatchWithClient(ctx, &CreatePatchOption{
 		Client: s.client,
@@ -81,46 +144,33 @@ func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflic
 		return err
 	}
 
-	// write patch to temp file
-	patch, err := os.CreateTemp(os.TempDir(), "patch")
-	if err != nil {
+	// check the patch on the target branch of the clone, the default path is the current directory
+	clone := git.NewGit(opts.RepoPath)
+	if err = clone.CheckoutRemote(ctx, opts.Remote, opts.Target); err != nil {
 		return err
 	}
-	_, err = patch.Write([]byte(content))
-	if err != nil {
-		return err
-	}
-	// remove patch file
-	defer func() {
-		err := os.Remove(patch.Name())
-		if err != nil {
-			logrus.Warnf("Remove Patch File Error: %v", err)
-		}
-		err = patch.Close()
-		if err != nil {
-			logrus.Warnf("Close Patch File Error: %v", err)
-		}
-	}()
+	return clone.CheckPatch(ctx, content)
+}
 
-	// checkout target branch
-	err = Checkout(&CheckoutOption{
-		Branch:   opts.Target,
-		RepoPath: opts.RepoPath, // default path is current directory
-	})
+// IsAncestor compares the commit with the branch, the branch contains it if it is ahead or identical
+func (s *CommitService) IsAncestor(ctx context.Context, opt *tp.AncestorOption) (bool, error) {
+	if opt == nil {
+		return false, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
 	if err != nil {
-		return err
+		return false, err
 	}
-
-	// check patch
-	err = ApplyPatch(&ApplyPatchOption{
-		Patch:    patch.Name(),
-		RepoPath: opts.RepoPath,
-		Check:    true,
-	})
+	comparison, response, err := s.client.Repositories.CompareCommits(ctx, repoOpt.Owner, repoOpt.Repo, opt.SHA, opt.Branch, &gh.ListOptions{PerPage: 1})
 	if err != nil {
-		return err
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return false, tp.NotFound
+		}
+		logrus.Errorf("Compare %s with %s: %v", opt.SHA, opt.Branch, err)
+		return false, err
 	}
-	return nil
+	status := comparison.GetStatus()
+	return status == "ahead" || status == "identical", nil
 }
 
 // Create Commit creates a new commit.
@@ -141,7 +191,7 @@ func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption)
 		})
 	}
 
-	commit, _, err := s.client.Git.CreateCommit(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Commit{
+	newCommit := &gh.Commit{
 		Message: gh.String(opt.PickMessage),
 		Parents: parents,
 		Tree: &gh.Tree{
@@ -149,7 +199,24 @@ func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption)
 			Entries:   nil,
 			Truncated: gh.Bool(false),
 		},
-	}, nil)
+	}
+	if opt.SHA != "" {
+		// keep the author of the picked commit, the committer is set by the options
+		source, _, err := s.client.Git.GetCommit(ctx, repoOpt.Owner, repoOpt.Repo, opt.SHA)
+		if err != nil {
+			logrus.Errorf("Get Commit Error: %v", err)
+			return nil, err
+		}
+		newCommit.Author, newCommit.Committer = source.Author, pickCommitter(source, opt.Committer, opt.DateMode)
+	} else if opt.Committer != nil {
+		newCommit.Committer = &gh.CommitAuthor{Name: gh.String(opt.Committer.Name), Email: gh.String(opt.Committer.Email)}
+	}
+
+	commitOpt, err := createCommitOptions(newCommit, s.signer)
+	if err != nil {
+		return nil, err
+	}
+	commit, _, err := s.client.Git.CreateCommit(ctx, repoOpt.Owner, repoOpt.Repo, newCommit, commitOpt)
 	if err != nil {
 		logrus.Errorf("Create Commit Error: %v", err)
 		return nil, err
@@ -158,10 +225,41 @@ func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption)
 	return newGithubCommit(commit), nil
 }
 
+// createCommitOptions returns the options signing the commit by the signer, nil if unsigned.
+// The signed payload needs the author and the dates, a commit without author is authored by
+// its committer and the missing dates are now.
+func createCommitOptions(commit *gh.Commit, signer tp.Signer) (*gh.CreateCommitOptions, error) {
+	if signer == nil {
+		return nil, nil
+	}
+	if commit.Author == nil {
+		if commit.Committer == nil {
+			return nil, fmt.Errorf("%w: a signed commit requires the author or the committer", tp.ErrInvalidOptions)
+		}
+		commit.Author = commit.Committer
+	}
+	now := &gh.Timestamp{Time: time.Now()}
+	// copied, the author may be the author of the picked commit
+	author := *commit.Author
+	if author.Date == nil {
+		author.Date = now
+	}
+	commit.Author = &author
+	if commit.Committer != nil && commit.Committer.Date == nil {
+		committer := *commit.Committer
+		committer.Date = now
+		commit.Committer = &committer
+	}
+	return &gh.CreateCommitOptions{Signer: signer}, nil
+}
+
 func newGithubCommit(c *gh.Commit) *Commit {
 	return &Commit{
 		sha:     *c.SHA,
 		message: *c.Message,
+		parents: lo.Map(c.Parents, func(p *gh.Commit, i int) string {
+			return p.GetSHA()
+		}),
 	}
 }
 
@@ -178,6 +276,9 @@ func newCommit(commit *gh.RepositoryCommit) *Commit {
 	if commit.Commit.Tree.Truncated != nil {
 		truncated = *commit.Commit.Tree.Truncated
 	}
+	parents := lo.Map(commit.Parents, func(p *gh.Commit, i int) string {
+		return p.GetSHA()
+	})
 
 	return &Commit{
 		sha: *commit.SHA,
@@ -187,6 +288,8 @@ func newCommit(commit *gh.RepositoryCommit) *Commit {
 			truncated: truncated,
 		},
 		message: *commit.Commit.Message,
+		author:  tp.Signature{Name: commit.Commit.GetAuthor().GetName(), Email: commit.Commit.GetAuthor().GetEmail()},
+		parents: parents,
 	}
 }
 
@@ -232,6 +335,15 @@ func (c *Commit) Message() string {
 	return c.message
 }
 
+func (c *Commit) Author() tp.Signature {
+	return c.author
+}
+
+// Parents returns the shas of the parents, the first parent first
+func (c *Commit) Parents() []string {
+	return c.parents
+}
+
 // SHA Tree returns the tree for the given path.
 func (t *Tree) SHA() string {
 	return t.sha
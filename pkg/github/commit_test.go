@@ -3,8 +3,12 @@ package github
 import (
 	"context"
 	"github.com/google/go-github/v62/github"
+	"github.com/kentio/norn/internal/git"
 	"github.com/kentio/norn/pkg/types"
 	"golang.org/x/oauth2"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -35,3 +39,77 @@ func TestCommitService_Get(t *testing.T) {
 	}
 	t.Logf("commit: %+v", commit)
 }
+
+func TestCommitService_IsAncestor(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	fix := f.commit("t1", "fix", base)
+	f.refs["refs/heads/main"] = fix
+	f.refs["refs/heads/release"] = f.commit("t2", "release", base)
+	service := NewCommitService(client)
+
+	for branch, want := range map[string]bool{"main": true, "release": false} {
+		got, err := service.IsAncestor(context.Background(), &types.AncestorOption{Repo: "o/r", SHA: fix, Branch: branch})
+		if err != nil || got != want {
+			t.Errorf("IsAncestor(%s) = %v, %v, want %v", branch, got, err, want)
+		}
+	}
+	if _, err := service.IsAncestor(context.Background(), &types.AncestorOption{Repo: "o/r", SHA: fix, Branch: "missing"}); err != types.NotFound {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestCommitService_CheckConflictInvalidRepo(t *testing.T) {
+	_, client := setup(t)
+	err := NewCommitService(client).CheckConflict(context.Background(), &types.CheckConflictOption{Repo: "norn", Commit: "abc", Target: "main", Mode: types.WithCommand})
+	if err == nil {
+		t.Fatalf("invalid repo is checked")
+	}
+}
+
+func TestCommitService_CheckConflictWithCommand(t *testing.T) {
+	ctx := context.Background()
+	env := []string{"GIT_AUTHOR_NAME=norn", "GIT_AUTHOR_EMAIL=norn@example.com", "GIT_COMMITTER_NAME=norn", "GIT_COMMITTER_EMAIL=norn@example.com"}
+	run := func(g *git.Git, args ...string) string {
+		out, err := g.RunIn(ctx, g.Path, env, args...)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return out
+	}
+	// the origin has a.txt on main, the patch of the pull request changes it
+	origin := git.NewGit(t.TempDir())
+	run(origin, "init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(origin.Path, "a.txt"), []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	run(origin, "add", "a.txt")
+	run(origin, "commit", "-q", "-m", "init")
+	run(origin, "checkout", "-q", "-b", "fix")
+	if err := os.WriteFile(filepath.Join(origin.Path, "a.txt"), []byte("a\nfix\nc\n"), 0o644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	run(origin, "commit", "-q", "-am", "fix: a")
+	patch := run(origin, "format-patch", "-1", "--stdout", "HEAD") + "\n"
+	run(origin, "checkout", "-q", "main")
+	// the clone has the origin as upstream only
+	clone := git.NewGit(filepath.Join(t.TempDir(), "clone"))
+	run(git.NewGit("."), "clone", "-q", "-o", "upstream", origin.Path, clone.Path)
+
+	mux, client := setup(t)
+	mux.HandleFunc("GET /repos/o/r/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(patch))
+	})
+	service := NewCommitService(client)
+	opt := &types.CheckConflictOption{Repo: "o/r", Commit: "abc", Target: "main", Pr: 1, Mode: types.WithCommand, RepoPath: clone.Path, Remote: "upstream"}
+	if err := service.CheckConflict(ctx, opt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the clone has no origin
+	opt.Remote = ""
+	if err := service.CheckConflict(ctx, opt); err == nil {
+		t.Fatalf("main of origin is checked")
+	}
+}
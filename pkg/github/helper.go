@@ -2,9 +2,12 @@ package github
 
 import (
 	"context"
+	"fmt"
 	gh "github.com/google/go-github/v62/github"
 	tp "github.com/kentio/norn/pkg/types"
 	"golang.org/x/oauth2"
+	"net/url"
+	"strings"
 )
 
 func NewGithubClient(ctx context.Context, token string) *gh.Client {
@@ -15,10 +18,70 @@ func NewGithubClient(ctx context.Context, token string) *gh.Client {
 	return gh.NewClient(tc)
 }
 
-func NewGitHubWithBaseUrl(ctx context.Context, opt *tp.CreateProviderOption) *gh.Client {
+func NewGitHubWithBaseUrl(ctx context.Context, opt *tp.CreateProviderOption) (*gh.Client, error) {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: opt.Token})
 	tc := oauth2.NewClient(ctx, ts)
-	client, _ := gh.NewClient(tc).WithEnterpriseURLs(*opt.BaseUrl, *opt.UploadUrl)
 
-	return client
+	return withBaseUrl(gh.NewClient(tc), opt)
+}
+
+// NewGithubClientWithOption creates a client authenticated as a GitHub App when the app is set, otherwise with the token.
+func NewGithubClientWithOption(ctx context.Context, opt *tp.CreateProviderOption) (*gh.Client, error) {
+	if opt.AppID == 0 {
+		if opt.BaseUrl != nil {
+			return NewGitHubWithBaseUrl(ctx, opt)
+		}
+		return NewGithubClient(ctx, opt.Token), nil
+	}
+
+	ts, err := NewAppTokenSource(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	return withBaseUrl(gh.NewClient(oauth2.NewClient(ctx, ts)), opt)
+}
+
+// withBaseUrl points the client to GitHub Enterprise when the base url is set
+func withBaseUrl(client *gh.Client, opt *tp.CreateProviderOption) (*gh.Client, error) {
+	if opt.BaseUrl == nil {
+		return client, nil
+	}
+	baseUrl, uploadUrl, err := EnterpriseURLs(*opt.BaseUrl, opt.UploadUrl)
+	if err != nil {
+		return nil, err
+	}
+	return client.WithEnterpriseURLs(baseUrl, uploadUrl)
+}
+
+// EnterpriseURLs validates the GitHub Enterprise Server urls and derives the upload url when it is omitted,
+// such as https://ghe.example.com/api/v3/ -> https://ghe.example.com/api/uploads/.
+func EnterpriseURLs(baseUrl string, uploadUrl *string) (string, string, error) {
+	base, err := validateUrl("base url", baseUrl)
+	if err != nil {
+		return "", "", err
+	}
+	if uploadUrl != nil && *uploadUrl != "" {
+		upload, err := validateUrl("upload url", *uploadUrl)
+		if err != nil {
+			return "", "", err
+		}
+		return base.String(), upload.String(), nil
+	}
+
+	upload := *base
+	root := strings.TrimSuffix(strings.TrimSuffix(base.Path, "/"), "/api/v3")
+	upload.Path = root + "/api/uploads/"
+	return base.String(), upload.String(), nil
+}
+
+// validateUrl check the url is an absolute http(s) url
+func validateUrl(name, rawUrl string) (*url.URL, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s %q: %v", tp.ErrInvalidOptions, name, rawUrl, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid %s %q, expected http(s)://host[/path]", tp.ErrInvalidOptions, name, rawUrl)
+	}
+	return u, nil
 }
@@ -1,6 +1,33 @@
 package github
 
-import "testing"
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	gh "github.com/google/go-github/v62/github"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// setup starts a stand-in of the GitHub API and returns a client connected to it
+func setup(t *testing.T) (*http.ServeMux, *gh.Client) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := gh.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return mux, client
+}
 
 func TestNewGithubClient(t *testing.T) {
 	token := ""
@@ -9,3 +36,413 @@ func TestNewGithubClient(t *testing.T) {
 
 	t.Logf("client: %v", client)
 }
+
+func TestEnterpriseURLs(t *testing.T) {
+	upload := "https://uploads.example.com/"
+	tests := []struct {
+		name       string
+		baseUrl    string
+		uploadUrl  *string
+		wantBase   string
+		wantUpload string
+		wantErr    bool
+	}{
+		{name: "api path", baseUrl: "https://ghe.example.com/api/v3/", wantBase: "https://ghe.example.com/api/v3/", wantUpload: "https://ghe.example.com/api/uploads/"},
+		{name: "api path without slash", baseUrl: "https://ghe.example.com/api/v3", wantBase: "https://ghe.example.com/api/v3", wantUpload: "https://ghe.example.com/api/uploads/"},
+		{name: "host", baseUrl: "https://ghe.example.com", wantBase: "https://ghe.example.com", wantUpload: "https://ghe.example.com/api/uploads/"},
+		{name: "explicit upload", baseUrl: "https://ghe.example.com/api/v3/", uploadUrl: &upload, wantBase: "https://ghe.example.com/api/v3/", wantUpload: upload},
+		{name: "no scheme", baseUrl: "ghe.example.com", wantErr: true},
+		{name: "empty upload", baseUrl: "https://ghe.example.com", uploadUrl: new(string), wantBase: "https://ghe.example.com", wantUpload: "https://ghe.example.com/api/uploads/"},
+		{name: "ftp", baseUrl: "ftp://ghe.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, upload, err := EnterpriseURLs(tt.baseUrl, tt.uploadUrl)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tp.ErrInvalidOptions)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBase, base)
+			assert.Equal(t, tt.wantUpload, upload)
+		})
+	}
+}
+
+// fakeGitHub is a stand-in of the git data and merge API used by PickService
+type fakeGitHub struct {
+	mu       sync.Mutex
+	refs     map[string]string // full ref -> sha
+	commits  map[string]*fakeCommit
+	conflict bool   // merges conflict
+	merged   string // tree of the merges, a new tree for each merge if empty
+	created  []*fakeCommit
+	merges   []map[string]string
+	seq      int
+	onMerge  func()                          // called with the lock held on merge, to update refs while a pick is in progress
+	trees    map[string]map[string]fakeEntry // tree sha -> path -> file, for the merge engine
+	blobs    map[string]string
+	writes   []string // the trees and blobs posted
+}
+
+type fakeEntry struct {
+	Mode string
+	SHA  string
+}
+
+type fakeCommit struct {
+	SHA     string   `json:"sha"`
+	Tree    string   `json:"tree"`
+	Parents []string `json:"parents"`
+	Message string   `json:"message"`
+	// Committer is the committer posted with the commit, nil for the default
+	Committer map[string]string `json:"committer"`
+	Signature string            `json:"signature"`
+}
+
+func newFakeGitHub() *fakeGitHub {
+	return &fakeGitHub{
+		refs:    map[string]string{},
+		commits: map[string]*fakeCommit{},
+		trees:   map[string]map[string]fakeEntry{},
+		blobs:   map[string]string{},
+	}
+}
+
+// tree adds a tree of regular files by path and returns its sha
+func (f *fakeGitHub) tree(files map[string]string) string {
+	f.seq++
+	sha := fmt.Sprintf("tree-%d", f.seq)
+	f.trees[sha] = map[string]fakeEntry{}
+	for path, content := range files {
+		f.blobs[blobSHA(content)] = content
+		f.trees[sha][path] = fakeEntry{Mode: "100644", SHA: blobSHA(content)}
+	}
+	return sha
+}
+
+// files returns the contents of the tree by path
+func (f *fakeGitHub) files(tree string) map[string]string {
+	files := map[string]string{}
+	for path, entry := range f.trees[tree] {
+		files[path] = f.blobs[entry.SHA]
+	}
+	return files
+}
+
+// commit adds a commit and returns its sha
+func (f *fakeGitHub) commit(tree, message string, parents ...string) string {
+	f.seq++
+	sha := fmt.Sprintf("%040d", f.seq)
+	f.commits[sha] = &fakeCommit{SHA: sha, Tree: tree, Parents: parents, Message: message}
+	return sha
+}
+
+// isAncestor check if ancestor is reachable from sha
+func (f *fakeGitHub) isAncestor(ancestor, sha string) bool {
+	if ancestor == sha {
+		return true
+	}
+	c, ok := f.commits[sha]
+	if !ok {
+		return false
+	}
+	for _, parent := range c.Parents {
+		if f.isAncestor(ancestor, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// serve registers the handlers of the repo o/r
+func (f *fakeGitHub) serve(mux *http.ServeMux) {
+	const prefix = "/repos/o/r"
+	mux.HandleFunc("GET "+prefix+"/git/ref/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		ref := "refs/" + r.PathValue("ref")
+		sha, ok := f.refs[ref]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ref": ref, "object": map[string]string{"sha": sha}})
+	})
+	mux.HandleFunc("POST "+prefix+"/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := f.refs[body["ref"]]; ok {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference already exists"})
+			return
+		}
+		f.refs[body["ref"]] = body["sha"]
+		writeJSON(w, http.StatusCreated, map[string]any{"ref": body["ref"], "object": map[string]string{"sha": body["sha"]}})
+	})
+	mux.HandleFunc("PATCH "+prefix+"/git/refs/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		ref := "refs/" + r.PathValue("ref")
+		if _, ok := f.refs[ref]; !ok {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference does not exist"})
+			return
+		}
+		if force, _ := body["force"].(bool); !force && !f.isAncestor(f.refs[ref], body["sha"].(string)) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"})
+			return
+		}
+		f.refs[ref] = body["sha"].(string)
+		writeJSON(w, http.StatusOK, map[string]any{"ref": ref, "object": map[string]any{"sha": body["sha"]}})
+	})
+	mux.HandleFunc("GET "+prefix, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 1, "node_id": "R_1", "name": "r", "full_name": "o/r"})
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Input struct {
+					RepositoryID string `json:"repositoryId"`
+					RefUpdates   []struct {
+						Name      string `json:"name"`
+						AfterOid  string `json:"afterOid"`
+						BeforeOid string `json:"beforeOid"`
+						Force     bool   `json:"force"`
+					} `json:"refUpdates"`
+				} `json:"input"`
+			} `json:"variables"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		input := body.Variables.Input
+		if !strings.Contains(body.Query, "updateRefs") || input.RepositoryID != "R_1" {
+			writeJSON(w, http.StatusOK, map[string]any{"errors": []map[string]string{{"message": "bad query"}}})
+			return
+		}
+		// the updates are atomic, all or nothing
+		for _, u := range input.RefUpdates {
+			sha, ok := f.refs[u.Name]
+			if !ok || sha != u.BeforeOid || (!u.Force && !f.isAncestor(sha, u.AfterOid)) {
+				writeJSON(w, http.StatusOK, map[string]any{"errors": []map[string]string{{"message": "A ref update was rejected"}}})
+				return
+			}
+		}
+		for _, u := range input.RefUpdates {
+			f.refs[u.Name] = u.AfterOid
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"updateRefs": map[string]any{"clientMutationId": nil}}})
+	})
+	mux.HandleFunc("DELETE "+prefix+"/git/refs/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		ref := "refs/" + r.PathValue("ref")
+		if _, ok := f.refs[ref]; !ok {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference does not exist"})
+			return
+		}
+		delete(f.refs, ref)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET "+prefix+"/git/matching-refs/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		refs := []map[string]any{}
+		for ref, sha := range f.refs {
+			if strings.HasPrefix(ref, "refs/"+r.PathValue("ref")) {
+				refs = append(refs, map[string]any{"ref": ref, "object": map[string]string{"sha": sha}})
+			}
+		}
+		sort.Slice(refs, func(i, j int) bool { return refs[i]["ref"].(string) < refs[j]["ref"].(string) })
+		writeJSON(w, http.StatusOK, refs)
+	})
+	mux.HandleFunc("GET "+prefix+"/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c, ok := f.commits[r.PathValue("sha")]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, toGitHubCommit(c))
+	})
+	mux.HandleFunc("POST "+prefix+"/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := struct {
+			Message   string            `json:"message"`
+			Tree      string            `json:"tree"`
+			Parents   []string          `json:"parents"`
+			Committer map[string]string `json:"committer"`
+			Signature string            `json:"signature"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sha := f.commit(body.Tree, body.Message, body.Parents...)
+		f.commits[sha].Committer, f.commits[sha].Signature = body.Committer, body.Signature
+		f.created = append(f.created, f.commits[sha])
+		writeJSON(w, http.StatusCreated, toGitHubCommit(f.commits[sha]))
+	})
+	mux.HandleFunc("GET "+prefix+"/git/trees/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		tree, ok := f.trees[r.PathValue("sha")]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		entries, dirs := []map[string]string{}, map[string]bool{}
+		for path, entry := range tree {
+			entries = append(entries, map[string]string{"path": path, "mode": entry.Mode, "type": "blob", "sha": entry.SHA})
+			for dir := path; strings.Contains(dir, "/"); {
+				dir = dir[:strings.LastIndex(dir, "/")]
+				dirs[dir] = true
+			}
+		}
+		for dir := range dirs {
+			entries = append(entries, map[string]string{"path": dir, "mode": "040000", "type": "tree", "sha": "dir-" + dir})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"sha": r.PathValue("sha"), "tree": entries, "truncated": false})
+	})
+	mux.HandleFunc("GET "+prefix+"/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		content, ok := f.blobs[r.PathValue("sha")]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("POST "+prefix+"/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		content, _ := base64.StdEncoding.DecodeString(body["content"])
+		f.blobs[blobSHA(string(content))] = string(content)
+		f.writes = append(f.writes, "blob")
+		writeJSON(w, http.StatusCreated, map[string]string{"sha": blobSHA(string(content))})
+	})
+	mux.HandleFunc("POST "+prefix+"/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := struct {
+			BaseTree string `json:"base_tree"`
+			Tree     []struct {
+				Path    string  `json:"path"`
+				Mode    string  `json:"mode"`
+				SHA     *string `json:"sha"`
+				Content *string `json:"content"`
+			} `json:"tree"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.writes = append(f.writes, "tree")
+		files := map[string]fakeEntry{}
+		for path, entry := range f.trees[body.BaseTree] {
+			files[path] = entry
+		}
+		for _, entry := range body.Tree {
+			switch {
+			case entry.Content != nil:
+				f.blobs[blobSHA(*entry.Content)] = *entry.Content
+				files[entry.Path] = fakeEntry{Mode: entry.Mode, SHA: blobSHA(*entry.Content)}
+			case entry.SHA != nil:
+				files[entry.Path] = fakeEntry{Mode: entry.Mode, SHA: *entry.SHA}
+			default:
+				delete(files, entry.Path)
+			}
+		}
+		f.seq++
+		sha := fmt.Sprintf("tree-%d", f.seq)
+		f.trees[sha] = files
+		writeJSON(w, http.StatusCreated, map[string]string{"sha": sha})
+	})
+	mux.HandleFunc("GET "+prefix+"/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		base, head, _ := strings.Cut(r.PathValue("basehead"), "...")
+		if sha, ok := f.refs["refs/heads/"+head]; ok {
+			head = sha
+		}
+		if _, ok := f.commits[head]; !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		status := "diverged"
+		switch {
+		case base == head:
+			status = "identical"
+		case f.isAncestor(base, head):
+			status = "ahead"
+		case f.isAncestor(head, base):
+			status = "behind"
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": status})
+	})
+	mux.HandleFunc("POST "+prefix+"/merges", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.merges = append(f.merges, body)
+		if f.onMerge != nil {
+			f.onMerge()
+		}
+		if f.conflict {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "Merge conflict"})
+			return
+		}
+		base := f.refs[body["base"]]
+		tree := f.merged
+		if tree == "" {
+			tree = fmt.Sprintf("merged-%d", len(f.merges))
+		}
+		sha := f.commit(tree, body["commit_message"], base, body["head"])
+		f.refs[body["base"]] = sha
+		writeJSON(w, http.StatusCreated, map[string]any{"sha": sha, "commit": map[string]any{"tree": map[string]string{"sha": f.commits[sha].Tree}}})
+	})
+}
+
+func blobSHA(content string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(content)))
+}
+
+func toGitHubCommit(c *fakeCommit) map[string]any {
+	parents := make([]map[string]string, 0, len(c.Parents))
+	for _, p := range c.Parents {
+		parents = append(parents, map[string]string{"sha": p})
+	}
+	committer := map[string]string{"name": "norn", "email": "norn@example.com", "date": "2024-01-02T03:04:05Z"}
+	if c.Committer != nil {
+		committer = c.Committer
+	}
+	return map[string]any{
+		"sha":       c.SHA,
+		"message":   c.Message,
+		"tree":      map[string]string{"sha": c.Tree},
+		"parents":   parents,
+		"author":    map[string]string{"name": "norn", "email": "norn@example.com", "date": "2024-01-02T03:04:05Z"},
+		"committer": committer,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewGitHubWithBaseUrl(t *testing.T) {
+	baseUrl, uploadUrl := "https://ghe.example.com/api/v3/", "ghe.example.com/uploads"
+	_, err := NewGitHubWithBaseUrl(context.Background(), &tp.CreateProviderOption{BaseUrl: &baseUrl, UploadUrl: &uploadUrl})
+	assert.ErrorIs(t, err, tp.ErrInvalidOptions)
+
+	// the upload url falls back to the one of the base url
+	client, err := NewGitHubWithBaseUrl(context.Background(), &tp.CreateProviderOption{BaseUrl: &baseUrl})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ghe.example.com/api/uploads/", client.UploadURL.String())
+}
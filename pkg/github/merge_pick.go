@@ -0,0 +1,163 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	gh "github.com/google/go-github/v62/github"
+	"github.com/kentio/norn/pkg/merge"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"unicode/utf8"
+)
+
+// mergeApply is apply of the merge engine. The trees of the target, of Base and of Head are read,
+// the change from Base to Head is merged into the target tree in process and only the changed
+// blobs, the tree and the pick commit are written. No temporary ref is created.
+func (c *PickService) mergeApply(ctx context.Context, pc *pickContext, opt *applyOption) (*tp.PickResult, error) {
+	repoOpt, latestCommit := pc.repoOpt, pc.latest
+	result, err := c.mergeTrees(ctx, pc, opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Changes) == 0 {
+		logrus.Infof("Pick %s to %s is empty", pc.opt.SHA, pc.opt.Branch)
+		return nil, tp.ErrEmptyPick
+	}
+
+	entries, err := c.treeEntries(ctx, repoOpt, result.Changes)
+	if err != nil {
+		return nil, err
+	}
+	tree, _, err := c.client.Git.CreateTree(ctx, repoOpt.Owner, repoOpt.Repo, latestCommit.Tree.GetSHA(), entries)
+	if err != nil {
+		logrus.Errorf("Failed to create the tree of %s on %s: %v", pc.opt.SHA, pc.opt.Branch, err)
+		return nil, err
+	}
+	if tree.GetSHA() == latestCommit.Tree.GetSHA() {
+		logrus.Infof("Pick %s to %s is empty", pc.opt.SHA, pc.opt.Branch)
+		return nil, tp.ErrEmptyPick
+	}
+	if pc.opt.DryRun {
+		logrus.Infof("Dry run: pick %s to %s results in tree %s", pc.opt.SHA, pc.opt.Branch, tree.GetSHA())
+		return &tp.PickResult{Tree: tree.GetSHA()}, nil
+	}
+
+	newCommit, err := c.createPickCommit(ctx, pc, opt, tree.GetSHA())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fastForward(ctx, c.client, repoOpt, pc.target.GetRef(), newCommit.GetSHA()); err != nil {
+		logrus.Errorf("update target branch error %s: %v", pc.target.GetRef(), err)
+		return nil, err
+	}
+	return &tp.PickResult{SHA: newCommit.GetSHA(), Tree: tree.GetSHA()}, nil
+}
+
+// mergeTrees merges the change from Base to Head into the tree of the target, nothing is written.
+// It returns a *tp.ConflictError if the change conflicts.
+func (c *PickService) mergeTrees(ctx context.Context, pc *pickContext, opt *applyOption) (*merge.Result, error) {
+	repoOpt := pc.repoOpt
+	baseTree, err := c.commitTree(ctx, pc, opt.Base)
+	if err != nil {
+		return nil, err
+	}
+	theirsTree, err := c.commitTree(ctx, pc, opt.Head)
+	if err != nil {
+		return nil, err
+	}
+	trees := make([]merge.Tree, 3)
+	for i, sha := range []string{baseTree, pc.latest.Tree.GetSHA(), theirsTree} {
+		if trees[i], err = c.readTree(ctx, repoOpt, sha); err != nil {
+			return nil, err
+		}
+	}
+	result, err := merge.Trees(trees[0], trees[1], trees[2], func(sha string) ([]byte, error) {
+		content, _, err := c.client.Git.GetBlobRaw(ctx, repoOpt.Owner, repoOpt.Repo, sha)
+		return content, err
+	})
+	if err != nil {
+		logrus.Errorf("Merge %s to %s: %v", pc.opt.SHA, pc.opt.Branch, err)
+		return nil, err
+	}
+	if len(result.Conflicts) > 0 {
+		logrus.Warnf("Pick %s to %s conflicts in %v", pc.opt.SHA, pc.opt.Branch, result.Conflicts)
+		return nil, &tp.ConflictError{Files: result.Conflicts}
+	}
+	return result, nil
+}
+
+// checkConflict merges the commit into the target like a pick by the merge engine, nothing is written
+func (c *PickService) checkConflict(ctx context.Context, opt *tp.CheckConflictOption) error {
+	pc, err := c.prepare(ctx, opt.Repo, &tp.PickOption{SHA: opt.Commit, Branch: opt.Target, Mainline: opt.Mainline})
+	if err != nil {
+		return err
+	}
+	_, err = c.mergeTrees(ctx, pc, &applyOption{Base: pc.source.Parents[pc.mainline].GetSHA(), Head: opt.Commit})
+	return err
+}
+
+// commitTree returns the tree of the commit, the source commit is already read
+func (c *PickService) commitTree(ctx context.Context, pc *pickContext, sha string) (string, error) {
+	if sha == pc.source.GetSHA() {
+		return pc.source.Tree.GetSHA(), nil
+	}
+	commit, _, err := c.client.Git.GetCommit(ctx, pc.repoOpt.Owner, pc.repoOpt.Repo, sha)
+	if err != nil {
+		logrus.Errorf("Get commit %s: %v", sha, err)
+		return "", err
+	}
+	return commit.Tree.GetSHA(), nil
+}
+
+// readTree reads the tree recursively, a tree too large for one response is not supported
+func (c *PickService) readTree(ctx context.Context, repoOpt *RepoOption, sha string) (merge.Tree, error) {
+	tree, _, err := c.client.Git.GetTree(ctx, repoOpt.Owner, repoOpt.Repo, sha, true)
+	if err != nil {
+		logrus.Errorf("Get tree %s: %v", sha, err)
+		return nil, err
+	}
+	if tree.GetTruncated() {
+		return nil, fmt.Errorf("%w: tree %s is too large for the merge engine", tp.ErrNotSupported, sha)
+	}
+	files := merge.Tree{}
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "tree" {
+			continue
+		}
+		files[entry.GetPath()] = merge.Entry{Mode: entry.GetMode(), SHA: entry.GetSHA()}
+	}
+	return files, nil
+}
+
+// treeEntries returns the entries of the changes. A merged text is sent in the tree, a merged
+// content which is not UTF-8 is written as a blob first.
+func (c *PickService) treeEntries(ctx context.Context, repoOpt *RepoOption, changes []merge.Change) ([]*gh.TreeEntry, error) {
+	entries := make([]*gh.TreeEntry, 0, len(changes))
+	for _, change := range changes {
+		entry := &gh.TreeEntry{Path: gh.String(change.Path), Mode: gh.String(change.Mode), Type: gh.String("blob")}
+		if change.Mode == merge.ModeSubmodule {
+			entry.Type = gh.String("commit")
+		}
+		switch {
+		case change.Delete:
+			// no sha and no content deletes the path
+		case change.SHA != "":
+			entry.SHA = gh.String(change.SHA)
+		case utf8.Valid(change.Content):
+			entry.Content = gh.String(string(change.Content))
+		default:
+			blob, _, err := c.client.Git.CreateBlob(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Blob{
+				Content:  gh.String(base64.StdEncoding.EncodeToString(change.Content)),
+				Encoding: gh.String("base64"),
+			})
+			if err != nil {
+				logrus.Errorf("Failed to create the blob of %s: %v", change.Path, err)
+				return nil, err
+			}
+			entry.SHA = blob.SHA
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"errors"
+	tp "github.com/kentio/norn/pkg/types"
+	"reflect"
+	"testing"
+)
+
+const mergeBase = "a\nb\nc\nd\ne\nf\ng\n"
+
+// setupMergePick returns the merge engine on a repo whose release branch and fix commit change
+// different lines of the file "a" of their base
+func setupMergePick(t *testing.T, release map[string]string) (*fakeGitHub, *PickService, string, string) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit(f.tree(map[string]string{"a": mergeBase, "b": "b\n", "latin": "\xe9\n1\n2\n3\n4\n"}), "init")
+	target := f.commit(f.tree(release), "release", base)
+	source := f.commit(f.tree(map[string]string{"a": "a\nb\nc\nd\ne\nF\ng\n", "c": "c\n", "latin": "\xe9\n1\n2\n3\n4!\n"}), "fix", base)
+	f.refs["refs/heads/release"] = target
+	service := NewPickService(client)
+	service.engine = tp.EngineMerge
+	return f, service, target, source
+}
+
+func TestPickService_MergeEngine(t *testing.T) {
+	f, service, target, source := setupMergePick(t, map[string]string{"a": "a\nB\nc\nd\ne\nf\ng\n", "b": "b\n", "latin": "\xe9!\n1\n2\n3\n4\n"})
+
+	result, err := service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	picked := f.commits[f.refs["refs/heads/release"]]
+	if result.SHA != picked.SHA || len(picked.Parents) != 1 || picked.Parents[0] != target || picked.Tree != result.Tree {
+		t.Fatalf("picked: %+v result: %+v", picked, result)
+	}
+	want := map[string]string{"a": "a\nB\nc\nd\ne\nF\ng\n", "c": "c\n", "latin": "\xe9!\n1\n2\n3\n4!\n"}
+	if files := f.files(picked.Tree); !reflect.DeepEqual(files, want) {
+		t.Fatalf("files: %q", files)
+	}
+	// only the merged blob which is not UTF-8, the tree and the pick commit are written
+	if !reflect.DeepEqual(f.writes, []string{"blob", "tree"}) || len(f.created) != 1 || len(f.merges) != 0 || len(f.refs) != 1 {
+		t.Fatalf("writes: %v created: %d merges: %d refs: %v", f.writes, len(f.created), len(f.merges), f.refs)
+	}
+}
+
+func TestPickService_MergeEngineConflict(t *testing.T) {
+	f, service, target, source := setupMergePick(t, map[string]string{"a": "a\nb\nc\nd\ne\nf!\ng\n", "latin": "\xe9\n1\n2\n3\n4\n"})
+
+	_, err := service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"})
+	var conflict *tp.ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, tp.ErrConflict) {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	// b is deleted on both sides, which is not a conflict
+	if !reflect.DeepEqual(conflict.Files, []string{"a"}) {
+		t.Fatalf("files: %v", conflict.Files)
+	}
+	if len(f.writes) != 0 || len(f.created) != 0 || f.refs["refs/heads/release"] != target {
+		t.Fatalf("conflicting pick changed the repo")
+	}
+}
+
+func TestPickService_MergeEngineDryRunAndEmpty(t *testing.T) {
+	f, service, target, source := setupMergePick(t, map[string]string{"a": "a\nb\nc\nd\ne\nF\ng\n", "c": "c\n", "latin": "\xe9\n1\n2\n3\n4!\n"})
+
+	// the change of the source is already on the target
+	if _, err := service.Pick(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"}); err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+	result, err := service.Revert(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := map[string]string{"a": mergeBase, "b": "b\n", "latin": "\xe9\n1\n2\n3\n4\n"}
+	if result.SHA != "" || !reflect.DeepEqual(f.files(result.Tree), want) {
+		t.Fatalf("result: %+v files: %q", result, f.files(result.Tree))
+	}
+	if len(f.created) != 0 || f.refs["refs/heads/release"] != target {
+		t.Fatalf("dry run changed the branch")
+	}
+}
+
+func TestPickService_MergeEngineRevert(t *testing.T) {
+	f, service, target, source := setupMergePick(t, map[string]string{"a": "a\nB\nc\nd\ne\nF\ng\n", "b": "b\n", "c": "c\n", "latin": "\xe9\n1\n2\n3\n4!\n"})
+
+	result, err := service.Revert(context.Background(), "o/r", &tp.PickOption{SHA: source, Branch: "release"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	reverted := f.commits[f.refs["refs/heads/release"]]
+	if reverted.SHA != result.SHA || reverted.Parents[0] != target || reverted.Message != tp.RevertMessage(source, "fix") {
+		t.Fatalf("reverted: %+v", reverted)
+	}
+	want := map[string]string{"a": "a\nB\nc\nd\ne\nf\ng\n", "b": "b\n", "latin": "\xe9\n1\n2\n3\n4\n"}
+	if files := f.files(reverted.Tree); !reflect.DeepEqual(files, want) {
+		t.Fatalf("files: %q", files)
+	}
+}
+
+func TestCommitService_CheckConflict(t *testing.T) {
+	f, service, _, source := setupMergePick(t, map[string]string{"a": "a\nb\nc\nd\ne\nf!\ng\n", "latin": "\xe9\n1\n2\n3\n4\n"})
+	commits := NewCommitService(service.client)
+
+	err := commits.CheckConflict(context.Background(), &tp.CheckConflictOption{Repo: "o/r", Commit: source, Target: "release", Mode: tp.WithAPI})
+	var conflict *tp.ConflictError
+	if !errors.As(err, &conflict) || !reflect.DeepEqual(conflict.Files, []string{"a"}) {
+		t.Fatalf("err = %v, want conflict in a", err)
+	}
+
+	f.refs["refs/heads/clean"] = f.commit(f.tree(map[string]string{"a": "a\nB\nc\nd\ne\nf\ng\n", "latin": "\xe9\n1\n2\n3\n4\n"}), "clean")
+	err = commits.CheckConflict(context.Background(), &tp.CheckConflictOption{Repo: "o/r", Commit: source, Target: "clean", Mode: tp.WithAPI})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the check reads only
+	if len(f.writes) != 0 || len(f.created) != 0 || len(f.merges) != 0 || len(f.refs) != 2 {
+		t.Fatalf("writes: %v created: %d refs: %v", f.writes, len(f.created), f.refs)
+	}
+}
@@ -18,22 +18,15 @@ type Provider struct {
 	repositoryService   *RepositoryService
 }
 
-func NewProvider(ctx context.Context, opt *tp.CreateProviderOption) *Provider {
-	var client *gh.Client
-	if opt.BaseUrl != nil {
-		client = NewGitHubWithBaseUrl(ctx, opt)
-	} else {
-		client = NewGithubClient(ctx, opt.Token)
-	}
-	return &Provider{
-		providerID:          tp.GitHubProvider,
-		commitService:       NewCommitService(client),
-		referenceService:    NewReferenceService(client),
-		mergeRequestService: NewPullRequestService(client),
-		commentService:      NewCommentService(client),
-		pickService:         NewPickService(client),
-		repositoryService:   NewRepositoryService(client),
+func NewProvider(ctx context.Context, opt *tp.CreateProviderOption) (*Provider, error) {
+	client, err := NewGithubClientWithOption(ctx, opt)
+	if err != nil {
+		return nil, err
 	}
+	provider := NewProviderWithClient(client)
+	provider.SetSigner(opt.Signer)
+	provider.SetEngine(opt.Engine)
+	return provider, nil
 }
 
 // NewProviderWithClient creates a new provider with the given client.
@@ -50,6 +43,18 @@ func NewProviderWithClient(client *gh.Client) *Provider {
 	}
 }
 
+// SetSigner signs the commits created by the provider, unsigned if nil
+func (p *Provider) SetSigner(signer tp.Signer) {
+	p.commitService.signer = signer
+	p.pickService.signer = signer
+}
+
+// SetEngine sets how the picks are made, tp.EngineMerge merges in process, any other engine uses
+// the merges API
+func (p *Provider) SetEngine(engine tp.PickEngine) {
+	p.pickService.engine = engine
+}
+
 func (p *Provider) Commit() tp.CommitService {
 	return p.commitService
 }
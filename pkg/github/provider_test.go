@@ -6,7 +6,10 @@ import (
 )
 
 func TestNewProvider(t *testing.T) {
-	provider := NewProvider(nil, &types.CreateProviderOption{Token: ""})
+	provider, err := NewProvider(nil, &types.CreateProviderOption{Token: ""})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
 	t.Logf("provider: %v", provider)
 }
@@ -2,11 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	gh "github.com/google/go-github/v62/github"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
+	"net/http"
 	"strconv"
+	"strings"
 )
 
 type PullRequestService struct {
@@ -18,10 +21,12 @@ type PullRequest struct {
 	title       string
 	description string
 	state       tp.MergeRequestState
+	labels      []string
+	webUrl      string
 }
 
 func (s *PullRequest) MergeId() string {
-	return s.title
+	return strconv.Itoa(s.id)
 }
 
 func (s *PullRequest) Title() string {
@@ -36,6 +41,14 @@ func (s *PullRequest) State() tp.MergeRequestState {
 	return s.state
 }
 
+func (s *PullRequest) Labels() []string {
+	return s.labels
+}
+
+func (s *PullRequest) WebUrl() string {
+	return s.webUrl
+}
+
 func NewPullRequestService(client *gh.Client) *PullRequestService {
 	return &PullRequestService{
 		client: client,
@@ -66,12 +79,87 @@ func (s *PullRequestService) Get(ctx context.Context, opt *tp.GetMergeRequestOpt
 	return newPullRequest(pr), nil
 }
 
+// ListCommits returns the commits of the pull request, GitHub lists them oldest first and at most 250
+func (s *PullRequestService) ListCommits(ctx context.Context, opt *tp.ListMergeRequestCommitsOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	mergeId, err := strconv.Atoi(opt.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	var commits []tp.Commit
+	listOpt := &gh.ListOptions{PerPage: 100}
+	for {
+		page, response, err := s.client.PullRequests.ListCommits(ctx, repoOpt.Owner, repoOpt.Repo, mergeId, listOpt)
+		if err != nil {
+			logrus.Errorf("List PR Commits Error: %+v", err)
+			return nil, err
+		}
+		for _, c := range page {
+			commits = append(commits, newCommit(c))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		listOpt.Page = response.NextPage
+	}
+	return commits, nil
+}
+
+// Create opens a pull request and adds the labels to it
+func (s *PullRequestService) Create(ctx context.Context, opt *tp.CreateMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Pull Request Opt: %+v", *opt)
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, _, err := s.client.PullRequests.Create(ctx, repoOpt.Owner, repoOpt.Repo, &gh.NewPullRequest{
+		Title: gh.String(opt.Title),
+		Head:  gh.String(opt.Head),
+		Base:  gh.String(opt.Base),
+		Body:  gh.String(opt.Description),
+	})
+	if err != nil {
+		if pullRequestExists(err) {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create PR Error: %+v", err)
+		return nil, err
+	}
+	if len(opt.Labels) > 0 {
+		labels, _, err := s.client.Issues.AddLabelsToIssue(ctx, repoOpt.Owner, repoOpt.Repo, pr.GetNumber(), opt.Labels)
+		if err != nil {
+			// the pull request is opened, missing labels are not fatal
+			logrus.Warnf("Add labels to PR %d: %v", pr.GetNumber(), err)
+		} else {
+			pr.Labels = labels
+		}
+	}
+	return newPullRequest(pr), nil
+}
+
 func newPullRequest(pr *gh.PullRequest) (mr *PullRequest) {
+	var labels []string
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
 	return &PullRequest{
 		id:          pr.GetNumber(),
 		title:       pr.GetTitle(),
 		description: pr.GetBody(),
 		state:       mr.getStateFromGithubPullRequest(pr),
+		labels:      labels,
+		webUrl:      pr.GetHTMLURL(),
 	}
 }
 
@@ -91,3 +179,18 @@ func getStateFromGitHubPullRequestState(state string) tp.MergeRequestState {
 		return tp.MergeRequestStateUnknown
 	}
 }
+
+// pullRequestExists reports whether the create failed because a pull request of the head to the
+// base is open, GitHub responds 422 "A pull request already exists for o:head"
+func pullRequestExists(err error) bool {
+	var e *gh.ErrorResponse
+	if !errors.As(err, &e) || e.Response == nil || e.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, detail := range e.Errors {
+		if strings.Contains(detail.Message, "already exists") {
+			return true
+		}
+	}
+	return false
+}
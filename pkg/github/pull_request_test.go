@@ -2,7 +2,10 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/kentio/norn/pkg/types"
+	"net/http"
 	"testing"
 )
 
@@ -25,3 +28,66 @@ func TestPullRequestService_Get(t *testing.T) {
 	t.Logf("pr: %+v state %s", pr, pr.State().String())
 
 }
+
+func TestPullRequestService_ListCommits(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("GET /repos/o/r/pulls/3/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"sha":"ccc","commit":{"message":"third","tree":{"sha":"t3"}}}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"sha":"aaa","commit":{"message":"first","tree":{"sha":"t1"}}},`+
+			`{"sha":"bbb","commit":{"message":"second","tree":{"sha":"t2"}}}]`)
+	})
+
+	commits, err := NewPullRequestService(client).ListCommits(context.Background(), &types.ListMergeRequestCommitsOption{Repo: "o/r", MergeID: "3"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(commits) != 3 || commits[0].SHA() != "aaa" || commits[2].SHA() != "ccc" {
+		t.Fatalf("commits: %+v", commits)
+	}
+}
+
+func TestPullRequestService_Create(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["head"] != "backport/3-main" || body["base"] != "main" {
+			t.Errorf("body: %v", body)
+		}
+		fmt.Fprintf(w, `{"number":4,"title":%q,"state":"open","html_url":"https://github.com/o/r/pull/4"}`, body["title"])
+	})
+	mux.HandleFunc("POST /repos/o/r/issues/4/labels", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bug"}]`)
+	})
+
+	pr, err := NewPullRequestService(client).Create(context.Background(), &types.CreateMergeRequestOption{
+		Repo:   "o/r",
+		Title:  "[Backport main] fix",
+		Head:   "backport/3-main",
+		Base:   "main",
+		Labels: []string{"bug"},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if pr.MergeId() != "4" || pr.WebUrl() != "https://github.com/o/r/pull/4" || len(pr.Labels()) != 1 {
+		t.Fatalf("pr: %+v", pr)
+	}
+}
+
+func TestPullRequestService_CreateExists(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"PullRequest","code":"custom","message":"A pull request already exists for o:backport/3-main."}]}`)
+	})
+
+	_, err := NewPullRequestService(client).Create(context.Background(), &types.CreateMergeRequestOption{Repo: "o/r", Head: "backport/3-main", Base: "main"})
+	if err != types.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
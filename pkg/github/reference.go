@@ -2,11 +2,13 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	gh "github.com/google/go-github/v62/github"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"strings"
 )
 
 type ReferenceService struct {
@@ -52,11 +54,35 @@ func (s *ReferenceService) Find(ctx context.Context, opts *tp.FindOptions) ([]tp
 	return nil, nil
 }
 
-func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateOptions) (*tp.Reference, error) {
-	return nil, nil
+// Create creates the reference at the commit
+func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Create Reference Opt: %+v", opt)
+	ref, response, err := s.client.Git.CreateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
+		Ref: gh.String(opt.Ref),
+		Object: &gh.GitObject{
+			SHA: gh.String(opt.SHA),
+		},
+	})
+	if err != nil {
+		// GitHub responds 422 "Reference already exists"
+		if response != nil && response.StatusCode == http.StatusUnprocessableEntity {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create Reference Error: %v", err)
+		return nil, err
+	}
+	return newBranch(ref), nil
 }
 
-// Update updates the reference with the specified options.
+// Update fast-forwards the reference. With an expected sha, forced or not, the update is a
+// compare-and-swap by the updateRefs mutation of the GraphQL API.
 func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
 	if opt == nil {
 		return nil, tp.ErrInvalidOptions
@@ -66,25 +92,137 @@ func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*t
 		return nil, err
 	}
 	logrus.Debugf("Update Reference Opt: %+v", opt)
-	ref, response, err := s.client.Git.UpdateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
-		Ref: gh.String(opt.Ref),
+	if opt.Force && opt.ExpectedSHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	if opt.ExpectedSHA != "" {
+		if err = s.compareAndSwap(ctx, repoOpt, opt.Ref, opt.SHA, opt.ExpectedSHA, opt.Force); err != nil {
+			return nil, err
+		}
+		return &tp.Reference{Ref: opt.Ref, SHA: opt.SHA}, nil
+	}
+	ref, err := fastForward(ctx, s.client, repoOpt, opt.Ref, opt.SHA)
+	if err != nil {
+		if err == tp.ErrStaleRef {
+			return nil, fmt.Errorf("reference: %s update is not a fast forward", opt.Ref)
+		}
+		logrus.Errorf("Update Reference Error: %v", err)
+		return nil, err
+	}
+	logrus.Debugf("Update Reference: %+v", *ref)
+	return newBranch(ref), nil
+}
+
+// fastForward updates the ref without force, ErrStaleRef if the commit does not descend from the ref
+func fastForward(ctx context.Context, client *gh.Client, repoOpt *RepoOption, ref, sha string) (*gh.Reference, error) {
+	updated, response, err := client.Git.UpdateRef(ctx, repoOpt.Owner, repoOpt.Repo, &gh.Reference{
+		Ref: gh.String(ref),
 		Object: &gh.GitObject{
-			SHA: gh.String(opt.SHA),
+			SHA: gh.String(sha),
 		},
 	}, false)
-	logrus.Debugf("Update Reference Response: %+v", response)
 	if err != nil {
-		logrus.Errorf("Update Reference Error: %v", err)
+		// GitHub responds 422 "Update is not a fast forward", and 422 for a missing ref or sha too
+		var e *gh.ErrorResponse
+		if response != nil && response.StatusCode == http.StatusUnprocessableEntity &&
+			errors.As(err, &e) && strings.Contains(strings.ToLower(e.Message), "not a fast forward") {
+			return nil, tp.ErrStaleRef
+		}
 		return nil, err
 	}
-	if response.StatusCode == http.StatusUnprocessableEntity {
-		return nil, fmt.Errorf("reference: %v", *ref.Ref)
+	return updated, nil
+}
+
+const updateRefsMutation = `mutation($input: UpdateRefsInput!) { updateRefs(input: $input) { clientMutationId } }`
+
+// compareAndSwap updates the ref to sha if it is at expected. GitHub checks beforeOid of the
+// updateRefs mutation atomically with the update, a ref which moved fails with ErrStaleRef.
+func (s *ReferenceService) compareAndSwap(ctx context.Context, repoOpt *RepoOption, ref, sha, expected string, force bool) error {
+	repo, _, err := s.client.Repositories.Get(ctx, repoOpt.Owner, repoOpt.Repo)
+	if err != nil {
+		logrus.Errorf("Get repository %s/%s: %v", repoOpt.Owner, repoOpt.Repo, err)
+		return err
+	}
+	// the GraphQL endpoint is /graphql on github.com and /api/graphql on GitHub Enterprise Server
+	req, err := s.client.NewRequest(http.MethodPost, "../graphql", map[string]any{
+		"query": updateRefsMutation,
+		"variables": map[string]any{"input": map[string]any{
+			"repositoryId": repo.GetNodeID(),
+			"refUpdates": []map[string]any{
+				{"name": ref, "afterOid": sha, "beforeOid": expected, "force": force},
+			},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err = s.client.Do(ctx, req, &result); err != nil {
+		logrus.Errorf("Update Reference %s: %v", ref, err)
+		return err
+	}
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	// the mutation tells no reason, the ref is read to tell a moved ref from other failures
+	logrus.Warnf("Update Reference %s to %s: %s", ref, sha, result.Errors[0].Message)
+	if current, err := s.Get(ctx, &tp.GetRefOption{Repo: repoOpt.Owner + "/" + repoOpt.Repo, Ref: ref}); err == nil && current.SHA != expected {
+		return tp.ErrStaleRef
+	}
+	return fmt.Errorf("reference: update %s: %s", ref, result.Errors[0].Message)
+}
+
+// List returns the branches whose name starts with the prefix
+func (s *ReferenceService) List(ctx context.Context, opt *tp.ListRefOption) ([]*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("List Reference Opt: %+v", opt)
+	var refs []*tp.Reference
+	listOpt := &gh.ReferenceListOptions{Ref: "heads/" + opt.Prefix, ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		page, response, err := s.client.Git.ListMatchingRefs(ctx, repoOpt.Owner, repoOpt.Repo, listOpt)
+		if err != nil {
+			logrus.Errorf("List Reference Error: %v", err)
+			return nil, err
+		}
+		for _, ref := range page {
+			refs = append(refs, newBranch(ref))
+		}
+		if response.NextPage == 0 {
+			return refs, nil
+		}
+		listOpt.Page = response.NextPage
 	}
-	logrus.Debugf("Update Reference: %+v", *ref)
-	return &tp.Reference{Ref: *ref.Ref}, nil
 }
 
-func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteOptions) error {
+// Delete deletes the branch
+func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteRefOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	repoOpt, err := parseRepo(opt.Repo)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Delete Reference Opt: %+v", opt)
+	response, err := s.client.Git.DeleteRef(ctx, repoOpt.Owner, repoOpt.Repo, opt.Ref)
+	if err != nil {
+		// GitHub responds 422 "Reference does not exist"
+		if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusUnprocessableEntity) {
+			return tp.NotFound
+		}
+		logrus.Errorf("Delete Reference Error: %v", err)
+		return err
+	}
 	return nil
 }
 
@@ -21,3 +21,94 @@ func TestReferenceService_Get(t *testing.T) {
 	}
 	t.Logf("reference: %+v", reference)
 }
+
+func TestReferenceService_Create(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	f.refs["refs/heads/main"] = "abc"
+	ctx := context.Background()
+
+	ref, err := NewReferenceService(client).Create(ctx, &types.CreateRefOption{Repo: "o/r", Ref: "refs/heads/backport/1-main", SHA: "abc"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ref.Ref != "refs/heads/backport/1-main" || f.refs[ref.Ref] != "abc" {
+		t.Fatalf("ref: %+v refs: %v", ref, f.refs)
+	}
+}
+
+func TestReferenceService_CreateExists(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	f.refs["refs/heads/main"] = "abc"
+
+	_, err := NewReferenceService(client).Create(context.Background(), &types.CreateRefOption{Repo: "o/r", Ref: "refs/heads/main", SHA: "abc"})
+	if err != types.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
+
+func TestReferenceService_Update(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	base := f.commit("t0", "init")
+	head := f.commit("t1", "fix", base)
+	f.refs["refs/heads/main"] = base
+	ctx := context.Background()
+	service := NewReferenceService(client)
+
+	if _, err := service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/main", SHA: head, ExpectedSHA: head}); err != types.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	ref, err := service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/main", SHA: head, ExpectedSHA: base})
+	if err != nil || ref.SHA != head || f.refs["refs/heads/main"] != head {
+		t.Fatalf("err: %v ref: %+v", err, ref)
+	}
+	// not a fast forward
+	if _, err = service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/main", SHA: base}); err == nil {
+		t.Fatalf("update is not a fast forward")
+	}
+	// a reset is a compare-and-swap
+	if _, err = service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/main", SHA: base, ExpectedSHA: base, Force: true}); err != types.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	if f.refs["refs/heads/main"] != head {
+		t.Fatalf("refs: %v", f.refs)
+	}
+	ref, err = service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/main", SHA: base, ExpectedSHA: head, Force: true})
+	if err != nil || ref.SHA != base || f.refs["refs/heads/main"] != base {
+		t.Fatalf("err: %v ref: %+v refs: %v", err, ref, f.refs)
+	}
+	f.refs["refs/heads/main"] = head
+	// only a fast forward failure is stale
+	if _, err = service.Update(ctx, &types.UpdateOption{Repo: "o/r", Ref: "refs/heads/missing", SHA: head, ExpectedSHA: ""}); err == nil || err == types.ErrStaleRef {
+		t.Fatalf("err = %v, want the error of the missing ref", err)
+	}
+}
+
+func TestReferenceService_ListDelete(t *testing.T) {
+	mux, client := setup(t)
+	f := newFakeGitHub()
+	f.serve(mux)
+	f.refs["refs/heads/main"] = "abc"
+	f.refs["refs/heads/norn/tmp/20240102T030405Z-1a2b3c4d/pick-main-012345678"] = "def"
+	ctx := context.Background()
+	service := NewReferenceService(client)
+
+	refs, err := service.List(ctx, &types.ListRefOption{Repo: "o/r", Prefix: types.TempRefPrefix})
+	if err != nil || len(refs) != 1 || refs[0].SHA != "def" {
+		t.Fatalf("err: %v refs: %+v", err, refs)
+	}
+	if err = service.Delete(ctx, &types.DeleteRefOption{Repo: "o/r", Ref: refs[0].Ref}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := f.refs[refs[0].Ref]; ok {
+		t.Fatalf("ref is not deleted")
+	}
+	if err = service.Delete(ctx, &types.DeleteRefOption{Repo: "o/r", Ref: refs[0].Ref}); err != types.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
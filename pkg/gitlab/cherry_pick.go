@@ -0,0 +1,145 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+	"net/http"
+)
+
+type PickService struct {
+	client *gl.Client
+}
+
+func NewPickService(client *gl.Client) *PickService {
+	return &PickService{
+		client: client,
+	}
+}
+
+// Pick cherry-pick the commit to the target branch with the GitLab cherry-pick API
+func (c *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	source, err := c.source(ctx, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	warnCommitter(opt.Committer, opt.DateMode)
+	message := opt.Message
+	if message == "" {
+		message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", source.Message, source.ID[:7])
+	}
+	pickOpt := &gl.CherryPickCommitOptions{
+		Branch:  gl.Ptr(branchName(opt.Branch)),
+		Message: gl.Ptr(message),
+	}
+	if opt.DryRun {
+		// GitLab checks the pick without committing, the tree is not reported
+		pickOpt.DryRun = gl.Ptr(true)
+	}
+	commit, _, err := c.client.Commits.CherryPickCommit(repo, opt.SHA, pickOpt, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Cherry-pick %s to %s: %v", opt.SHA, opt.Branch, err)
+		return nil, pickError(err)
+	}
+	if opt.DryRun {
+		return &tp.PickResult{}, nil
+	}
+	logrus.Debugf("Cherry-pick %s to %s: %s", opt.SHA, opt.Branch, commit.ID)
+	return &tp.PickResult{SHA: commit.ID}, nil
+}
+
+// warnCommitter warns that the committer settings are ignored. The cherry-pick API of GitLab keeps
+// the author and commits as the user of the token at the current time.
+func warnCommitter(committer *tp.Signature, mode tp.DateMode) {
+	if committer != nil || mode == tp.DateOriginal {
+		logrus.Warnf("GitLab commits the pick as the user of the token now, the committer and date mode are ignored")
+	}
+}
+
+// revertOption is the body of the revert API, go-gitlab does not support its dry_run
+type revertOption struct {
+	Branch string `json:"branch"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// Revert reverts the commit on the target branch with the GitLab revert API
+func (c *PickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	if _, err := c.source(ctx, repo, opt); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("projects/%s/repository/commits/%s/revert", gl.PathEscape(repo), gl.PathEscape(opt.SHA))
+	req, err := c.client.NewRequest(http.MethodPost, path, &revertOption{Branch: branchName(opt.Branch), DryRun: opt.DryRun}, []gl.RequestOptionFunc{gl.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	commit := &gl.Commit{}
+	if _, err = c.client.Do(req, commit); err != nil {
+		logrus.Warnf("Revert %s on %s: %v", opt.SHA, opt.Branch, err)
+		return nil, pickError(err)
+	}
+	if opt.DryRun {
+		return &tp.PickResult{}, nil
+	}
+	logrus.Debugf("Revert %s on %s: %s", opt.SHA, opt.Branch, commit.ID)
+	return &tp.PickResult{SHA: commit.ID}, nil
+}
+
+// source checks the target branch and returns the commit to pick or revert
+func (c *PickService) source(ctx context.Context, repo string, opt *tp.PickOption) (*gl.Commit, error) {
+	if repo == "" || opt == nil || opt.SHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+
+	// GitLab returns 400 for a missing branch too, so check the target first
+	_, _, err := c.client.Branches.GetBranch(repo, branchName(opt.Branch), gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Get target branch %s: %v", opt.Branch, err)
+		return nil, tp.NotFound
+	}
+
+	source, _, err := c.client.Commits.GetCommit(repo, opt.SHA, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Errorf("Get source commit %s: %v", opt.SHA, err)
+		return nil, err
+	}
+
+	if err = checkMainline(source, opt.Mainline); err != nil {
+		logrus.Warnf("Pick %s: %v", opt.SHA, err)
+		return nil, err
+	}
+	return source, nil
+}
+
+// checkMainline GitLab always picks a merge commit against its first parent,
+// so only the first parent can be used as mainline.
+func checkMainline(commit *gl.Commit, mainline int) error {
+	parent, err := tp.MainlineParent(len(commit.ParentIDs), mainline)
+	if err != nil {
+		return err
+	}
+	if parent != 0 {
+		return fmt.Errorf("%w: GitLab picks merge commits against the first parent only", tp.ErrNotSupported)
+	}
+	return nil
+}
+
+// pickError converts the cherry-pick and revert API error to provider error
+func pickError(err error) error {
+	switch statusCode(err) {
+	case http.StatusNotFound:
+		return tp.NotFound
+	case http.StatusBadRequest:
+		// GitLab responds 400 when the commit can not be cherry-picked automatically,
+		// the error code tells an empty pick from a conflict
+		if errorCode(err) == "empty" {
+			return tp.ErrEmptyPick
+		}
+		return tp.ErrConflict
+	default:
+		return err
+	}
+}
@@ -0,0 +1,164 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const sourceSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func TestPickService_Pick(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/release%2F1.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"release/1.0","commit":{"id":"fff"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"%s","message":"fix: bug","parent_ids":["ddd"]}`, sourceSHA)
+	})
+	var body map[string]string
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA+"/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"id":"eee","message":"fix: bug"}`)
+	})
+
+	result, err := NewPickService(client).Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "release/1.0"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != "eee" {
+		t.Errorf("result: %+v", result)
+	}
+	if body["branch"] != "release/1.0" {
+		t.Errorf("branch = %s, want release/1.0", body["branch"])
+	}
+	if !strings.HasSuffix(body["message"], "(cherry picked from commit 0123456)") {
+		t.Errorf("message = %q", body["message"])
+	}
+}
+
+func TestPickService_PickDryRun(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"main","commit":{"id":"fff"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"%s","message":"fix: bug","parent_ids":["ddd"]}`, sourceSHA)
+	})
+	var body map[string]any
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA+"/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"dry_run":"success"}`)
+	})
+
+	result, err := NewPickService(client).Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if body["dry_run"] != true || result.SHA != "" {
+		t.Fatalf("body: %v result: %+v", body, result)
+	}
+}
+
+func TestPickService_PickEmpty(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"main","commit":{"id":"fff"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"%s","message":"fix: bug","parent_ids":["ddd"]}`, sourceSHA)
+	})
+	code := "empty"
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA+"/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"message":"Sorry, we cannot cherry-pick this commit automatically.","error_code":"%s"}`, code)
+	})
+
+	service := NewPickService(client)
+	if _, err := service.Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main"}); err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+	code = "conflict"
+	if _, err := service.Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main"}); err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestPickService_PickBranchNotFound(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"404 Branch Not Found"}`)
+	})
+	_, err := NewPickService(client).Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "missing"})
+	if err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestPickService_PickMergeCommit(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"main","commit":{"id":"fff"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"%s","message":"Merge branch","parent_ids":["p1","p2"]}`, sourceSHA)
+	})
+	picked := false
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA+"/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		picked = true
+		fmt.Fprint(w, `{"id":"eee","message":"Merge branch"}`)
+	})
+
+	service := NewPickService(client)
+	if _, err := service.Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main"}); !errors.Is(err, tp.ErrMainlineRequired) {
+		t.Fatalf("err = %v, want mainline required", err)
+	}
+	if _, err := service.Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main", Mainline: 2}); !errors.Is(err, tp.ErrNotSupported) {
+		t.Fatalf("err = %v, want not supported", err)
+	}
+	if picked {
+		t.Fatalf("ambiguous merge is picked")
+	}
+	if _, err := service.Pick(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main", Mainline: 1}); err != nil || !picked {
+		t.Fatalf("err: %v picked: %t", err, picked)
+	}
+}
+
+func TestPickService_Revert(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"main","commit":{"id":"fff"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/"+sourceSHA, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"%s","message":"fix: bug","parent_ids":["ddd"]}`, sourceSHA)
+	})
+	var body map[string]any
+	mux.HandleFunc("POST /api/v4/projects/g%2Fp/repository/commits/"+sourceSHA+"/revert", func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["dry_run"] == true {
+			fmt.Fprint(w, `{"dry_run":"success"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"eee","message":"Revert \"fix: bug\""}`)
+	})
+	service := NewPickService(client)
+
+	result, err := service.Revert(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main"})
+	if err != nil || result.SHA != "eee" || body["branch"] != "main" {
+		t.Fatalf("err: %v result: %+v body: %v", err, result, body)
+	}
+	result, err = service.Revert(context.Background(), "g/p", &tp.PickOption{SHA: sourceSHA, Branch: "main", DryRun: true})
+	if err != nil || result.SHA != "" || body["dry_run"] != true {
+		t.Fatalf("err: %v result: %+v body: %v", err, result, body)
+	}
+}
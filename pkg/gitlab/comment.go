@@ -0,0 +1,144 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+	"strconv"
+)
+
+// CommentService manages the notes of a merge request
+type CommentService struct {
+	client *gl.Client
+}
+
+type Comment struct {
+	commentId string
+	body      string
+}
+
+func NewCommentService(client *gl.Client) *CommentService {
+	return &CommentService{
+		client: client,
+	}
+}
+
+// Create Comment creates a new note on the given merge request.
+func (s *CommentService) Create(ctx context.Context, opt *tp.CreateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	mergeId, err := strconv.Atoi(opt.MergeRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+	note, _, err := s.client.Notes.CreateMergeRequestNote(opt.Repo, mergeId, &gl.CreateMergeRequestNoteOptions{
+		Body: gl.Ptr(opt.Body),
+	}, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Failed to add comment: %v", err)
+		return nil, err
+	}
+	return newNote(note), nil
+}
+
+// Find Comment finds notes on the given merge request, system notes are ignored.
+func (s *CommentService) Find(ctx context.Context, opt *tp.FindCommentOption) ([]tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Find Comment Opt: %+v", *opt)
+	mergeId, err := strconv.Atoi(opt.MergeRequestID)
+	if err != nil {
+		logrus.Errorf("failed to convert merge id to int: %v", err)
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	var comments []tp.Comment
+	listOpt := &gl.ListMergeRequestNotesOptions{ListOptions: gl.ListOptions{PerPage: 100}}
+	for {
+		notes, response, err := s.client.Notes.ListMergeRequestNotes(opt.Repo, mergeId, listOpt, gl.WithContext(ctx))
+		if err != nil {
+			logrus.Warnf("Failed to list notes: %v", err)
+			return nil, err
+		}
+		for _, note := range notes {
+			if note.System {
+				continue
+			}
+			comments = append(comments, newNote(note))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		listOpt.Page = response.NextPage
+	}
+	return comments, nil
+}
+
+// Update Comment updates a note on the given merge request.
+func (s *CommentService) Update(ctx context.Context, opt *tp.UpdateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Update Comment Opt: %+v", *opt)
+	mergeId, noteId, err := parseNoteID(opt.MergeRequestID, opt.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	note, _, err := s.client.Notes.UpdateMergeRequestNote(opt.Repo, mergeId, noteId, &gl.UpdateMergeRequestNoteOptions{
+		Body: gl.Ptr(opt.Body),
+	}, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Failed to update comment: %v", err)
+		return nil, err
+	}
+	return newNote(note), nil
+}
+
+// Delete Comment deletes a note on the given merge request.
+func (s *CommentService) Delete(ctx context.Context, opt *tp.DeleteCommentOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	mergeId, noteId, err := parseNoteID(opt.MergeRequestID, opt.CommentID)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Notes.DeleteMergeRequestNote(opt.Repo, mergeId, noteId, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Failed to delete comment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// parseNoteID notes are scoped to the merge request, both ids are required
+func parseNoteID(mergeRequestID, commentID string) (int, int, error) {
+	mergeId, err := strconv.Atoi(mergeRequestID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+	noteId, err := strconv.Atoi(commentID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to convert comment id to int: %v", err)
+	}
+	return mergeId, noteId, nil
+}
+
+func newNote(note *gl.Note) *Comment {
+	return &Comment{
+		commentId: strconv.Itoa(note.ID),
+		body:      note.Body,
+	}
+}
+
+func (c *Comment) CommentID() string {
+	return c.commentId
+}
+
+func (c *Comment) Body() string {
+	return c.body
+}
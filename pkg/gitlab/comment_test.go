@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"testing"
+)
+
+func TestCommentService_Find(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/merge_requests/3/notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id":3,"body":"summary"}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"id":1,"body":"hello"},{"id":2,"body":"changed the description","system":true}]`)
+	})
+
+	comments, err := NewCommentService(client).Find(context.Background(), &tp.FindCommentOption{Repo: "g/p", MergeRequestID: "3"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(comments) != 2 || comments[0].CommentID() != "1" || comments[1].Body() != "summary" {
+		t.Fatalf("comments: %+v", comments)
+	}
+}
+
+func TestCommentService_CreateUpdateDelete(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/merge_requests/3/notes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":7,"body":"created"}`)
+	})
+	var deleted bool
+	mux.HandleFunc("/api/v4/projects/g%2Fp/merge_requests/3/notes/7", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			fmt.Fprint(w, `{"id":7,"body":"updated"}`)
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ctx := context.Background()
+	service := NewCommentService(client)
+
+	comment, err := service.Create(ctx, &tp.CreateCommentOption{Repo: "g/p", MergeRequestID: "3", Body: "created"})
+	if err != nil || comment.CommentID() != "7" {
+		t.Fatalf("create: %v %+v", err, comment)
+	}
+	comment, err = service.Update(ctx, &tp.UpdateCommentOption{Repo: "g/p", MergeRequestID: "3", CommentID: "7", Body: "updated"})
+	if err != nil || comment.Body() != "updated" {
+		t.Fatalf("update: %v %+v", err, comment)
+	}
+	if err = service.Delete(ctx, &tp.DeleteCommentOption{Repo: "g/p", MergeRequestID: "3", CommentID: "7"}); err != nil || !deleted {
+		t.Fatalf("delete: %v", err)
+	}
+}
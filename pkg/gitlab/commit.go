@@ -0,0 +1,208 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+	"strings"
+)
+
+type Commit struct {
+	sha     string
+	message string
+	author  tp.Signature
+	parents []string
+}
+
+type CommitService struct {
+	client *gl.Client
+}
+
+func NewCommitService(client *gl.Client) *CommitService {
+	return &CommitService{
+		client: client,
+	}
+}
+
+// Get Commit returns the commit for the given sha.
+func (s *CommitService) Get(ctx context.Context, opt *tp.GetCommitOption) (tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Get Commit Opt: %+v", *opt)
+	commit, _, err := s.client.Commits.GetCommit(opt.Repo, opt.SHA, gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Debugf("Get Commit Error: %+v", err)
+		return nil, err
+	}
+	return newCommit(commit), nil
+}
+
+// List returns the recent commits of the branch, newest first
+func (s *CommitService) List(ctx context.Context, opt *tp.ListCommitOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("List Commits Opt: %+v", *opt)
+	listOpt := &gl.ListCommitsOptions{
+		RefName:     gl.Ptr(branchName(opt.Branch)),
+		ListOptions: gl.ListOptions{PerPage: 100},
+	}
+	var result []tp.Commit
+	for len(result) < opt.Limit {
+		commits, response, err := s.client.Commits.ListCommits(opt.Repo, listOpt, gl.WithContext(ctx))
+		if err != nil {
+			if isNotFound(err) {
+				return nil, tp.NotFound
+			}
+			logrus.Debugf("List Commits Error: %+v", err)
+			return nil, err
+		}
+		for _, c := range commits {
+			result = append(result, newCommit(c))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		listOpt.Page = response.NextPage
+	}
+	if len(result) > opt.Limit {
+		result = result[:opt.Limit]
+	}
+	return result, nil
+}
+
+// Diff returns the diff of the commit, GitLab returns the hunks of each file so the git headers
+// are added back.
+func (s *CommitService) Diff(ctx context.Context, opt *tp.GetCommitOption) (string, error) {
+	if opt == nil {
+		return "", tp.ErrInvalidOptions
+	}
+	diffOpt := &gl.GetCommitDiffOptions{ListOptions: gl.ListOptions{PerPage: 100}}
+	var diff strings.Builder
+	for {
+		files, response, err := s.client.Commits.GetCommitDiff(opt.Repo, opt.SHA, diffOpt, gl.WithContext(ctx))
+		if err != nil {
+			if isNotFound(err) {
+				return "", tp.NotFound
+			}
+			logrus.Debugf("Get Commit Diff Error: %+v", err)
+			return "", err
+		}
+		for _, f := range files {
+			oldPath, newPath := "a/"+f.OldPath, "b/"+f.NewPath
+			if f.NewFile {
+				oldPath = "/dev/null"
+			}
+			if f.DeletedFile {
+				newPath = "/dev/null"
+			}
+			fmt.Fprintf(&diff, "diff --git a/%s b/%s\n--- %s\n+++ %s\n%s", f.OldPath, f.NewPath, oldPath, newPath, f.Diff)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		diffOpt.Page = response.NextPage
+	}
+	return diff.String(), nil
+}
+
+// Create Commit creates a new commit.
+// GitLab has no git data API to create a commit from a tree, so the commit is created by
+// cherry-picking SHA onto Target with PickMessage.
+func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption) (tp.Commit, error) {
+	if opt == nil || opt.SHA == "" || opt.Target == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Commit Opt: %+v", *opt)
+	warnCommitter(opt.Committer, opt.DateMode)
+	pickOpt := &gl.CherryPickCommitOptions{
+		Branch: gl.Ptr(branchName(opt.Target)),
+	}
+	if opt.PickMessage != "" {
+		pickOpt.Message = gl.Ptr(opt.PickMessage)
+	}
+	commit, _, err := s.client.Commits.CherryPickCommit(opt.Repo, opt.SHA, pickOpt, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Errorf("Create Commit Error: %v", err)
+		return nil, pickError(err)
+	}
+	return newCommit(commit), nil
+}
+
+// IsAncestor reports whether the commit is the merge base of itself and the branch
+func (s *CommitService) IsAncestor(ctx context.Context, opt *tp.AncestorOption) (bool, error) {
+	if opt == nil {
+		return false, tp.ErrInvalidOptions
+	}
+	base, _, err := s.client.Repositories.MergeBase(opt.Repo, &gl.MergeBaseOptions{Ref: &[]string{opt.SHA, opt.Branch}}, gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return false, tp.NotFound
+		}
+		logrus.Errorf("Merge base of %s and %s: %v", opt.SHA, opt.Branch, err)
+		return false, err
+	}
+	return base.ID == opt.SHA, nil
+}
+
+// CheckConflict check conflict with the cherry-pick dry run API
+func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflictOption) error {
+	if opts == nil {
+		return tp.ErrInvalidOptions
+	}
+	if opts.Mode != tp.WithAPI {
+		return errors.New("not support check conflict with command")
+	}
+
+	_, _, err := s.client.Commits.CherryPickCommit(opts.Repo, opts.Commit, &gl.CherryPickCommitOptions{
+		Branch: gl.Ptr(branchName(opts.Target)),
+		DryRun: gl.Ptr(true),
+	}, gl.WithContext(ctx))
+	if err != nil {
+		logrus.Warnf("Check conflict %s to %s: %v", opts.Commit, opts.Target, err)
+		return pickError(err)
+	}
+	return nil
+}
+
+func newCommit(commit *gl.Commit) *Commit {
+	if commit == nil {
+		return nil
+	}
+	return &Commit{
+		sha:     commit.ID,
+		message: commit.Message,
+		author:  tp.Signature{Name: commit.AuthorName, Email: commit.AuthorEmail},
+		parents: commit.ParentIDs,
+	}
+}
+
+// SHA Commit returns the commit sha.
+func (c *Commit) SHA() string {
+	return c.sha
+}
+
+// Tree GitLab does not expose the tree of a commit.
+func (c *Commit) Tree() tp.Tree {
+	return nil
+}
+
+func (c *Commit) Message() string {
+	return c.message
+}
+
+func (c *Commit) Author() tp.Signature {
+	return c.author
+}
+
+// Parents returns the shas of the parents, the first parent first
+func (c *Commit) Parents() []string {
+	return c.parents
+}
@@ -0,0 +1,64 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"testing"
+)
+
+func TestCommitService_Get(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"abc","message":"fix: bug"}`)
+	})
+	commit, err := NewCommitService(client).Get(context.Background(), &tp.GetCommitOption{Repo: "g/p", SHA: "abc"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if commit.SHA() != "abc" || commit.Message() != "fix: bug" {
+		t.Fatalf("commit: %+v", commit)
+	}
+}
+
+func TestCommitService_CheckConflict(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/abc/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"message":"Sorry, we cannot cherry-pick this commit automatically."}`)
+	})
+	err := NewCommitService(client).CheckConflict(context.Background(), &tp.CheckConflictOption{
+		Repo:   "g/p",
+		Commit: "abc",
+		Target: "main",
+		Mode:   tp.WithAPI,
+	})
+	if err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestCommitService_ListDiff(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref_name") != "release/1.0" {
+			t.Errorf("ref_name: %s", r.URL.Query().Get("ref_name"))
+		}
+		fmt.Fprint(w, `[{"id":"bbb","message":"fix: bug"},{"id":"aaa","message":"init"}]`)
+	})
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/bbb/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"old_path":"b.txt","new_path":"b.txt","new_file":true,"diff":"@@ -0,0 +1 @@\n+b\n"}]`)
+	})
+	service := NewCommitService(client)
+
+	commits, err := service.List(context.Background(), &tp.ListCommitOption{Repo: "g/p", Branch: "refs/heads/release/1.0", Limit: 1})
+	if err != nil || len(commits) != 1 || commits[0].SHA() != "bbb" {
+		t.Fatalf("err: %v commits: %+v", err, commits)
+	}
+	diff, err := service.Diff(context.Background(), &tp.GetCommitOption{Repo: "g/p", SHA: "bbb"})
+	want := "diff --git a/b.txt b/b.txt\n--- /dev/null\n+++ b/b.txt\n@@ -0,0 +1 @@\n+b\n"
+	if err != nil || diff != want {
+		t.Fatalf("err: %v diff: %q", err, diff)
+	}
+}
@@ -0,0 +1,57 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	tp "github.com/kentio/norn/pkg/types"
+	gl "github.com/xanzy/go-gitlab"
+	"net/http"
+	"strings"
+)
+
+// NewGitlabClient returns a new GitLab client, BaseUrl is used for self-hosted instances.
+func NewGitlabClient(opt *tp.CreateProviderOption) (*gl.Client, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	var options []gl.ClientOptionFunc
+	if opt.BaseUrl != nil && *opt.BaseUrl != "" {
+		options = append(options, gl.WithBaseURL(*opt.BaseUrl))
+	}
+	return gl.NewClient(opt.Token, options...)
+}
+
+// branchName trims the ref prefix, "refs/heads/main" and "heads/main" both return "main"
+func branchName(ref string) string {
+	ref = strings.TrimPrefix(ref, "refs/")
+	return strings.TrimPrefix(ref, "heads/")
+}
+
+// statusCode returns the http status code of the gitlab error, 0 if unknown
+func statusCode(err error) int {
+	var e *gl.ErrorResponse
+	if errors.As(err, &e) && e.Response != nil {
+		return e.Response.StatusCode
+	}
+	return 0
+}
+
+// errorCode returns the error_code of the response body, empty if there is none
+func errorCode(err error) string {
+	var e *gl.ErrorResponse
+	if !errors.As(err, &e) {
+		return ""
+	}
+	body := struct {
+		ErrorCode string `json:"error_code"`
+	}{}
+	if json.Unmarshal(e.Body, &body) != nil {
+		return ""
+	}
+	return body.ErrorCode
+}
+
+// isNotFound check if the error is a 404 response
+func isNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
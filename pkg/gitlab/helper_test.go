@@ -0,0 +1,43 @@
+package gitlab
+
+import (
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	gl "github.com/xanzy/go-gitlab"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setup starts a fake GitLab API server, handlers are registered on the returned mux
+func setup(t *testing.T) (*http.ServeMux, *gl.Client) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := gl.NewClient("", gl.WithBaseURL(server.URL), gl.WithoutRetries())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return mux, client
+}
+
+func TestBranchName(t *testing.T) {
+	for _, ref := range []string{"refs/heads/release/1.0", "heads/release/1.0", "release/1.0"} {
+		if name := branchName(ref); name != "release/1.0" {
+			t.Errorf("branchName(%s) = %s, want release/1.0", ref, name)
+		}
+	}
+}
+
+func TestPickError(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/commits/abc/cherry_pick", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"message":"Sorry, we cannot cherry-pick this commit automatically."}`)
+	})
+	_, _, err := client.Commits.CherryPickCommit("g/p", "abc", nil)
+	if pickError(err) != tp.ErrConflict {
+		t.Fatalf("pickError() = %v, want conflict", err)
+	}
+}
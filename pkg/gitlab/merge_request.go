@@ -0,0 +1,163 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+	"net/http"
+	"strconv"
+)
+
+type MergeRequestService struct {
+	client *gl.Client
+}
+
+type MergeRequest struct {
+	iid         int
+	title       string
+	description string
+	state       tp.MergeRequestState
+	labels      []string
+	webUrl      string
+}
+
+func (s *MergeRequest) MergeId() string {
+	return strconv.Itoa(s.iid)
+}
+
+func (s *MergeRequest) Title() string {
+	return s.title
+}
+
+func (s *MergeRequest) Description() string {
+	return s.description
+}
+
+func (s *MergeRequest) State() tp.MergeRequestState {
+	return s.state
+}
+
+func (s *MergeRequest) Labels() []string {
+	return s.labels
+}
+
+func (s *MergeRequest) WebUrl() string {
+	return s.webUrl
+}
+
+func NewMergeRequestService(client *gl.Client) *MergeRequestService {
+	return &MergeRequestService{
+		client: client,
+	}
+}
+
+func (s *MergeRequestService) Get(ctx context.Context, opt *tp.GetMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Get Merge Request Opt: %+v", *opt)
+
+	mergeId, err := strconv.Atoi(opt.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+	mr, _, err := s.client.MergeRequests.GetMergeRequest(opt.Repo, mergeId, nil, gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Get MR Error: %+v", err)
+		return nil, err
+	}
+	return newMergeRequest(mr), nil
+}
+
+// ListCommits returns the commits of the merge request, GitLab lists them newest first so they are reversed
+func (s *MergeRequestService) ListCommits(ctx context.Context, opt *tp.ListMergeRequestCommitsOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	mergeId, err := strconv.Atoi(opt.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merge id to int: %v", err)
+	}
+
+	var commits []tp.Commit
+	listOpt := &gl.GetMergeRequestCommitsOptions{PerPage: 100}
+	for {
+		page, response, err := s.client.MergeRequests.GetMergeRequestCommits(opt.Repo, mergeId, listOpt, gl.WithContext(ctx))
+		if err != nil {
+			if isNotFound(err) {
+				return nil, tp.NotFound
+			}
+			logrus.Errorf("List MR Commits Error: %+v", err)
+			return nil, err
+		}
+		for _, c := range page {
+			commits = append(commits, newCommit(c))
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		listOpt.Page = response.NextPage
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// Create opens a merge request from the head branch to the base branch
+func (s *MergeRequestService) Create(ctx context.Context, opt *tp.CreateMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Merge Request Opt: %+v", *opt)
+
+	createOpt := &gl.CreateMergeRequestOptions{
+		Title:        gl.Ptr(opt.Title),
+		Description:  gl.Ptr(opt.Description),
+		SourceBranch: gl.Ptr(branchName(opt.Head)),
+		TargetBranch: gl.Ptr(branchName(opt.Base)),
+	}
+	if len(opt.Labels) > 0 {
+		labels := gl.LabelOptions(opt.Labels)
+		createOpt.Labels = &labels
+	}
+	mr, _, err := s.client.MergeRequests.CreateMergeRequest(opt.Repo, createOpt, gl.WithContext(ctx))
+	if err != nil {
+		// GitLab responds 409 "Another open merge request already exists for this source branch"
+		if statusCode(err) == http.StatusConflict {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create MR Error: %+v", err)
+		return nil, err
+	}
+	return newMergeRequest(mr), nil
+}
+
+func newMergeRequest(mr *gl.MergeRequest) *MergeRequest {
+	return &MergeRequest{
+		iid:         mr.IID,
+		title:       mr.Title,
+		description: mr.Description,
+		state:       getStateFromGitlabMergeRequestState(mr.State),
+		labels:      mr.Labels,
+		webUrl:      mr.WebURL,
+	}
+}
+
+func getStateFromGitlabMergeRequestState(state string) tp.MergeRequestState {
+	switch state {
+	case "opened", "locked":
+		return tp.MergeRequestStateOpen
+	case "closed":
+		return tp.MergeRequestStateClosed
+	case "merged":
+		return tp.MergeRequestStateMerged
+	default:
+		return tp.MergeRequestStateUnknown
+	}
+}
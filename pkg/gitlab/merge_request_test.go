@@ -0,0 +1,76 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"testing"
+)
+
+func TestMergeRequestService_Get(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/merge_requests/3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"iid":3,"title":"fix","description":"desc","state":"merged"}`)
+	})
+	mr, err := NewMergeRequestService(client).Get(context.Background(), &tp.GetMergeRequestOption{Repo: "g/p", MergeID: "3"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if mr.MergeId() != "3" || mr.State() != tp.MergeRequestStateMerged {
+		t.Fatalf("mr: %+v state %s", mr, mr.State())
+	}
+}
+
+func TestMergeRequestService_ListCommits(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/merge_requests/3/commits", func(w http.ResponseWriter, r *http.Request) {
+		// GitLab lists the newest commit first
+		fmt.Fprint(w, `[{"id":"bbb","message":"second"},{"id":"aaa","message":"first"}]`)
+	})
+	commits, err := NewMergeRequestService(client).ListCommits(context.Background(), &tp.ListMergeRequestCommitsOption{Repo: "g/p", MergeID: "3"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(commits) != 2 || commits[0].SHA() != "aaa" || commits[1].SHA() != "bbb" {
+		t.Fatalf("commits: %+v", commits)
+	}
+}
+
+func TestMergeRequestService_Create(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("POST /api/v4/projects/g%2Fp/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["source_branch"] != "backport/3-main" || body["target_branch"] != "main" || body["labels"] != "bug" {
+			t.Errorf("body: %v", body)
+		}
+		fmt.Fprint(w, `{"iid":4,"title":"fix","state":"opened","labels":["bug"],"web_url":"https://gitlab.com/g/p/-/merge_requests/4"}`)
+	})
+	mr, err := NewMergeRequestService(client).Create(context.Background(), &tp.CreateMergeRequestOption{
+		Repo:   "g/p",
+		Title:  "fix",
+		Head:   "backport/3-main",
+		Base:   "main",
+		Labels: []string{"bug"},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if mr.MergeId() != "4" || mr.WebUrl() != "https://gitlab.com/g/p/-/merge_requests/4" || len(mr.Labels()) != 1 {
+		t.Fatalf("mr: %+v", mr)
+	}
+}
+
+func TestMergeRequestService_CreateExists(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("POST /api/v4/projects/g%2Fp/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"message":["Another open merge request already exists for this source branch: !4"]}`)
+	})
+	_, err := NewMergeRequestService(client).Create(context.Background(), &tp.CreateMergeRequestOption{Repo: "g/p", Head: "backport/3-main", Base: "main"})
+	if err != tp.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
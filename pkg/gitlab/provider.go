@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+)
+
+type Provider struct {
+	providerID tp.ProviderType
+	client     *gl.Client
+
+	commitService       *CommitService
+	referenceService    *ReferenceService
+	mergeRequestService *MergeRequestService
+	commentService      *CommentService
+	pickService         *PickService
+	repositoryService   *RepositoryService
+}
+
+func NewProvider(ctx context.Context, opt *tp.CreateProviderOption) (*Provider, error) {
+	client, err := NewGitlabClient(opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Signer != nil {
+		logrus.Warnf("The signing key is ignored, GitLab signs the commits by the key of the instance when it is configured")
+	}
+	return NewProviderWithClient(client), nil
+}
+
+// NewProviderWithClient creates a new provider with the given client.
+func NewProviderWithClient(client *gl.Client) *Provider {
+	return &Provider{
+		providerID:          tp.GitlabProvider,
+		client:              client,
+		commitService:       NewCommitService(client),
+		referenceService:    NewReferenceService(client),
+		mergeRequestService: NewMergeRequestService(client),
+		commentService:      NewCommentService(client),
+		pickService:         NewPickService(client),
+		repositoryService:   NewRepositoryService(client),
+	}
+}
+
+func (p *Provider) Commit() tp.CommitService {
+	return p.commitService
+}
+
+func (p *Provider) Reference() tp.ReferenceService {
+	return p.referenceService
+}
+
+func (p *Provider) MergeRequest() tp.MergeRequestService {
+	return p.mergeRequestService
+}
+
+func (p *Provider) Comment() tp.CommentService {
+	return p.commentService
+}
+
+func (p *Provider) Repository() tp.RepositoryService {
+	return p.repositoryService
+}
+
+func (p *Provider) ProviderID() tp.ProviderType {
+	return p.providerID
+}
+
+func (p *Provider) Pick() tp.PickService {
+	return p.pickService
+}
@@ -0,0 +1,21 @@
+package gitlab
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	baseUrl := "https://gitlab.example.com/api/v4"
+	provider, err := NewProvider(context.Background(), &tp.CreateProviderOption{Token: "", BaseUrl: &baseUrl})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.ProviderID() != tp.GitlabProvider {
+		t.Fatalf("provider id = %s, want %s", provider.ProviderID(), tp.GitlabProvider)
+	}
+	if provider.client.BaseURL().String() != baseUrl+"/" {
+		t.Fatalf("base url = %s", provider.client.BaseURL())
+	}
+}
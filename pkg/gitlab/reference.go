@@ -0,0 +1,127 @@
+package gitlab
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	gl "github.com/xanzy/go-gitlab"
+	"net/http"
+	"strings"
+)
+
+type ReferenceService struct {
+	client *gl.Client
+}
+
+func NewReferenceService(client *gl.Client) *ReferenceService {
+	return &ReferenceService{
+		client: client,
+	}
+}
+
+// Get reference, only branches are supported
+func (s *ReferenceService) Get(ctx context.Context, opt *tp.GetRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Get Reference Opt: %+v", opt)
+
+	branch, _, err := s.client.Branches.GetBranch(opt.Repo, branchName(opt.Ref), gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Get Reference Error: %v", err)
+		return nil, err
+	}
+	return newBranch(branch), nil
+}
+
+// Update GitLab does not support moving a branch to an arbitrary commit.
+func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	return nil, tp.ErrNotSupported
+}
+
+// Create creates the branch at the commit
+func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Reference Opt: %+v", opt)
+
+	branch, _, err := s.client.Branches.CreateBranch(opt.Repo, &gl.CreateBranchOptions{
+		Branch: gl.Ptr(branchName(opt.Ref)),
+		Ref:    gl.Ptr(opt.SHA),
+	}, gl.WithContext(ctx))
+	if err != nil {
+		// GitLab responds 400 "Branch already exists"
+		if statusCode(err) == http.StatusBadRequest {
+			return nil, tp.ErrAlreadyExists
+		}
+		logrus.Errorf("Create Reference Error: %v", err)
+		return nil, err
+	}
+	return newBranch(branch), nil
+}
+
+// List returns the branches whose name starts with the prefix
+func (s *ReferenceService) List(ctx context.Context, opt *tp.ListRefOption) ([]*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("List Reference Opt: %+v", opt)
+
+	listOpt := &gl.ListBranchesOptions{ListOptions: gl.ListOptions{PerPage: 100}}
+	if opt.Prefix != "" {
+		listOpt.Search = gl.Ptr("^" + opt.Prefix)
+	}
+	var refs []*tp.Reference
+	for {
+		branches, response, err := s.client.Branches.ListBranches(opt.Repo, listOpt, gl.WithContext(ctx))
+		if err != nil {
+			logrus.Errorf("List Reference Error: %v", err)
+			return nil, err
+		}
+		for _, branch := range branches {
+			// the search is case insensitive
+			if strings.HasPrefix(branch.Name, opt.Prefix) {
+				refs = append(refs, newBranch(branch))
+			}
+		}
+		if response.NextPage == 0 {
+			return refs, nil
+		}
+		listOpt.Page = response.NextPage
+	}
+}
+
+// Delete deletes the branch
+func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteRefOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Delete Reference Opt: %+v", opt)
+
+	_, err := s.client.Branches.DeleteBranch(opt.Repo, branchName(opt.Ref), gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return tp.NotFound
+		}
+		logrus.Errorf("Delete Reference Error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func newBranch(branch *gl.Branch) *tp.Reference {
+	ref := &tp.Reference{
+		Ref: "refs/heads/" + branch.Name,
+	}
+	if branch.Commit != nil {
+		ref.SHA = branch.Commit.ID
+	}
+	return ref
+}
@@ -0,0 +1,82 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"testing"
+)
+
+func TestReferenceService_Get(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"main","commit":{"id":"abc"}}`)
+	})
+	ref, err := NewReferenceService(client).Get(context.Background(), &tp.GetRefOption{Repo: "g/p", Ref: "heads/main"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ref.Ref != "refs/heads/main" || ref.SHA != "abc" {
+		t.Fatalf("reference: %+v", ref)
+	}
+}
+
+func TestReferenceService_Create(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("POST /api/v4/projects/g%2Fp/repository/branches", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["branch"] == "main" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message":"Branch already exists"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"name":%q,"commit":{"id":%q}}`, body["branch"], body["ref"])
+	})
+	service := NewReferenceService(client)
+
+	ref, err := service.Create(context.Background(), &tp.CreateRefOption{Repo: "g/p", Ref: "refs/heads/backport/1-main", SHA: "abc"})
+	if err != nil || ref.Ref != "refs/heads/backport/1-main" || ref.SHA != "abc" {
+		t.Fatalf("err: %v ref: %+v", err, ref)
+	}
+	if _, err = service.Create(context.Background(), &tp.CreateRefOption{Repo: "g/p", Ref: "main", SHA: "abc"}); err != tp.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
+
+func TestReferenceService_ListDelete(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("GET /api/v4/projects/g%2Fp/repository/branches", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("search") != "^norn/tmp/" {
+			t.Errorf("search: %s", r.URL.Query().Get("search"))
+		}
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"name":"norn/tmp/20240102T030405Z-1a2b3c4d/pick-main-012345678","commit":{"id":"abc"}}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"name":"Norn/tmp/other","commit":{"id":"def"}}]`)
+	})
+	mux.HandleFunc("DELETE /api/v4/projects/g%2Fp/repository/branches/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/g%2Fp/repository/branches/norn%2Ftmp%2Frun%2Fpick-main-012345678" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Branch Not Found"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	service := NewReferenceService(client)
+
+	refs, err := service.List(context.Background(), &tp.ListRefOption{Repo: "g/p", Prefix: tp.TempRefPrefix})
+	if err != nil || len(refs) != 1 || refs[0].Ref != "refs/heads/norn/tmp/20240102T030405Z-1a2b3c4d/pick-main-012345678" {
+		t.Fatalf("err: %v refs: %+v", err, refs)
+	}
+	if err = service.Delete(context.Background(), &tp.DeleteRefOption{Repo: "g/p", Ref: "refs/heads/norn/tmp/run/pick-main-012345678"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err = service.Delete(context.Background(), &tp.DeleteRefOption{Repo: "g/p", Ref: "refs/heads/missing"}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
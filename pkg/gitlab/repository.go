@@ -0,0 +1,88 @@
+package gitlab
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	gl "github.com/xanzy/go-gitlab"
+)
+
+type Repository struct {
+	name                string
+	fullName            string
+	gitUrl              string
+	defaultBranch       string
+	allowSquashMerge    *bool
+	deleteBranchOnMerge *bool
+	allowRebaseMerge    *bool
+	private             *bool
+}
+
+type RepositoryService struct {
+	client *gl.Client
+}
+
+func NewRepositoryService(client *gl.Client) *RepositoryService {
+	return &RepositoryService{
+		client: client,
+	}
+}
+
+func (r *RepositoryService) Get(ctx context.Context, opt *tp.GetRepositoryOption) (tp.Repository, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+
+	project, _, err := r.client.Projects.GetProject(opt.Repo, nil, gl.WithContext(ctx))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, tp.NotFound
+		}
+		return nil, err
+	}
+	return newRepository(project), nil
+}
+
+func newRepository(project *gl.Project) *Repository {
+	return &Repository{
+		name:                project.Name,
+		fullName:            project.PathWithNamespace,
+		gitUrl:              project.HTTPURLToRepo,
+		defaultBranch:       project.DefaultBranch,
+		allowSquashMerge:    gl.Ptr(project.SquashOption != gl.SquashOptionNever),
+		deleteBranchOnMerge: gl.Ptr(project.RemoveSourceBranchAfterMerge),
+		allowRebaseMerge:    gl.Ptr(project.MergeMethod == gl.RebaseMerge),
+		private:             gl.Ptr(project.Visibility == gl.PrivateVisibility),
+	}
+}
+
+func (r *Repository) Name() string {
+	return r.name
+}
+
+func (r *Repository) FullName() string {
+	return r.fullName
+}
+
+func (r *Repository) GitUrl() string {
+	return r.gitUrl
+}
+
+func (r *Repository) DefaultBranch() string {
+	return r.defaultBranch
+}
+
+func (r *Repository) AllowSquashMerge() *bool {
+	return r.allowSquashMerge
+}
+
+func (r *Repository) DeleteBranchOnMerge() *bool {
+	return r.deleteBranchOnMerge
+}
+
+func (r *Repository) AllowRebaseMerge() *bool {
+	return r.allowRebaseMerge
+}
+
+func (r *Repository) Private() *bool {
+	return r.private
+}
@@ -0,0 +1,23 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"testing"
+)
+
+func TestRepositoryService_Get(t *testing.T) {
+	mux, client := setup(t)
+	mux.HandleFunc("/api/v4/projects/g%2Fp", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"p","path_with_namespace":"g/p","default_branch":"main","visibility":"private","merge_method":"merge"}`)
+	})
+	repo, err := NewRepositoryService(client).Get(context.Background(), &tp.GetRepositoryOption{Repo: "g/p"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if repo.FullName() != "g/p" || repo.DefaultBranch() != "main" || !*repo.Private() || *repo.AllowRebaseMerge() {
+		t.Fatalf("repo: %+v", repo)
+	}
+}
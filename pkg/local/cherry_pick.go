@@ -0,0 +1,214 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type PickService struct {
+	git    *Git
+	signer tp.Signer // signs the pick and revert commits, unsigned if nil
+	// strategyOptions are passed to git cherry-pick and git revert as -X, such as "find-renames=30%"
+	strategyOptions []string
+}
+
+func NewPickService(git *Git) *PickService {
+	return &PickService{
+		git: git,
+	}
+}
+
+// Pick cherry-pick the commit onto the branch in a temporary worktree,
+// the branch is only moved if nobody updated it in the meantime.
+func (c *PickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return c.apply(ctx, opt, false)
+}
+
+// Revert reverts the commit on the branch in a temporary worktree like Pick, the revert is
+// committed as the committer of the commit.
+func (c *PickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return c.apply(ctx, opt, true)
+}
+
+// apply cherry-picks or reverts the commit onto the branch
+func (c *PickService) apply(ctx context.Context, opt *tp.PickOption, revert bool) (*tp.PickResult, error) {
+	if opt == nil || opt.SHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Branch)
+	target, result, err := c.commit(ctx, ref, opt, revert)
+	if err != nil || opt.DryRun {
+		return result, err
+	}
+	// compare-and-swap, fails if the branch is not at target anymore
+	if _, err = c.git.Run(ctx, "update-ref", ref, result.SHA, target); err != nil {
+		logrus.Errorf("update target branch error %s: %v", ref, err)
+		return nil, tp.ErrStaleRef
+	}
+	return result, nil
+}
+
+// commit creates the pick or the revert of the commit on top of ref, returns the commit ref is at.
+// The ref is not moved, only the tree is computed in dry run.
+func (c *PickService) commit(ctx context.Context, ref string, opt *tp.PickOption, revert bool) (string, *tp.PickResult, error) {
+	target, err := c.git.ResolveCommit(ctx, ref)
+	if err != nil {
+		return "", nil, tp.NotFound
+	}
+	source, err := readCommit(ctx, c.git, opt.SHA)
+	if err != nil {
+		logrus.Errorf("Get source commit %s: %v", opt.SHA, err)
+		return "", nil, err
+	}
+
+	worktree, err := addWorktree(ctx, c.git, target)
+	if err != nil {
+		return "", nil, err
+	}
+	defer worktree.Remove(ctx)
+	worktree.strategyOptions = c.strategyOptions
+
+	command, message, identity := "cherry-pick", opt.Message, source.pickIdentity(opt.Committer, opt.DateMode)
+	if message == "" {
+		message = source.Message
+	}
+	// commits already picked are found by the trailer, it is kept whatever the message
+	message = tp.PickMessage(source.SHA, message)
+	if revert {
+		command, message, identity = "revert", tp.RevertMessage(source.SHA, source.Message), source.revertIdentity()
+	}
+	tree, err := worktree.Apply(ctx, command, source, opt.Mainline)
+	if err != nil {
+		return "", nil, err
+	}
+	// the change is already on the branch, the pick would be an empty commit
+	if targetTree, err := c.git.Run(ctx, "rev-parse", target+"^{tree}"); err == nil && targetTree == tree {
+		return "", nil, tp.ErrEmptyPick
+	}
+	if opt.DryRun {
+		return target, &tp.PickResult{Tree: tree}, nil
+	}
+
+	newCommit, err := c.git.RunIn(ctx, c.git.Path, identity, "commit-tree", tree, "-p", target, "-m", message)
+	if err != nil {
+		logrus.Errorf("creating %s commit: %v", command, err)
+		return "", nil, err
+	}
+	if c.signer != nil {
+		if newCommit, err = c.git.SignCommit(ctx, c.signer, newCommit); err != nil {
+			return "", nil, err
+		}
+	}
+	return target, &tp.PickResult{SHA: newCommit, Tree: tree}, nil
+}
+
+// Worktree is a temporary detached worktree of the clone
+type Worktree struct {
+	git *Git
+	dir string
+	tmp string
+	// strategyOptions are passed to git cherry-pick and git revert as -X
+	strategyOptions []string
+}
+
+func addWorktree(ctx context.Context, git *Git, commit string) (*Worktree, error) {
+	tmp, err := os.MkdirTemp("", "norn-pick-")
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(tmp, "worktree")
+	if _, err = git.Run(ctx, "worktree", "add", "--detach", dir, commit); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, err
+	}
+	return &Worktree{git: git, dir: dir, tmp: tmp}, nil
+}
+
+// CherryPick applies the commit to the index of the worktree, returns the resulting tree.
+// The mainline selects the parent of a merge commit, see types.PickOption.
+func (w *Worktree) CherryPick(ctx context.Context, source *commitInfo, mainline int) (string, error) {
+	return w.Apply(ctx, "cherry-pick", source, mainline)
+}
+
+// Apply runs git cherry-pick or git revert of the commit without committing, returns the resulting tree
+func (w *Worktree) Apply(ctx context.Context, command string, source *commitInfo, mainline int) (string, error) {
+	parent, err := tp.MainlineParent(len(source.Parents), mainline)
+	if err != nil {
+		return "", err
+	}
+	args := []string{command, "--no-commit"}
+	if len(source.Parents) > 1 {
+		args = append(args, "-m", strconv.Itoa(parent+1))
+	}
+	for _, option := range w.strategyOptions {
+		args = append(args, "-X", option)
+	}
+	_, err = w.git.RunIn(ctx, w.dir, source.identity(), append(args, source.SHA)...)
+	if err != nil {
+		logrus.Warnf("%s %s conflict: %v", command, source.SHA, err)
+		_, _ = w.git.RunIn(ctx, w.dir, nil, command, "--abort")
+		return "", tp.ErrConflict
+	}
+	return w.git.RunIn(ctx, w.dir, nil, "write-tree")
+}
+
+// Remove removes the worktree and its temporary directory
+func (w *Worktree) Remove(ctx context.Context) {
+	if _, err := w.git.Run(ctx, "worktree", "remove", "--force", w.dir); err != nil {
+		logrus.Warnf("Failed to remove worktree %s: %v", w.dir, err)
+	}
+	if err := os.RemoveAll(w.tmp); err != nil {
+		logrus.Warnf("Failed to remove %s: %v", w.tmp, err)
+	}
+}
+
+// identity returns the environment to keep the author and committer of the commit
+func (c *commitInfo) identity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=" + c.AuthorName,
+		"GIT_AUTHOR_EMAIL=" + c.AuthorEmail,
+		"GIT_AUTHOR_DATE=" + c.AuthorDate,
+		"GIT_COMMITTER_NAME=" + c.CommitterName,
+		"GIT_COMMITTER_EMAIL=" + c.CommitterEmail,
+	}
+}
+
+// pickIdentity returns the environment to keep the author of the commit, the committer is the
+// committer of the commit if committer is nil. The committer date is set by the mode.
+func (c *commitInfo) pickIdentity(committer *tp.Signature, mode tp.DateMode) []string {
+	env := []string{
+		"GIT_AUTHOR_NAME=" + c.AuthorName,
+		"GIT_AUTHOR_EMAIL=" + c.AuthorEmail,
+		"GIT_AUTHOR_DATE=" + c.AuthorDate,
+	}
+	if committer == nil {
+		committer = &tp.Signature{Name: c.CommitterName, Email: c.CommitterEmail}
+	}
+	env = append(env, committerIdentity(committer)...)
+	if mode == tp.DateOriginal {
+		env = append(env, "GIT_COMMITTER_DATE="+c.CommitterDate)
+	}
+	return env
+}
+
+// committerIdentity returns the environment to commit as the committer
+func committerIdentity(committer *tp.Signature) []string {
+	return []string{
+		"GIT_COMMITTER_NAME=" + committer.Name,
+		"GIT_COMMITTER_EMAIL=" + committer.Email,
+	}
+}
+
+// revertIdentity returns the environment to author the revert as the committer of the commit
+func (c *commitInfo) revertIdentity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=" + c.CommitterName,
+		"GIT_AUTHOR_EMAIL=" + c.CommitterEmail,
+		"GIT_COMMITTER_NAME=" + c.CommitterName,
+		"GIT_COMMITTER_EMAIL=" + c.CommitterEmail,
+	}
+}
@@ -0,0 +1,272 @@
+package local
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"github.com/kentio/norn/pkg/sign"
+	tp "github.com/kentio/norn/pkg/types"
+	"golang.org/x/crypto/ssh"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPickService_Pick(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	sha := repo.commit("master", "b.txt", "new file\n", "feat: add b")
+	ctx := context.Background()
+
+	result, err := NewPickService(repo.git).Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != repo.run("rev-parse", "r1") || result.Tree != repo.run("rev-parse", "r1^{tree}") {
+		t.Fatalf("result: %+v", result)
+	}
+	if content := repo.show("r1", "b.txt"); content != "new file" {
+		t.Fatalf("content: %q", content)
+	}
+	message := repo.run("log", "-1", "--format=%B", "r1")
+	if !strings.HasSuffix(message, "(cherry picked from commit "+sha[:7]+")") {
+		t.Fatalf("message: %q", message)
+	}
+	if author := repo.run("log", "-1", "--format=%an", "r1"); author != "norn" {
+		t.Fatalf("author: %s", author)
+	}
+	// the worktree is removed
+	if worktrees := repo.run("worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Fatalf("worktrees: %s", worktrees)
+	}
+}
+
+func TestPickService_PickMessage(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.branch("r2", "master")
+	sha := repo.commit("master", "b.txt", "new file\n", "feat: add b")
+
+	// the trailer is appended to a message without it, commits already picked are found by it
+	message := "feat: add b\n\nBackport-Of: " + sha
+	_, err := NewPickService(repo.git).Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1", Message: message})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := repo.run("log", "-1", "--format=%B", "r1"); got != message+"\n\n(cherry picked from commit "+sha[:7]+")" {
+		t.Fatalf("message: %q", got)
+	}
+	// a message with the trailer is kept
+	message = "feat: add b\n\n(cherry picked from commit " + sha + ")"
+	if _, err = NewPickService(repo.git).Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r2", Message: message}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := repo.run("log", "-1", "--format=%B", "r2"); got != message {
+		t.Fatalf("message: %q", got)
+	}
+	commit, err := NewCommitService(repo.git).Get(context.Background(), &tp.GetCommitOption{SHA: sha})
+	if err != nil || commit.Author().Name != "norn" {
+		t.Fatalf("err: %v author: %+v", err, commit.Author())
+	}
+}
+
+func TestPickService_PickCommitter(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.commit("master", "b.txt", "new file\n", "feat: add b")
+	// commit the source in the past
+	env := append([]string{"GIT_COMMITTER_DATE=2024-01-02T03:04:05Z"}, testIdentity...)
+	if _, err := repo.git.RunIn(ctx, repo.git.Path, env, "commit", "--amend", "-q", "--no-edit"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	sha := repo.run("rev-parse", "master")
+
+	_, err := NewPickService(repo.git).Pick(ctx, "", &tp.PickOption{
+		SHA:       sha,
+		Branch:    "r1",
+		Committer: &tp.Signature{Name: "bot", Email: "bot@example.com"},
+		DateMode:  tp.DateOriginal,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := repo.run("log", "-1", "--format=%an <%ae> %cn <%ce> %cI", "r1"); got != "norn <norn@example.com> bot <bot@example.com> 2024-01-02T03:04:05+00:00" {
+		t.Fatalf("identity: %s", got)
+	}
+
+	// the committer of the source is kept with the current date by default
+	repo.run("update-ref", "refs/heads/r1", "r1~1")
+	if _, err = NewPickService(repo.git).Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := repo.run("log", "-1", "--format=%cn %cI", "r1"); got == "norn 2024-01-02T03:04:05+00:00" || !strings.HasPrefix(got, "norn ") {
+		t.Fatalf("committer: %s", got)
+	}
+}
+
+func TestPickService_PickSigned(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	sha := repo.commit("master", "b.txt", "new file\n", "feat: add b")
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	block, _ := ssh.MarshalPrivateKey(private, "norn")
+	signer, err := sign.NewSigner(pem.EncodeToMemory(block), nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	provider := NewProviderWithGit(repo.git)
+	provider.SetSigner(signer)
+	result, err := provider.Pick().Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != repo.run("rev-parse", "r1") || repo.run("log", "-1", "--format=%s", "r1") != "feat: add b" {
+		t.Fatalf("result: %+v", result)
+	}
+
+	// git verifies the signature with the public key
+	key, _ := ssh.NewPublicKey(public)
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	if err = os.WriteFile(allowed, []byte("norn@example.com "+string(ssh.MarshalAuthorizedKey(key))), 0o600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	repo.run("-c", "gpg.format=ssh", "-c", "gpg.ssh.allowedSignersFile="+allowed, "verify-commit", "r1")
+}
+
+func TestPickService_PickDryRun(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	head := repo.run("rev-parse", "r1")
+	sha := repo.commit("master", "b.txt", "new file\n", "feat: add b")
+
+	result, err := NewPickService(repo.git).Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the tree of the pick is the tree of master, the branch is not moved
+	if result.SHA != "" || result.Tree != repo.run("rev-parse", "master^{tree}") {
+		t.Fatalf("result: %+v", result)
+	}
+	if repo.run("rev-parse", "r1") != head {
+		t.Fatalf("branch is changed")
+	}
+}
+
+func TestPickService_PickEmpty(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	head := repo.commit("r1", "b.txt", "new file\n", "feat: add b")
+	sha := repo.commit("master", "b.txt", "new file\n", "feat: add b")
+
+	_, err := NewPickService(repo.git).Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+	if repo.run("rev-parse", "r1") != head {
+		t.Fatalf("branch is changed")
+	}
+}
+
+func TestPickService_Revert(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	picked := repo.commit("r1", "b.txt", "new file\n", "feat: add b")
+	repo.commit("r1", "c.txt", "c\n", "feat: add c")
+	service := NewPickService(repo.git)
+	ctx := context.Background()
+
+	planned, err := service.Revert(ctx, "", &tp.PickOption{SHA: picked, Branch: "r1", DryRun: true})
+	if err != nil || planned.SHA != "" {
+		t.Fatalf("err: %v planned: %+v", err, planned)
+	}
+	result, err := service.Revert(ctx, "", &tp.PickOption{SHA: picked, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.SHA != repo.run("rev-parse", "r1") || result.Tree != planned.Tree {
+		t.Fatalf("result: %+v planned: %+v", result, planned)
+	}
+	if files := repo.run("ls-tree", "--name-only", "r1"); files != "a.txt\nc.txt" {
+		t.Fatalf("files: %q", files)
+	}
+	if message := repo.run("log", "-1", "--format=%B", "r1"); message != tp.RevertMessage(picked, "feat: add b") {
+		t.Fatalf("message: %q", message)
+	}
+
+	// reverted already
+	if _, err = service.Revert(ctx, "", &tp.PickOption{SHA: picked, Branch: "r1"}); err != tp.ErrEmptyPick {
+		t.Fatalf("err = %v, want empty pick", err)
+	}
+}
+
+func TestPickService_PickConflict(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.commit("r1", "a.txt", "a\nr1\nc\n", "fix on r1")
+	head := repo.run("rev-parse", "r1")
+	sha := repo.commit("master", "a.txt", "a\nmaster\nc\n", "fix on master")
+	service := NewPickService(repo.git)
+
+	_, err := service.Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if repo.run("rev-parse", "r1") != head {
+		t.Fatalf("branch is changed")
+	}
+
+	_, err = service.Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "missing"})
+	if err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestPickService_PickMergeCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.branch("feature", "master")
+	repo.commit("feature", "b.txt", "b\n", "add b")
+	repo.commit("master", "c.txt", "c\n", "add c")
+	repo.run("merge", "-q", "--no-ff", "-m", "Merge feature", "feature")
+	merge := repo.run("rev-parse", "HEAD")
+	ctx := context.Background()
+	service := NewPickService(repo.git)
+
+	if _, err := service.Pick(ctx, "", &tp.PickOption{SHA: merge, Branch: "r1"}); err != tp.ErrMainlineRequired {
+		t.Fatalf("err = %v, want mainline required", err)
+	}
+	if _, err := service.Pick(ctx, "", &tp.PickOption{SHA: merge, Branch: "r1", Mainline: 1}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if content := repo.show("r1", "b.txt"); content != "b" {
+		t.Fatalf("b.txt: %q", content)
+	}
+	if _, err := repo.git.Run(ctx, "cat-file", "-e", "r1:c.txt"); err == nil {
+		t.Fatalf("c.txt of the mainline is picked")
+	}
+}
+
+func TestPickService_PickStrategyOption(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.commit("r1", "a.txt", "a\nr1\nc\n", "fix on r1")
+	sha := repo.commit("master", "a.txt", "a\nmaster\nc\n", "fix on master")
+	provider := NewProviderWithGit(repo.git)
+	provider.SetStrategyOptions([]string{"theirs"})
+
+	if _, err := provider.Pick().Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if content := repo.show("r1", "a.txt"); content != "a\nmaster\nc" {
+		t.Fatalf("content: %q", content)
+	}
+}
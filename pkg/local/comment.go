@@ -0,0 +1,129 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"strconv"
+)
+
+// CommentService keeps comments in the notes file of the store
+type CommentService struct {
+	store *Store
+}
+
+type Comment struct {
+	commentId string
+	body      string
+}
+
+func NewCommentService(store *Store) *CommentService {
+	return &CommentService{
+		store: store,
+	}
+}
+
+// Create Comment creates a new comment on the given merge request, the merge request is created if not exists.
+func (s *CommentService) Create(ctx context.Context, opt *tp.CreateCommentOption) (tp.Comment, error) {
+	if opt == nil || opt.MergeRequestID == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	var comment *commentRecord
+	err := s.store.Update(ctx, func(data *storeData) error {
+		mr, ok := data.MergeRequests[opt.MergeRequestID]
+		if !ok {
+			mr = &mergeRequestRecord{State: tp.MergeRequestStateOpen.String()}
+			data.MergeRequests[opt.MergeRequestID] = mr
+		}
+		data.NextID++
+		comment = &commentRecord{ID: strconv.Itoa(data.NextID), Body: opt.Body}
+		mr.Comments = append(mr.Comments, comment)
+		return nil
+	})
+	if err != nil {
+		logrus.Warnf("Failed to add comment: %v", err)
+		return nil, err
+	}
+	return newComment(comment), nil
+}
+
+// Find Comment finds comments on the given merge request.
+func (s *CommentService) Find(ctx context.Context, opt *tp.FindCommentOption) ([]tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	var comments []tp.Comment
+	err := s.store.View(ctx, func(data *storeData) error {
+		if mr, ok := data.MergeRequests[opt.MergeRequestID]; ok {
+			for _, c := range mr.Comments {
+				comments = append(comments, newComment(c))
+			}
+		}
+		return nil
+	})
+	return comments, err
+}
+
+// Update Comment updates a comment on the given merge request.
+func (s *CommentService) Update(ctx context.Context, opt *tp.UpdateCommentOption) (tp.Comment, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	var comment *commentRecord
+	err := s.store.Update(ctx, func(data *storeData) error {
+		comment = findComment(data, opt.CommentID)
+		if comment == nil {
+			return tp.NotFound
+		}
+		comment.Body = opt.Body
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newComment(comment), nil
+}
+
+// Delete Comment deletes a comment.
+func (s *CommentService) Delete(ctx context.Context, opt *tp.DeleteCommentOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	return s.store.Update(ctx, func(data *storeData) error {
+		for _, mr := range data.MergeRequests {
+			for i, c := range mr.Comments {
+				if c.ID == opt.CommentID {
+					mr.Comments = append(mr.Comments[:i], mr.Comments[i+1:]...)
+					return nil
+				}
+			}
+		}
+		return tp.NotFound
+	})
+}
+
+func findComment(data *storeData, commentID string) *commentRecord {
+	for _, mr := range data.MergeRequests {
+		for _, c := range mr.Comments {
+			if c.ID == commentID {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func newComment(comment *commentRecord) *Comment {
+	return &Comment{
+		commentId: comment.ID,
+		body:      comment.Body,
+	}
+}
+
+func (c *Comment) CommentID() string {
+	return c.commentId
+}
+
+func (c *Comment) Body() string {
+	return c.body
+}
@@ -0,0 +1,34 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestCommentService(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	service := NewCommentService(NewStore(repo.git))
+
+	comment, err := service.Create(ctx, &tp.CreateCommentOption{MergeRequestID: "1", Body: "hello"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = service.Update(ctx, &tp.UpdateCommentOption{CommentID: comment.CommentID(), Body: "updated"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	// comments are kept in the notes file
+	comments, err := NewCommentService(NewStore(repo.git)).Find(ctx, &tp.FindCommentOption{MergeRequestID: "1"})
+	if err != nil || len(comments) != 1 || comments[0].Body() != "updated" {
+		t.Fatalf("find: %v %+v", err, comments)
+	}
+
+	if err = service.Delete(ctx, &tp.DeleteCommentOption{CommentID: comment.CommentID()}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err = service.Delete(ctx, &tp.DeleteCommentOption{CommentID: comment.CommentID()}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,320 @@
+package local
+
+import (
+	"context"
+	"errors"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type Commit struct {
+	sha     string
+	tree    *Tree
+	message string
+	author  tp.Signature
+	parents []string
+}
+
+type Tree struct {
+	git *Git
+	sha string
+}
+
+type TreeEntry struct {
+	sha       string
+	path      string
+	mode      string
+	entryType string
+	size      int
+}
+
+// commitInfo is the raw commit object read from git
+type commitInfo struct {
+	SHA            string
+	Tree           string
+	Parents        []string
+	AuthorName     string
+	AuthorEmail    string
+	AuthorDate     string
+	CommitterName  string
+	CommitterEmail string
+	CommitterDate  string
+	Message        string
+}
+
+// commitFormat is the pretty format of commitInfo, fields are separated by NUL
+const commitFormat = "%H%x00%T%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%B"
+
+type CommitService struct {
+	git    *Git
+	signer tp.Signer // signs the created commits, unsigned if nil
+}
+
+func NewCommitService(git *Git) *CommitService {
+	return &CommitService{
+		git: git,
+	}
+}
+
+// Get Commit returns the commit for the given sha.
+func (s *CommitService) Get(ctx context.Context, opt *tp.GetCommitOption) (tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Get Commit Opt: %+v", *opt)
+	info, err := readCommit(ctx, s.git, opt.SHA)
+	if err != nil {
+		return nil, err
+	}
+	return newCommit(s.git, info), nil
+}
+
+// Create Commit creates a new commit object, no ref is updated.
+// The author of SHA is kept when it is set, see types.CreateCommitOption.
+func (s *CommitService) Create(ctx context.Context, opt *tp.CreateCommitOption) (tp.Commit, error) {
+	if opt == nil || opt.Tree == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	logrus.Debugf("Create Commit Opt: %+v", *opt)
+	args := []string{"commit-tree", opt.Tree.SHA(), "-m", opt.PickMessage}
+	for _, p := range opt.Parents {
+		args = append(args, "-p", p)
+	}
+	var env []string
+	if opt.SHA != "" {
+		source, err := readCommit(ctx, s.git, opt.SHA)
+		if err != nil {
+			return nil, err
+		}
+		env = source.pickIdentity(opt.Committer, opt.DateMode)
+	} else if opt.Committer != nil {
+		env = committerIdentity(opt.Committer)
+	}
+	sha, err := s.git.RunIn(ctx, s.git.Path, env, args...)
+	if err != nil {
+		logrus.Errorf("Create Commit Error: %v", err)
+		return nil, err
+	}
+	if s.signer != nil {
+		if sha, err = s.git.SignCommit(ctx, s.signer, sha); err != nil {
+			return nil, err
+		}
+	}
+	info, err := readCommit(ctx, s.git, sha)
+	if err != nil {
+		return nil, err
+	}
+	return newCommit(s.git, info), nil
+}
+
+// IsAncestor reports whether the commit is in the history of the branch by git merge-base --is-ancestor
+func (s *CommitService) IsAncestor(ctx context.Context, opt *tp.AncestorOption) (bool, error) {
+	if opt == nil {
+		return false, tp.ErrInvalidOptions
+	}
+	head, err := s.git.ResolveCommit(ctx, branchRef(opt.Branch))
+	if err != nil {
+		return false, tp.NotFound
+	}
+	sha, err := s.git.ResolveCommit(ctx, opt.SHA)
+	if err != nil {
+		return false, tp.NotFound
+	}
+	_, err = s.git.Run(ctx, "merge-base", "--is-ancestor", sha, head)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CheckConflict cherry-pick the commit onto the target in a temporary worktree, both modes work the same.
+func (s *CommitService) CheckConflict(ctx context.Context, opts *tp.CheckConflictOption) error {
+	if opts == nil {
+		return tp.ErrInvalidOptions
+	}
+	target, err := s.git.ResolveCommit(ctx, branchRef(opts.Target))
+	if err != nil {
+		return tp.NotFound
+	}
+	source, err := readCommit(ctx, s.git, opts.Commit)
+	if err != nil {
+		return err
+	}
+	worktree, err := addWorktree(ctx, s.git, target)
+	if err != nil {
+		return err
+	}
+	defer worktree.Remove(ctx)
+
+	_, err = worktree.CherryPick(ctx, source, opts.Mainline)
+	return err
+}
+
+// List returns the recent commits of the branch, newest first
+func (s *CommitService) List(ctx context.Context, opt *tp.ListCommitOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	head, err := s.git.ResolveCommit(ctx, branchRef(opt.Branch))
+	if err != nil {
+		return nil, tp.NotFound
+	}
+	out, err := s.git.Run(ctx, "rev-list", "-n", strconv.Itoa(opt.Limit), head)
+	if err != nil {
+		return nil, err
+	}
+	var result []tp.Commit
+	for _, sha := range strings.Fields(out) {
+		info, err := readCommit(ctx, s.git, sha)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newCommit(s.git, info))
+	}
+	return result, nil
+}
+
+// Diff returns the diff of the commit against its first parent
+func (s *CommitService) Diff(ctx context.Context, opt *tp.GetCommitOption) (string, error) {
+	if opt == nil {
+		return "", tp.ErrInvalidOptions
+	}
+	info, err := readCommit(ctx, s.git, opt.SHA)
+	if err != nil {
+		return "", err
+	}
+	if len(info.Parents) == 0 {
+		return s.git.Run(ctx, "diff-tree", "-p", "--no-color", "--no-commit-id", "--root", info.SHA)
+	}
+	return s.git.Run(ctx, "diff-tree", "-p", "--no-color", info.Parents[0], info.SHA)
+}
+
+func readCommit(ctx context.Context, git *Git, rev string) (*commitInfo, error) {
+	sha, err := git.ResolveCommit(ctx, rev)
+	if err != nil {
+		return nil, tp.NotFound
+	}
+	out, err := git.Run(ctx, "show", "-s", "--format="+commitFormat, sha)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.SplitN(out, "\x00", 10)
+	if len(fields) != 10 {
+		return nil, errors.New("unexpected commit format")
+	}
+	return &commitInfo{
+		SHA:            fields[0],
+		Tree:           fields[1],
+		Parents:        strings.Fields(fields[2]),
+		AuthorName:     fields[3],
+		AuthorEmail:    fields[4],
+		AuthorDate:     fields[5],
+		CommitterName:  fields[6],
+		CommitterEmail: fields[7],
+		CommitterDate:  fields[8],
+		Message:        fields[9],
+	}, nil
+}
+
+func newCommit(git *Git, info *commitInfo) *Commit {
+	return &Commit{
+		sha:     info.SHA,
+		tree:    &Tree{git: git, sha: info.Tree},
+		message: info.Message,
+		author:  tp.Signature{Name: info.AuthorName, Email: info.AuthorEmail},
+		parents: info.Parents,
+	}
+}
+
+// SHA Commit returns the commit sha.
+func (c *Commit) SHA() string {
+	return c.sha
+}
+
+func (c *Commit) Tree() tp.Tree {
+	return c.tree
+}
+
+func (c *Commit) Message() string {
+	return c.message
+}
+
+func (c *Commit) Author() tp.Signature {
+	return c.author
+}
+
+// Parents returns the shas of the parents, the first parent first
+func (c *Commit) Parents() []string {
+	return c.parents
+}
+
+// SHA Tree returns the tree sha.
+func (t *Tree) SHA() string {
+	return t.sha
+}
+
+// Entries returns the entries of the tree recursively, nil if the tree can not be read
+func (t *Tree) Entries() []tp.TreeEntry {
+	out, err := t.git.Run(context.Background(), "ls-tree", "-r", "-l", t.sha)
+	if err != nil {
+		logrus.Warnf("List tree %s: %v", t.sha, err)
+		return nil
+	}
+	var entries []tp.TreeEntry
+	for _, line := range strings.Split(out, "\n") {
+		// <mode> SP <type> SP <object> SP <object size> TAB <file>
+		meta, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			continue
+		}
+		size, _ := strconv.Atoi(fields[3])
+		entries = append(entries, &TreeEntry{
+			mode:      fields[0],
+			entryType: fields[1],
+			sha:       fields[2],
+			size:      size,
+			path:      path,
+		})
+	}
+	return entries
+}
+
+func (t *Tree) Truncated() bool {
+	return false
+}
+
+// SHA TreeEntry returns the object sha.
+func (t *TreeEntry) SHA() string {
+	return t.sha
+}
+
+func (t *TreeEntry) Path() string {
+	return t.path
+}
+
+func (t *TreeEntry) Mode() string {
+	return t.mode
+}
+
+func (t *TreeEntry) Type() string {
+	return t.entryType
+}
+
+func (t *TreeEntry) Size() int {
+	return t.size
+}
+
+// Content is not loaded for local trees.
+func (t *TreeEntry) Content() string {
+	return ""
+}
+
+// Url local objects have no url.
+func (t *TreeEntry) Url() string {
+	return ""
+}
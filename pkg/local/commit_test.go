@@ -0,0 +1,113 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestCommitService_Get(t *testing.T) {
+	repo := newTestRepo(t)
+	sha := repo.commit("master", "b.txt", "b\n", "feat: add b\n\nbody")
+	commit, err := NewCommitService(repo.git).Get(context.Background(), &tp.GetCommitOption{SHA: sha[:7]})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if commit.SHA() != sha || commit.Message() != "feat: add b\n\nbody" {
+		t.Fatalf("commit: %+v", commit)
+	}
+	if entries := commit.Tree().Entries(); len(entries) != 2 || entries[1].Path() != "b.txt" || entries[1].Size() != 2 {
+		t.Fatalf("entries: %+v", entries)
+	}
+
+	_, err = NewCommitService(repo.git).Get(context.Background(), &tp.GetCommitOption{SHA: "deadbeef"})
+	if err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestCommitService_Create(t *testing.T) {
+	repo := newTestRepo(t)
+	service := NewCommitService(repo.git)
+	head, err := service.Get(context.Background(), &tp.GetCommitOption{SHA: "master"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	t.Setenv("GIT_COMMITTER_NAME", "norn")
+	t.Setenv("GIT_COMMITTER_EMAIL", "norn@example.com")
+	t.Setenv("GIT_AUTHOR_NAME", "norn")
+	t.Setenv("GIT_AUTHOR_EMAIL", "norn@example.com")
+	commit, err := service.Create(context.Background(), &tp.CreateCommitOption{
+		Tree:        head.Tree(),
+		PickMessage: "empty",
+		Parents:     []string{head.SHA()},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if parent := repo.run("rev-parse", commit.SHA()+"^"); parent != head.SHA() {
+		t.Fatalf("parent: %s", parent)
+	}
+}
+
+func TestCommitService_CheckConflict(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.commit("r1", "a.txt", "a\nr1\nc\n", "fix on r1")
+	conflict := repo.commit("master", "a.txt", "a\nmaster\nc\n", "fix on master")
+	clean := repo.commit("master", "b.txt", "b\n", "feat: add b")
+	service := NewCommitService(repo.git)
+
+	err := service.CheckConflict(context.Background(), &tp.CheckConflictOption{Commit: clean, Target: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	err = service.CheckConflict(context.Background(), &tp.CheckConflictOption{Commit: conflict, Target: "r1"})
+	if err != tp.ErrConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestCommitService_IsAncestor(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	repo.branch("r1", "master")
+	fix := repo.commit("master", "a.txt", "fix\n", "fix")
+	service := NewCommitService(repo.git)
+
+	for branch, want := range map[string]bool{"master": true, "r1": false} {
+		got, err := service.IsAncestor(ctx, &tp.AncestorOption{SHA: fix, Branch: branch})
+		if err != nil || got != want {
+			t.Errorf("IsAncestor(%s) = %v, %v, want %v", branch, got, err, want)
+		}
+	}
+	if _, err := service.IsAncestor(ctx, &tp.AncestorOption{SHA: fix, Branch: "missing"}); err != tp.NotFound {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestCommitService_ListDiff(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	sha := repo.commit("master", "b.txt", "b\n", "feat: add b")
+	service := NewCommitService(repo.git)
+
+	commits, err := service.List(ctx, &tp.ListCommitOption{Branch: "master", Limit: 1})
+	if err != nil || len(commits) != 1 || commits[0].SHA() != sha || commits[0].Message() != "feat: add b" {
+		t.Fatalf("err: %v commits: %+v", err, commits)
+	}
+	if commits, _ = service.List(ctx, &tp.ListCommitOption{Branch: "master", Limit: 10}); len(commits) != 2 {
+		t.Fatalf("commits: %+v", commits)
+	}
+
+	diff, err := service.Diff(ctx, &tp.GetCommitOption{SHA: sha})
+	if err != nil || !strings.Contains(diff, "+++ b/b.txt") || strings.Contains(diff, "a.txt") {
+		t.Fatalf("err: %v diff: %s", err, diff)
+	}
+	// the root commit is diffed against the empty tree
+	root, err := service.Diff(ctx, &tp.GetCommitOption{SHA: commits[1].SHA()})
+	if err != nil || !strings.Contains(root, "+++ b/a.txt") {
+		t.Fatalf("err: %v diff: %s", err, root)
+	}
+}
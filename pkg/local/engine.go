@@ -0,0 +1,163 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// RemotePickService picks with git in the local clone like PickService, the target branch is fetched
+// from the remote first and the pick is pushed to it. It makes the picks of a vendor whose API can
+// not handle them, such as large merge requests or changes with renames.
+type RemotePickService struct {
+	pick   *PickService
+	remote string
+}
+
+func NewRemotePickService(git *Git, remote string) *RemotePickService {
+	if remote == "" {
+		remote = "origin"
+	}
+	return &RemotePickService{
+		pick:   NewPickService(git),
+		remote: remote,
+	}
+}
+
+// Pick cherry-picks the commit onto the branch of the remote
+func (c *RemotePickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return c.apply(ctx, opt, false)
+}
+
+// Revert reverts the commit on the branch of the remote
+func (c *RemotePickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	return c.apply(ctx, opt, true)
+}
+
+// apply fetches the branch and the commit, picks or reverts the commit on top of the fetched branch
+// and pushes the result. The push is refused if the branch moved since the fetch, it fails with
+// ErrStaleRef then and the pick is redone on the new head by the caller.
+func (c *RemotePickService) apply(ctx context.Context, opt *tp.PickOption, revert bool) (*tp.PickResult, error) {
+	if opt == nil || opt.SHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	git, branch := c.pick.git, strings.TrimPrefix(branchRef(opt.Branch), "refs/heads/")
+	tracking := fmt.Sprintf("refs/remotes/%s/%s", c.remote, branch)
+	if _, err := git.Run(ctx, "fetch", "--no-tags", c.remote, fmt.Sprintf("+refs/heads/%s:%s", branch, tracking)); err != nil {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Fetch %s from %s: %v", branch, c.remote, err)
+		return nil, err
+	}
+	if _, err := git.Run(ctx, "cat-file", "-e", opt.SHA+"^{commit}"); err != nil {
+		// servers only send commits by a full sha
+		if _, err = git.Run(ctx, "fetch", "--no-tags", c.remote, opt.SHA); err != nil {
+			logrus.Errorf("Fetch %s from %s: %v", opt.SHA, c.remote, err)
+			return nil, tp.NotFound
+		}
+	}
+
+	_, result, err := c.pick.commit(ctx, tracking, opt, revert)
+	if err != nil || opt.DryRun {
+		return result, err
+	}
+	// not forced, the remote refuses the push unless the pick descends from the head of the branch
+	if _, err = git.Run(ctx, "push", c.remote, result.SHA+":refs/heads/"+branch); err != nil {
+		if strings.Contains(err.Error(), "[rejected]") || strings.Contains(err.Error(), "non-fast-forward") {
+			logrus.Warnf("Push %s to %s is rejected, the branch moved", result.SHA, branch)
+			return nil, tp.ErrStaleRef
+		}
+		logrus.Errorf("Push %s to %s: %v", result.SHA, branch, err)
+		return nil, err
+	}
+	if _, err = git.Run(ctx, "update-ref", tracking, result.SHA); err != nil {
+		logrus.Warnf("Update %s: %v", tracking, err)
+	}
+	return result, nil
+}
+
+// engineProvider is a provider whose picks are made by another pick service, and whose branches
+// are reset by a push of the clone
+type engineProvider struct {
+	tp.Provider
+	pick      tp.PickService
+	reference *RemoteReferenceService
+}
+
+func (p *engineProvider) Pick() tp.PickService {
+	return p.pick
+}
+
+func (p *engineProvider) Reference() tp.ReferenceService {
+	return p.reference
+}
+
+// RemoteReferenceService resets the branches of the remote by a push of the clone with a lease,
+// which is a compare-and-swap the APIs of GitLab and Gitea do not have. The other calls are made
+// by the reference service of the vendor.
+type RemoteReferenceService struct {
+	tp.ReferenceService
+	git    *Git
+	remote string
+}
+
+// Update resets the branch by git push --force-with-lease if Force is set, the push is refused
+// with ErrStaleRef if the branch is not at ExpectedSHA anymore
+func (s *RemoteReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
+	if opt == nil || !opt.Force {
+		return s.ReferenceService.Update(ctx, opt)
+	}
+	if opt.ExpectedSHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Ref)
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	// the commit to reset to is in the history of the branch
+	tracking := fmt.Sprintf("refs/remotes/%s/%s", s.remote, branch)
+	if _, err := s.git.Run(ctx, "fetch", "--no-tags", s.remote, fmt.Sprintf("+%s:%s", ref, tracking)); err != nil {
+		if strings.Contains(err.Error(), "couldn't find remote ref") {
+			return nil, tp.NotFound
+		}
+		logrus.Errorf("Fetch %s from %s: %v", branch, s.remote, err)
+		return nil, err
+	}
+	if _, err := s.git.ResolveCommit(ctx, opt.SHA); err != nil {
+		return nil, tp.NotFound
+	}
+	_, err := s.git.Run(ctx, "push", fmt.Sprintf("--force-with-lease=%s:%s", ref, opt.ExpectedSHA), s.remote, opt.SHA+":"+ref)
+	if err != nil {
+		if strings.Contains(err.Error(), "stale info") || strings.Contains(err.Error(), "[rejected]") {
+			logrus.Warnf("Reset %s to %s is rejected, the branch is not at %s", branch, opt.SHA, opt.ExpectedSHA)
+			return nil, tp.ErrStaleRef
+		}
+		logrus.Errorf("Push %s to %s: %v", opt.SHA, branch, err)
+		return nil, err
+	}
+	if _, err = s.git.Run(ctx, "update-ref", tracking, opt.SHA); err != nil {
+		logrus.Warnf("Update %s: %v", tracking, err)
+	}
+	return &tp.Reference{Ref: ref, SHA: opt.SHA}, nil
+}
+
+// WithEngine returns the provider whose picks, reverts and branch resets are made in the clone at
+// opt.RepoPath and pushed to opt.Remote, the other services of the provider are kept
+func WithEngine(ctx context.Context, provider tp.Provider, opt *tp.CreateProviderOption) (tp.Provider, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	git := NewGit(opt.RepoPath)
+	if _, err := git.GitDir(ctx); err != nil {
+		return nil, err
+	}
+	pick := NewRemotePickService(git, opt.Remote)
+	if _, err := git.Run(ctx, "remote", "get-url", pick.remote); err != nil {
+		return nil, fmt.Errorf("%w: remote %s of %s: %v", tp.ErrInvalidOptions, pick.remote, git.Path, err)
+	}
+	pick.pick.signer = opt.Signer
+	pick.pick.strategyOptions = opt.StrategyOptions
+	reference := &RemoteReferenceService{ReferenceService: provider.Reference(), git: git, remote: pick.remote}
+	return &engineProvider{Provider: provider, pick: pick, reference: reference}, nil
+}
@@ -0,0 +1,130 @@
+package local
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/fake"
+	tp "github.com/kentio/norn/pkg/types"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestClone clones the repo, the repo is the origin of the clone
+func newTestClone(t *testing.T, origin *testRepo) *testRepo {
+	dir := filepath.Join(t.TempDir(), "clone")
+	if _, err := NewGit(".").Run(context.Background(), "clone", "-q", origin.git.Path, dir); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return &testRepo{t: t, git: NewGit(dir)}
+}
+
+func TestRemotePickService_Pick(t *testing.T) {
+	origin := newTestRepo(t)
+	origin.branch("r1", "master")
+	// a.txt is renamed on r1, the change of a.txt is picked to b.txt
+	origin.run("checkout", "-q", "r1")
+	origin.run("mv", "a.txt", "b.txt")
+	origin.run("commit", "-q", "-m", "rename a to b")
+	clone := newTestClone(t, origin)
+	sha := origin.commit("master", "a.txt", "a\nfix\nc\n", "fix: a")
+	origin.run("checkout", "-q", "--detach")
+	ctx := context.Background()
+	service := NewRemotePickService(clone.git, "")
+
+	// the commit and the branch are fetched, nothing is pushed in dry run
+	r1 := origin.run("rev-parse", "r1")
+	result, err := service.Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1", DryRun: true})
+	if err != nil || result.Tree == "" || origin.run("rev-parse", "r1") != r1 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+
+	result, err = service.Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if origin.run("rev-parse", "r1") != result.SHA || clone.run("rev-parse", "origin/r1") != result.SHA {
+		t.Fatalf("the pick %s is not pushed", result.SHA)
+	}
+	if content := origin.show("r1", "b.txt"); content != "a\nfix\nc" {
+		t.Fatalf("content: %q", content)
+	}
+	if message := origin.run("log", "-1", "--format=%B", "r1"); !strings.HasSuffix(message, "(cherry picked from commit "+sha[:7]+")") {
+		t.Fatalf("message: %q", message)
+	}
+
+	if _, err = service.Pick(ctx, "", &tp.PickOption{SHA: sha, Branch: "missing"}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestRemotePickService_PickRejected(t *testing.T) {
+	origin := newTestRepo(t)
+	origin.branch("r1", "master")
+	sha := origin.commit("master", "b.txt", "b\n", "feat: add b")
+	origin.run("checkout", "-q", "--detach")
+	clone := newTestClone(t, origin)
+	service := NewRemotePickService(clone.git, "origin")
+
+	// the branch moves after the fetch, simulated by a pick on top of the tracking ref made before it moved
+	_, result, err := service.pick.commit(context.Background(), "refs/remotes/origin/r1", &tp.PickOption{SHA: sha, Branch: "r1"}, false)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	moved := origin.commit("r1", "c.txt", "c\n", "feat: add c")
+	origin.run("checkout", "-q", "--detach")
+	if _, err = clone.git.Run(context.Background(), "push", "origin", result.SHA+":refs/heads/r1"); err == nil {
+		t.Fatalf("the push of a stale pick is accepted")
+	}
+
+	// the pick is redone on the new head
+	result, err = service.Pick(context.Background(), "", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil || origin.run("rev-parse", "r1^") != moved {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+}
+
+func TestWithEngine(t *testing.T) {
+	origin := newTestRepo(t)
+	clone := newTestClone(t, origin)
+	ctx := context.Background()
+
+	provider, err := WithEngine(ctx, fake.NewProvider(), &tp.CreateProviderOption{RepoPath: clone.git.Path, StrategyOptions: []string{"theirs"}})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pick, ok := provider.Pick().(*RemotePickService)
+	if !ok || pick.remote != "origin" || pick.pick.strategyOptions[0] != "theirs" || provider.ProviderID() != tp.GitHubProvider {
+		t.Fatalf("provider: %+v", provider)
+	}
+	if _, err = WithEngine(ctx, fake.NewProvider(), &tp.CreateProviderOption{RepoPath: clone.git.Path, Remote: "upstream"}); err == nil {
+		t.Fatalf("missing remote is accepted")
+	}
+}
+
+func TestRemoteReferenceService_Update(t *testing.T) {
+	origin := newTestRepo(t)
+	root := origin.run("rev-parse", "master")
+	pick := origin.commit("master", "b.txt", "b\n", "fix: b")
+	origin.run("checkout", "-q", "--detach")
+	clone := newTestClone(t, origin)
+	ctx := context.Background()
+	provider, err := WithEngine(ctx, fake.NewProvider(), &tp.CreateProviderOption{RepoPath: clone.git.Path})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the lease is the expected sha, the reset of a moved branch is refused
+	opt := &tp.UpdateOption{Ref: "refs/heads/master", SHA: root, ExpectedSHA: root, Force: true}
+	if _, err = provider.Reference().Update(ctx, opt); err != tp.ErrStaleRef || origin.run("rev-parse", "master") != pick {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	opt.ExpectedSHA = pick
+	ref, err := provider.Reference().Update(ctx, opt)
+	if err != nil || ref.SHA != root || origin.run("rev-parse", "master") != root {
+		t.Fatalf("err: %v ref: %+v", err, ref)
+	}
+	opt.Ref = "refs/heads/missing"
+	if _, err = provider.Reference().Update(ctx, opt); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
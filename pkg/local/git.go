@@ -0,0 +1,22 @@
+package local
+
+import (
+	"github.com/kentio/norn/internal/git"
+	"strings"
+)
+
+// Git runs git commands in the local clone, see internal/git
+type Git = git.Git
+
+// NewGit returns the git runner of the clone at path, the current directory if empty
+func NewGit(path string) *Git {
+	return git.NewGit(path)
+}
+
+// branchRef returns the full ref of the branch, "heads/main" and "main" both return "refs/heads/main"
+func branchRef(ref string) string {
+	if strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+	return "refs/heads/" + strings.TrimPrefix(ref, "heads/")
+}
@@ -0,0 +1,67 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testRepo is a local clone created for a test
+type testRepo struct {
+	t   *testing.T
+	git *Git
+}
+
+var testIdentity = []string{
+	"GIT_AUTHOR_NAME=norn", "GIT_AUTHOR_EMAIL=norn@example.com",
+	"GIT_COMMITTER_NAME=norn", "GIT_COMMITTER_EMAIL=norn@example.com",
+}
+
+// newTestRepo creates a repo with an initial commit on master
+func newTestRepo(t *testing.T) *testRepo {
+	dir := t.TempDir()
+	r := &testRepo{t: t, git: NewGit(dir)}
+	r.run("init", "-q", "-b", "master")
+	r.commit("master", "a.txt", "a\nb\nc\n", "init")
+	return r
+}
+
+func (r *testRepo) run(args ...string) string {
+	out, err := r.git.RunIn(context.Background(), r.git.Path, testIdentity, args...)
+	if err != nil {
+		r.t.Fatalf("err: %v", err)
+	}
+	return out
+}
+
+// commit writes the file on the branch and commits it, returns the commit sha
+func (r *testRepo) commit(branch, file, content, message string) string {
+	if current, _ := r.git.Run(context.Background(), "symbolic-ref", "--short", "HEAD"); current != branch {
+		r.run("checkout", "-q", branch)
+	}
+	if err := os.WriteFile(filepath.Join(r.git.Path, file), []byte(content), 0o644); err != nil {
+		r.t.Fatalf("err: %v", err)
+	}
+	r.run("add", file)
+	r.run("commit", "-q", "-m", message)
+	return r.run("rev-parse", "HEAD")
+}
+
+// branch creates a branch from the start point
+func (r *testRepo) branch(name, start string) {
+	r.run("branch", name, start)
+}
+
+// show returns the content of the file at rev
+func (r *testRepo) show(rev, file string) string {
+	return r.run("show", rev+":"+file)
+}
+
+func TestBranchRef(t *testing.T) {
+	for _, ref := range []string{"release/1.0", "heads/release/1.0", "refs/heads/release/1.0"} {
+		if r := branchRef(ref); r != "refs/heads/release/1.0" {
+			t.Errorf("branchRef(%s) = %s", ref, r)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"strconv"
+	"strings"
+)
+
+// MergeRequestService reads merge requests from the notes file of the store
+type MergeRequestService struct {
+	store *Store
+}
+
+type MergeRequest struct {
+	id          string
+	title       string
+	description string
+	state       tp.MergeRequestState
+	labels      []string
+}
+
+func (s *MergeRequest) MergeId() string {
+	return s.id
+}
+
+func (s *MergeRequest) Title() string {
+	return s.title
+}
+
+func (s *MergeRequest) Description() string {
+	return s.description
+}
+
+func (s *MergeRequest) State() tp.MergeRequestState {
+	return s.state
+}
+
+func (s *MergeRequest) Labels() []string {
+	return s.labels
+}
+
+// WebUrl local merge requests have no web page
+func (s *MergeRequest) WebUrl() string {
+	return ""
+}
+
+func NewMergeRequestService(store *Store) *MergeRequestService {
+	return &MergeRequestService{
+		store: store,
+	}
+}
+
+func (s *MergeRequestService) Get(ctx context.Context, opt *tp.GetMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	var mr *MergeRequest
+	err := s.store.View(ctx, func(data *storeData) error {
+		record, ok := data.MergeRequests[opt.MergeID]
+		if !ok {
+			return tp.NotFound
+		}
+		mr = newMergeRequest(opt.MergeID, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+// Create records a merge request from the head branch to the base branch in the notes file
+func (s *MergeRequestService) Create(ctx context.Context, opt *tp.CreateMergeRequestOption) (tp.MergeRequest, error) {
+	if opt == nil || opt.Head == "" || opt.Base == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	head := strings.TrimPrefix(branchRef(opt.Head), "refs/heads/")
+	base := strings.TrimPrefix(branchRef(opt.Base), "refs/heads/")
+	var mr *MergeRequest
+	err := s.store.Update(ctx, func(data *storeData) error {
+		for _, record := range data.MergeRequests {
+			if record.State == tp.MergeRequestStateOpen.String() && record.Head == head && record.Base == base {
+				return tp.ErrAlreadyExists
+			}
+		}
+		// ids are shared with comments, skip the ids chosen by the caller
+		id := ""
+		for id == "" || data.MergeRequests[id] != nil {
+			data.NextID++
+			id = strconv.Itoa(data.NextID)
+		}
+		record := &mergeRequestRecord{
+			Title:       opt.Title,
+			Description: opt.Description,
+			State:       tp.MergeRequestStateOpen.String(),
+			Head:        head,
+			Base:        base,
+			Labels:      opt.Labels,
+		}
+		data.MergeRequests[id] = record
+		mr = newMergeRequest(id, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func newMergeRequest(id string, record *mergeRequestRecord) *MergeRequest {
+	state, _ := tp.MergeRequestStateFromString(record.State)
+	return &MergeRequest{
+		id:          id,
+		title:       record.Title,
+		description: record.Description,
+		state:       state,
+		labels:      record.Labels,
+	}
+}
+
+// ListCommits returns the commits of head not in base, the merge request must record both branches
+func (s *MergeRequestService) ListCommits(ctx context.Context, opt *tp.ListMergeRequestCommitsOption) ([]tp.Commit, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	var head, base string
+	err := s.store.View(ctx, func(data *storeData) error {
+		record, ok := data.MergeRequests[opt.MergeID]
+		if !ok {
+			return tp.NotFound
+		}
+		head, base = record.Head, record.Base
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if head == "" || base == "" {
+		return nil, fmt.Errorf("%w: merge request %s has no head or base branch", tp.ErrNotSupported, opt.MergeID)
+	}
+
+	out, err := s.store.git.Run(ctx, "rev-list", "--reverse", "--topo-order", branchRef(base)+".."+branchRef(head))
+	if err != nil {
+		return nil, err
+	}
+	var commits []tp.Commit
+	for _, sha := range strings.Fields(out) {
+		info, err := readCommit(ctx, s.store.git, sha)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, newCommit(s.store.git, info))
+	}
+	return commits, nil
+}
@@ -0,0 +1,89 @@
+package local
+
+import (
+	"context"
+	"errors"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestMergeRequestService_Get(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	store := NewStore(repo.git)
+	service := NewMergeRequestService(store)
+
+	if _, err := service.Get(ctx, &tp.GetMergeRequestOption{MergeID: "1"}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+
+	// commenting creates the merge request
+	if _, err := NewCommentService(store).Create(ctx, &tp.CreateCommentOption{MergeRequestID: "1", Body: "hello"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	mr, err := service.Get(ctx, &tp.GetMergeRequestOption{MergeID: "1"})
+	if err != nil || mr.MergeId() != "1" || mr.State() != tp.MergeRequestStateOpen {
+		t.Fatalf("get: %v %+v", err, mr)
+	}
+}
+
+func TestMergeRequestService_ListCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	store := NewStore(repo.git)
+	service := NewMergeRequestService(store)
+
+	repo.branch("feature", "master")
+	first := repo.commit("feature", "b.txt", "b\n", "first")
+	second := repo.commit("feature", "c.txt", "c\n", "second")
+
+	err := store.Update(ctx, func(data *storeData) error {
+		data.MergeRequests["1"] = &mergeRequestRecord{State: tp.MergeRequestStateOpen.String()}
+		data.MergeRequests["2"] = &mergeRequestRecord{State: tp.MergeRequestStateOpen.String(), Head: "feature", Base: "master"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err = service.ListCommits(ctx, &tp.ListMergeRequestCommitsOption{MergeID: "1"}); !errors.Is(err, tp.ErrNotSupported) {
+		t.Fatalf("err = %v, want not supported", err)
+	}
+	commits, err := service.ListCommits(ctx, &tp.ListMergeRequestCommitsOption{MergeID: "2"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(commits) != 2 || commits[0].SHA() != first || commits[1].SHA() != second {
+		t.Fatalf("commits: %+v", commits)
+	}
+}
+
+func TestMergeRequestService_Create(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	store := NewStore(repo.git)
+	service := NewMergeRequestService(store)
+
+	repo.branch("feature", "master")
+	head := repo.commit("feature", "b.txt", "b\n", "feat: add b")
+
+	// keep ids of merge requests created through comments
+	if _, err := NewCommentService(store).Create(ctx, &tp.CreateCommentOption{MergeRequestID: "1", Body: "hello"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	mr, err := service.Create(ctx, &tp.CreateMergeRequestOption{Title: "feat", Head: "feature", Base: "master", Labels: []string{"backport"}})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if mr.MergeId() != "2" || mr.Title() != "feat" || len(mr.Labels()) != 1 {
+		t.Fatalf("mr: %+v", mr)
+	}
+	commits, err := service.ListCommits(ctx, &tp.ListMergeRequestCommitsOption{MergeID: mr.MergeId()})
+	if err != nil || len(commits) != 1 || commits[0].SHA() != head {
+		t.Fatalf("commits: %v %+v", err, commits)
+	}
+	// one open merge request of the head to the base
+	if _, err = service.Create(ctx, &tp.CreateMergeRequestOption{Title: "again", Head: "feature", Base: "master"}); !errors.Is(err, tp.ErrAlreadyExists) {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+}
@@ -0,0 +1,88 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+)
+
+// Provider is backed by a local clone, it needs no forge API.
+// The repo argument of the services is ignored, all operations work on the clone at RepoPath.
+type Provider struct {
+	providerID tp.ProviderType
+	git        *Git
+
+	commitService       *CommitService
+	referenceService    *ReferenceService
+	mergeRequestService *MergeRequestService
+	commentService      *CommentService
+	pickService         *PickService
+	repositoryService   *RepositoryService
+}
+
+func NewProvider(ctx context.Context, opt *tp.CreateProviderOption) (*Provider, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	git := NewGit(opt.RepoPath)
+	if _, err := git.GitDir(ctx); err != nil {
+		return nil, err
+	}
+	provider := NewProviderWithGit(git)
+	provider.SetSigner(opt.Signer)
+	provider.SetStrategyOptions(opt.StrategyOptions)
+	return provider, nil
+}
+
+// NewProviderWithGit creates a new provider with the given git runner.
+func NewProviderWithGit(git *Git) *Provider {
+	store := NewStore(git)
+	return &Provider{
+		providerID:          tp.LocalProvider,
+		git:                 git,
+		commitService:       NewCommitService(git),
+		referenceService:    NewReferenceService(git),
+		mergeRequestService: NewMergeRequestService(store),
+		commentService:      NewCommentService(store),
+		pickService:         NewPickService(git),
+		repositoryService:   NewRepositoryService(git),
+	}
+}
+
+// SetStrategyOptions passes the options to git cherry-pick and git revert as -X, such as "find-renames=30%"
+func (p *Provider) SetStrategyOptions(options []string) {
+	p.pickService.strategyOptions = options
+}
+
+// SetSigner signs the commits created by the provider, unsigned if nil
+func (p *Provider) SetSigner(signer tp.Signer) {
+	p.commitService.signer = signer
+	p.pickService.signer = signer
+}
+
+func (p *Provider) Commit() tp.CommitService {
+	return p.commitService
+}
+
+func (p *Provider) Reference() tp.ReferenceService {
+	return p.referenceService
+}
+
+func (p *Provider) MergeRequest() tp.MergeRequestService {
+	return p.mergeRequestService
+}
+
+func (p *Provider) Comment() tp.CommentService {
+	return p.commentService
+}
+
+func (p *Provider) Repository() tp.RepositoryService {
+	return p.repositoryService
+}
+
+func (p *Provider) ProviderID() tp.ProviderType {
+	return p.providerID
+}
+
+func (p *Provider) Pick() tp.PickService {
+	return p.pickService
+}
@@ -0,0 +1,61 @@
+package local
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/pick"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(context.Background(), &tp.CreateProviderOption{RepoPath: t.TempDir()}); err == nil {
+		t.Fatalf("not a git repo")
+	}
+	repo := newTestRepo(t)
+	provider, err := NewProvider(context.Background(), &tp.CreateProviderOption{RepoPath: repo.git.Path})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.ProviderID() != tp.LocalProvider {
+		t.Fatalf("provider id = %s", provider.ProviderID())
+	}
+}
+
+// TestProvider_ProcessPick runs the summary and pick workflow end-to-end without a forge
+func TestProvider_ProcessPick(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	repo.branch("r2", "master")
+	repo.commit("master", "a.txt", "a\nmaster\nc\n", "change on master")
+	sha := repo.commit("r1", "b.txt", "b\n", "feat: add b")
+	ctx := context.Background()
+	provider := NewProviderWithGit(repo.git)
+	service := pick.NewPickService(provider)
+
+	task := &pick.Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            &sha,
+		MergeRequestID: "1",
+		IsSummary:      true,
+	}
+	if err := service.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("summary err: %v", err)
+	}
+	task.IsSummary = false
+	if err := service.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("pick err: %v", err)
+	}
+
+	for _, branch := range []string{"r2", "master"} {
+		if content := repo.show(branch, "b.txt"); content != "b" {
+			t.Fatalf("%s content: %q", branch, content)
+		}
+	}
+	comments, _ := provider.Comment().Find(ctx, &tp.FindCommentOption{MergeRequestID: "1"})
+	if len(comments) != 2 || !strings.Contains(comments[1].Body(), tp.CherryPickResultFlag) {
+		t.Fatalf("comments: %+v", comments)
+	}
+}
@@ -0,0 +1,121 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+type ReferenceService struct {
+	git *Git
+}
+
+func NewReferenceService(git *Git) *ReferenceService {
+	return &ReferenceService{
+		git: git,
+	}
+}
+
+// Get reference, "main", "heads/main" and "refs/heads/main" are the same branch
+func (s *ReferenceService) Get(ctx context.Context, opt *tp.GetRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Ref)
+	sha, err := s.git.ResolveCommit(ctx, ref)
+	if err != nil {
+		logrus.Debugf("Get Reference %s: %v", ref, err)
+		return nil, tp.NotFound
+	}
+	return &tp.Reference{Ref: ref, SHA: sha}, nil
+}
+
+// Update updates the reference, only fast-forward is allowed without Force.
+func (s *ReferenceService) Update(ctx context.Context, opt *tp.UpdateOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	if opt.Force && opt.ExpectedSHA == "" {
+		return nil, tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Ref)
+	current, err := s.git.ResolveCommit(ctx, ref)
+	if err != nil {
+		return nil, tp.NotFound
+	}
+	if opt.ExpectedSHA != "" && current != opt.ExpectedSHA {
+		return nil, tp.ErrStaleRef
+	}
+	if !opt.Force {
+		if _, err = s.git.Run(ctx, "merge-base", "--is-ancestor", current, opt.SHA); err != nil {
+			return nil, fmt.Errorf("reference: %s update is not a fast forward", ref)
+		}
+	}
+	// compare-and-swap, fails if the branch is moved after it is resolved
+	if _, err = s.git.Run(ctx, "update-ref", ref, opt.SHA, current); err != nil {
+		logrus.Errorf("Update Reference Error: %v", err)
+		return nil, tp.ErrStaleRef
+	}
+	return &tp.Reference{Ref: ref, SHA: opt.SHA}, nil
+}
+
+// Create creates the branch at the commit, it fails if the branch exists.
+func (s *ReferenceService) Create(ctx context.Context, opt *tp.CreateRefOption) (*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Ref)
+	if _, err := s.git.ResolveCommit(ctx, ref); err == nil {
+		return nil, tp.ErrAlreadyExists
+	}
+	sha, err := s.git.ResolveCommit(ctx, opt.SHA)
+	if err != nil {
+		return nil, tp.NotFound
+	}
+	// the empty old value makes git refuse to overwrite a branch created in the meantime
+	if _, err = s.git.Run(ctx, "update-ref", ref, sha, ""); err != nil {
+		logrus.Errorf("Create Reference Error: %v", err)
+		return nil, err
+	}
+	return &tp.Reference{Ref: ref, SHA: sha}, nil
+}
+
+// List returns the branches whose name starts with the prefix
+func (s *ReferenceService) List(ctx context.Context, opt *tp.ListRefOption) ([]*tp.Reference, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	out, err := s.git.Run(ctx, "for-each-ref", "--format=%(refname) %(objectname)", "refs/heads/")
+	if err != nil {
+		logrus.Errorf("List Reference Error: %v", err)
+		return nil, err
+	}
+	var refs []*tp.Reference
+	for _, line := range strings.Split(out, "\n") {
+		ref, sha, ok := strings.Cut(line, " ")
+		if ok && strings.HasPrefix(ref, "refs/heads/"+opt.Prefix) {
+			refs = append(refs, &tp.Reference{Ref: ref, SHA: sha})
+		}
+	}
+	return refs, nil
+}
+
+// Delete deletes the branch
+func (s *ReferenceService) Delete(ctx context.Context, opt *tp.DeleteRefOption) error {
+	if opt == nil {
+		return tp.ErrInvalidOptions
+	}
+	ref := branchRef(opt.Ref)
+	sha, err := s.git.ResolveCommit(ctx, ref)
+	if err != nil {
+		return tp.NotFound
+	}
+	// compare-and-swap, a branch moved after it is resolved is kept
+	if _, err = s.git.Run(ctx, "update-ref", "-d", ref, sha); err != nil {
+		logrus.Errorf("Delete Reference Error: %v", err)
+		return err
+	}
+	return nil
+}
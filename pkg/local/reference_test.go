@@ -0,0 +1,77 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"testing"
+)
+
+func TestReferenceService(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.branch("r1", "master")
+	base := repo.run("rev-parse", "master")
+	head := repo.commit("master", "b.txt", "b\n", "feat: add b")
+	ctx := context.Background()
+	service := NewReferenceService(repo.git)
+
+	ref, err := service.Get(ctx, &tp.GetRefOption{Ref: "heads/r1"})
+	if err != nil || ref.Ref != "refs/heads/r1" || ref.SHA != base {
+		t.Fatalf("get: %v %+v", err, ref)
+	}
+
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: head, ExpectedSHA: head}); err != tp.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	// fast-forward
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: head, ExpectedSHA: base}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// not a fast-forward
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: base}); err == nil {
+		t.Fatalf("update is not a fast forward")
+	}
+	// reset
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: base, ExpectedSHA: base, Force: true}); err != tp.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: base, ExpectedSHA: head, Force: true}); err != nil || repo.run("rev-parse", "r1") != base {
+		t.Fatalf("reset: %v", err)
+	}
+	if _, err = service.Update(ctx, &tp.UpdateOption{Ref: "refs/heads/r1", SHA: head, ExpectedSHA: base}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	ref, err = service.Create(ctx, &tp.CreateRefOption{Ref: "refs/heads/backport/1-r1", SHA: base})
+	if err != nil || ref.Ref != "refs/heads/backport/1-r1" || ref.SHA != base {
+		t.Fatalf("create: %v %+v", err, ref)
+	}
+	if _, err = service.Create(ctx, &tp.CreateRefOption{Ref: "r1", SHA: base}); err != tp.ErrAlreadyExists {
+		t.Fatalf("err = %v, want already exists", err)
+	}
+
+	if _, err = service.Get(ctx, &tp.GetRefOption{Ref: "missing"}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestReferenceService_ListDelete(t *testing.T) {
+	repo := newTestRepo(t)
+	temp := tp.TempRef(tp.NewRunID(), "pick-r1-012345678")
+	repo.run("update-ref", temp, "master")
+	ctx := context.Background()
+	service := NewReferenceService(repo.git)
+
+	refs, err := service.List(ctx, &tp.ListRefOption{Prefix: tp.TempRefPrefix})
+	if err != nil || len(refs) != 1 || refs[0].Ref != temp || refs[0].SHA != repo.run("rev-parse", "master") {
+		t.Fatalf("err: %v refs: %+v", err, refs)
+	}
+	if err = service.Delete(ctx, &tp.DeleteRefOption{Ref: temp}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if refs, _ = service.List(ctx, &tp.ListRefOption{Prefix: tp.TempRefPrefix}); len(refs) != 0 {
+		t.Fatalf("refs: %+v", refs)
+	}
+	if err = service.Delete(ctx, &tp.DeleteRefOption{Ref: temp}); err != tp.NotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,94 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"path/filepath"
+	"strings"
+)
+
+type Repository struct {
+	name          string
+	fullName      string
+	gitUrl        string
+	defaultBranch string
+}
+
+type RepositoryService struct {
+	git *Git
+}
+
+func NewRepositoryService(git *Git) *RepositoryService {
+	return &RepositoryService{
+		git: git,
+	}
+}
+
+// Get returns the local clone, the default branch is the HEAD of origin or the local HEAD
+func (r *RepositoryService) Get(ctx context.Context, opt *tp.GetRepositoryOption) (tp.Repository, error) {
+	if opt == nil {
+		return nil, tp.ErrInvalidOptions
+	}
+	gitDir, err := r.git.GitDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(gitDir)
+	if name == ".git" {
+		name = filepath.Base(filepath.Dir(gitDir))
+	}
+	name = strings.TrimSuffix(name, ".git")
+
+	fullName := opt.Repo
+	if fullName == "" {
+		fullName = name
+	}
+	gitUrl, _ := r.git.Run(ctx, "remote", "get-url", "origin")
+
+	defaultBranch, err := r.git.Run(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err == nil {
+		defaultBranch = strings.TrimPrefix(defaultBranch, "origin/")
+	} else {
+		defaultBranch, _ = r.git.Run(ctx, "symbolic-ref", "--short", "HEAD")
+	}
+
+	return &Repository{
+		name:          name,
+		fullName:      fullName,
+		gitUrl:        gitUrl,
+		defaultBranch: defaultBranch,
+	}, nil
+}
+
+func (r *Repository) Name() string {
+	return r.name
+}
+
+func (r *Repository) FullName() string {
+	return r.fullName
+}
+
+func (r *Repository) GitUrl() string {
+	return r.gitUrl
+}
+
+func (r *Repository) DefaultBranch() string {
+	return r.defaultBranch
+}
+
+// AllowSquashMerge merge settings are unknown for a local clone
+func (r *Repository) AllowSquashMerge() *bool {
+	return nil
+}
+
+func (r *Repository) DeleteBranchOnMerge() *bool {
+	return nil
+}
+
+func (r *Repository) AllowRebaseMerge() *bool {
+	return nil
+}
+
+func (r *Repository) Private() *bool {
+	return nil
+}
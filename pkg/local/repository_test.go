@@ -0,0 +1,19 @@
+package local
+
+import (
+	"context"
+	tp "github.com/kentio/norn/pkg/types"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryService_Get(t *testing.T) {
+	repo := newTestRepo(t)
+	result, err := NewRepositoryService(repo.git).Get(context.Background(), &tp.GetRepositoryOption{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if result.Name() != filepath.Base(repo.git.Path) || result.DefaultBranch() != "master" {
+		t.Fatalf("repo: %+v", result)
+	}
+}
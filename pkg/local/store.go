@@ -0,0 +1,99 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// storeFile is the notes file of merge requests and comments, relative to the git dir
+const storeFile = "norn/merge-requests.json"
+
+// Store keeps merge requests and their comments in a notes file inside the git dir,
+// so they survive between runs without a forge.
+type Store struct {
+	mu  sync.Mutex
+	git *Git
+}
+
+type storeData struct {
+	NextID        int                            `json:"next_id"`
+	MergeRequests map[string]*mergeRequestRecord `json:"merge_requests"`
+}
+
+type mergeRequestRecord struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	State       string           `json:"state"`
+	Head        string           `json:"head,omitempty"` // source branch
+	Base        string           `json:"base,omitempty"` // target branch
+	Labels      []string         `json:"labels,omitempty"`
+	Comments    []*commentRecord `json:"comments"`
+}
+
+type commentRecord struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+}
+
+func NewStore(git *Git) *Store {
+	return &Store{git: git}
+}
+
+// View loads the notes file and calls fn with it
+func (s *Store) View(ctx context.Context, fn func(data *storeData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, data, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	return fn(data)
+}
+
+// Update loads the notes file, calls fn and writes it back if fn succeeds
+func (s *Store) Update(ctx context.Context, fn func(data *storeData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, data, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if err = fn(data); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+func (s *Store) load(ctx context.Context) (string, *storeData, error) {
+	gitDir, err := s.git.GitDir(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	path := filepath.Join(gitDir, storeFile)
+	data := &storeData{MergeRequests: map[string]*mergeRequestRecord{}}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return path, data, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if err = json.Unmarshal(content, data); err != nil {
+		return "", nil, err
+	}
+	if data.MergeRequests == nil {
+		data.MergeRequests = map[string]*mergeRequestRecord{}
+	}
+	return path, data, nil
+}
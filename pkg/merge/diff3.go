@@ -0,0 +1,236 @@
+package merge
+
+import (
+	"bytes"
+	"slices"
+)
+
+// maxEditDistance bounds the work of diff, which takes time by the lines times the edit distance.
+// The lines between the common prefix and suffix of files which differ more are not matched, which
+// only makes a conflict more likely.
+const maxEditDistance = 4096
+
+// Merge3 merges the changes from base to ours and from base to theirs line by line like diff3. It
+// returns false if both change the same lines, or adjacent lines, differently.
+func Merge3(base, ours, theirs []byte) ([]byte, bool) {
+	switch {
+	case bytes.Equal(ours, theirs), bytes.Equal(base, theirs):
+		return ours, true
+	case bytes.Equal(base, ours):
+		return theirs, true
+	}
+	ids := map[string]int{}
+	b, o, t := splitLines(base, ids), splitLines(ours, ids), splitLines(theirs, ids)
+	lines := make([]string, len(ids))
+	for line, id := range ids {
+		lines[id] = line
+	}
+	mo, mt := matchIndex(len(b), diff(b, o)), matchIndex(len(b), diff(b, t))
+
+	var out bytes.Buffer
+	write := func(ids []int) {
+		for _, id := range ids {
+			out.WriteString(lines[id])
+		}
+	}
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		// stable, the lines are unchanged on both sides
+		n := 0
+		for i+n < len(b) && mo[i+n] == j+n && mt[i+n] == k+n {
+			n++
+		}
+		if n > 0 {
+			write(b[i : i+n])
+			i, j, k = i+n, j+n, k+n
+			continue
+		}
+		// unstable, up to the next base line kept by both sides
+		l, jo, kt := i, len(o), len(t)
+		for ; l < len(b); l++ {
+			if mo[l] >= 0 && mt[l] >= 0 {
+				jo, kt = mo[l], mt[l]
+				break
+			}
+		}
+		bc, oc, tc := b[i:l], o[j:jo], t[k:kt]
+		switch {
+		case slices.Equal(oc, tc), slices.Equal(bc, tc):
+			write(oc)
+		case slices.Equal(bc, oc):
+			write(tc)
+		default:
+			return nil, false
+		}
+		i, j, k = l, jo, kt
+	}
+	return out.Bytes(), true
+}
+
+// splitLines splits the content after each newline and returns the ids of the lines
+func splitLines(content []byte, ids map[string]int) []int {
+	var lines []int
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n') + 1
+		if end == 0 {
+			end = len(content)
+		}
+		line := string(content[:end])
+		id, ok := ids[line]
+		if !ok {
+			id = len(ids)
+			ids[line] = id
+		}
+		lines = append(lines, id)
+		content = content[end:]
+	}
+	return lines
+}
+
+// matchIndex returns the index of the matched line in the other file by the line in a, -1 if unmatched
+func matchIndex(n int, matches [][2]int) []int {
+	index := make([]int, n)
+	for i := range index {
+		index[i] = -1
+	}
+	for _, m := range matches {
+		index[m[0]] = m[1]
+	}
+	return index
+}
+
+// diff returns the pairs of matching lines of a and b in order, the longest common subsequence
+func diff(a, b []int) [][2]int {
+	var matches [][2]int
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches = append(matches, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, m := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		matches = append(matches, [2]int{m[0] + prefix, m[1] + prefix})
+	}
+	for s := suffix; s > 0; s-- {
+		matches = append(matches, [2]int{len(a) - s, len(b) - s})
+	}
+	return matches
+}
+
+// myers matches the lines of a and b by the shortest edit script of the Myers diff, nil if the edit
+// distance is above maxEditDistance. The files are split at the middle of the edit script and each
+// half is matched on its own, so the memory is linear in the lines.
+func myers(a, b []int) [][2]int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	x, y, ok := split(a, b, maxEditDistance)
+	if !ok {
+		return nil
+	}
+	var matches [][2]int
+	lcs(a[:x], b[:y], 0, 0, &matches)
+	lcs(a[x:], b[y:], x, y, &matches)
+	return matches
+}
+
+// lcs appends the matching lines of a and b in order, the lines are numbered from x in a and y in b
+func lcs(a, b []int, x, y int, matches *[][2]int) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		*matches = append(*matches, [2]int{x, y})
+		a, b, x, y = a[1:], b[1:], x+1, y+1
+	}
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	n, m := len(a)-suffix, len(b)-suffix
+	if n > 0 && m > 0 {
+		if sx, sy, ok := split(a[:n], b[:m], -1); ok {
+			lcs(a[:sx], b[:sy], x, y, matches)
+			lcs(a[sx:n], b[sy:m], x+sx, y+sy, matches)
+		}
+	}
+	for s := 0; s < suffix; s++ {
+		*matches = append(*matches, [2]int{x + n + s, y + m + s})
+	}
+}
+
+// split searches the shortest edit script from both ends at once and returns the point where the
+// paths meet, which is on a shortest edit script. It returns false if a and b have no common line,
+// or if the edit distance is above the limit when the limit is not negative. The first lines and
+// the last lines of a and b must differ.
+func split(a, b []int, limit int) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[offset+k] is the furthest x on diagonal k from the start, backward from the end
+	forward, backward := make([]int, 2*maxD+2), make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// with an odd delta the paths meet on a forward step, otherwise on a backward step
+	odd := delta%2 != 0
+	// the diagonals off the edit graph are skipped
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		if limit >= 0 && 2*d-1 > limit {
+			return 0, 0, false
+		}
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				at := offset + delta - k
+				if at >= 0 && at < len(backward) && backward[at] != -1 && x >= n-backward[at] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x, y = x+1, y+1
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				at := offset + delta - k
+				if at >= 0 && at < len(forward) && forward[at] != -1 && forward[at] >= n-x {
+					fx := forward[at]
+					return fx, fx - (at - offset), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
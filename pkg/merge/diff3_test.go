@@ -0,0 +1,146 @@
+package merge
+
+import (
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const base3 = "a\nb\nc\nd\ne\nf\ng\n"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name         string
+		ours, theirs string
+		want         string
+		conflict     bool
+	}{
+		{name: "unchanged", ours: base3, theirs: base3, want: base3},
+		{name: "ours only", ours: "a\nB\nc\nd\ne\nf\ng\n", theirs: base3, want: "a\nB\nc\nd\ne\nf\ng\n"},
+		{name: "theirs only", ours: base3, theirs: "a\nb\nc\nd\ne\nF\ng\n", want: "a\nb\nc\nd\ne\nF\ng\n"},
+		{name: "both apart", ours: "a\nB\nc\nd\ne\nf\ng\n", theirs: "a\nb\nc\nd\ne\nF\ng\n", want: "a\nB\nc\nd\ne\nF\ng\n"},
+		{name: "same change", ours: "a\nB\nc\nd\ne\nf\ng\n", theirs: "a\nB\nc\nd\ne\nf\ng\n", want: "a\nB\nc\nd\ne\nf\ng\n"},
+		{name: "insert and delete", ours: "0\na\nb\nc\nd\ne\nf\ng\n", theirs: "a\nb\nc\ne\nf\ng\n", want: "0\na\nb\nc\ne\nf\ng\n"},
+		{name: "append", ours: "a\nb\nc\nd\ne\nf\ng\nh\n", theirs: "A\nb\nc\nd\ne\nf\ng\n", want: "A\nb\nc\nd\ne\nf\ng\nh\n"},
+		{name: "same line", ours: "a\nb\nC\nd\ne\nf\ng\n", theirs: "a\nb\nc!\nd\ne\nf\ng\n", conflict: true},
+		{name: "adjacent lines", ours: "a\nb\nC\nd\ne\nf\ng\n", theirs: "a\nb\nc\nD\ne\nf\ng\n", conflict: true},
+		{name: "insert at same place", ours: "a\nb\nx\nc\nd\ne\nf\ng\n", theirs: "a\nb\ny\nc\nd\ne\nf\ng\n", conflict: true},
+		{name: "no final newline", ours: "a\nB\nc\nd\ne\nf\ng\n", theirs: "a\nb\nc\nd\ne\nf\ng", want: "a\nB\nc\nd\ne\nf\ng"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Merge3([]byte(base3), []byte(tt.ours), []byte(tt.theirs))
+			if ok == tt.conflict {
+				t.Fatalf("ok = %v, want conflict %v", ok, tt.conflict)
+			}
+			if ok && string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge3_EmptyBase(t *testing.T) {
+	if got, ok := Merge3(nil, []byte("a\n"), []byte("a\n")); !ok || string(got) != "a\n" {
+		t.Errorf("got %q %v", got, ok)
+	}
+	if _, ok := Merge3(nil, []byte("a\n"), []byte("b\n")); ok {
+		t.Errorf("different additions are merged")
+	}
+}
+
+// TestMerge3_GitMergeFile compares the merge of longer files with git merge-file
+func TestMerge3_GitMergeFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	var base []string
+	for i := 0; i < 200; i++ {
+		base = append(base, strings.Repeat(string(rune('a'+i%26)), 1+i%3))
+	}
+	edit := func(f func(lines []string) []string) string {
+		return strings.Join(f(append([]string(nil), base...)), "\n") + "\n"
+	}
+	ours := edit(func(l []string) []string {
+		l[10] = "ours"
+		l = append(l[:50], l[55:]...)
+		return append(l[:120], append([]string{"x", "y"}, l[120:]...)...)
+	})
+	theirs := edit(func(l []string) []string {
+		l[30] = "theirs"
+		l[150] = "theirs"
+		return append(l[:199], "z")
+	})
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"base": strings.Join(base, "\n") + "\n", "ours": ours, "theirs": theirs} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	want, err := exec.Command("git", "merge-file", "-p", filepath.Join(dir, "ours"), filepath.Join(dir, "base"), filepath.Join(dir, "theirs")).Output()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	got, ok := Merge3([]byte(strings.Join(base, "\n")+"\n"), []byte(ours), []byte(theirs))
+	if !ok || string(got) != string(want) {
+		t.Errorf("ok = %v, got:\n%s\nwant:\n%s", ok, got, want)
+	}
+}
+
+// TestDiff compares the length of the matches of diff with the longest common subsequence
+func TestDiff(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := make([]int, rng.Intn(40)), make([]int, rng.Intn(40))
+		for j := range a {
+			a[j] = rng.Intn(4)
+		}
+		for j := range b {
+			b[j] = rng.Intn(4)
+		}
+		matches := diff(a, b)
+		for j, m := range matches {
+			if a[m[0]] != b[m[1]] || (j > 0 && (m[0] <= matches[j-1][0] || m[1] <= matches[j-1][1])) {
+				t.Fatalf("a %v b %v: bad matches %v", a, b, matches)
+			}
+		}
+		if want := lcsLength(a, b); len(matches) != want {
+			t.Fatalf("a %v b %v: %d matches, want %d", a, b, len(matches), want)
+		}
+	}
+}
+
+func TestDiff_MaxEditDistance(t *testing.T) {
+	a, b := make([]int, maxEditDistance+2), make([]int, maxEditDistance+2)
+	for i := range a {
+		a[i], b[i] = i, -i-1
+	}
+	a[len(a)/2], b[len(b)/2] = -1, -1
+	if matches := diff(a, b); matches != nil {
+		t.Errorf("matches beyond the edit distance: %v", matches)
+	}
+	if matches := diff(a[:100], b[:100]); len(matches) != 0 {
+		t.Errorf("matches of different lines: %v", matches)
+	}
+}
+
+func lcsLength(a, b []int) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
@@ -0,0 +1,181 @@
+package merge
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// modes of the tree entries, as git writes them
+const (
+	ModeFile       = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+	ModeSubmodule  = "160000"
+)
+
+// Entry is a file of a tree, a blob, a symlink or a submodule
+type Entry struct {
+	Mode string
+	SHA  string
+}
+
+// Tree is a recursive tree by path, the directories are not in it
+type Tree map[string]Entry
+
+// Change is a change of a path of ours
+type Change struct {
+	Path string
+	Mode string // the mode of ours for a deleted path
+	// SHA is the object of the path, empty if Content is set or the path is deleted
+	SHA string
+	// Content is the content merged from both sides, a blob which does not exist yet
+	Content []byte
+	Delete  bool
+}
+
+type Result struct {
+	Changes   []Change // sorted by path
+	Conflicts []string // the conflicting paths, sorted
+}
+
+// Reader returns the content of the blob
+type Reader func(sha string) ([]byte, error)
+
+// Trees merges the change from base to theirs into ours and returns the changes to ours. A path
+// changed on both sides is merged by Merge3, it conflicts if the lines conflict, if either side is
+// binary, a symlink or a submodule, if it is deleted on one side only, or if both change its mode.
+func Trees(base, ours, theirs Tree, read Reader) (*Result, error) {
+	paths := map[string]bool{}
+	for _, tree := range []Tree{base, ours, theirs} {
+		for path := range tree {
+			paths[path] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	slices.Sort(sorted)
+
+	result := &Result{}
+	for _, path := range sorted {
+		b, inBase := base[path]
+		o, inOurs := ours[path]
+		t, inTheirs := theirs[path]
+		switch {
+		case inOurs == inTheirs && o == t, inBase == inTheirs && b == t:
+			// the same on both sides, or unchanged by theirs
+			continue
+		case inBase == inOurs && b == o:
+			// unchanged by ours, theirs is taken
+			if !inTheirs {
+				result.Changes = append(result.Changes, Change{Path: path, Mode: o.Mode, Delete: true})
+				continue
+			}
+			result.Changes = append(result.Changes, Change{Path: path, Mode: t.Mode, SHA: t.SHA})
+			continue
+		case !inOurs || !inTheirs:
+			// modified on one side, deleted on the other
+			result.Conflicts = append(result.Conflicts, path)
+			continue
+		}
+
+		mode, ok := mergeMode(b.Mode, o.Mode, t.Mode)
+		if !ok || (mode != ModeFile && mode != ModeExecutable) {
+			result.Conflicts = append(result.Conflicts, path)
+			continue
+		}
+		if o.SHA == t.SHA {
+			if mode == o.Mode {
+				continue
+			}
+			result.Changes = append(result.Changes, Change{Path: path, Mode: mode, SHA: o.SHA})
+			continue
+		}
+		merged, unchanged, ok, err := mergeBlobs(b, o, t, inBase, read)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			result.Conflicts = append(result.Conflicts, path)
+			continue
+		}
+		if unchanged {
+			// the change of theirs is already in ours
+			if mode != o.Mode {
+				result.Changes = append(result.Changes, Change{Path: path, Mode: mode, SHA: o.SHA})
+			}
+			continue
+		}
+		result.Changes = append(result.Changes, Change{Path: path, Mode: mode, Content: merged})
+	}
+	result.Conflicts = append(result.Conflicts, fileDirectoryConflicts(ours, result.Changes)...)
+	slices.Sort(result.Conflicts)
+	result.Conflicts = slices.Compact(result.Conflicts)
+	return result, nil
+}
+
+// mergeMode returns the mode of the path changed on both sides, false if both change it differently
+func mergeMode(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, base == theirs:
+		return ours, true
+	case base == ours:
+		return theirs, true
+	}
+	return "", false
+}
+
+// mergeBlobs merges the contents of the blob, a blob added on both sides is merged against an
+// empty base. It returns whether the merged content is the content of ours, and false if the
+// contents conflict or are binary.
+func mergeBlobs(base, ours, theirs Entry, inBase bool, read Reader) ([]byte, bool, bool, error) {
+	var contents [3][]byte
+	for i, entry := range []Entry{base, ours, theirs} {
+		if i == 0 && (!inBase || (entry.Mode != ModeFile && entry.Mode != ModeExecutable)) {
+			continue
+		}
+		content, err := read(entry.SHA)
+		if err != nil {
+			return nil, false, false, err
+		}
+		if binary(content) {
+			return nil, false, false, nil
+		}
+		contents[i] = content
+	}
+	merged, ok := Merge3(contents[0], contents[1], contents[2])
+	return merged, ok && bytes.Equal(merged, contents[1]), ok, nil
+}
+
+// binary reports whether the content is binary, like git it looks for a NUL in the first 8000 bytes
+func binary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// fileDirectoryConflicts returns the paths which are a file and a directory after the changes, such
+// as a file added where the other side added a directory of the same name
+func fileDirectoryConflicts(ours Tree, changes []Change) []string {
+	merged := map[string]bool{}
+	for path := range ours {
+		merged[path] = true
+	}
+	for _, change := range changes {
+		merged[change.Path] = !change.Delete
+	}
+	var conflicts []string
+	for path, exists := range merged {
+		if !exists {
+			continue
+		}
+		for dir := path; strings.Contains(dir, "/"); {
+			dir = dir[:strings.LastIndex(dir, "/")]
+			if merged[dir] {
+				conflicts = append(conflicts, dir)
+				break
+			}
+		}
+	}
+	return conflicts
+}
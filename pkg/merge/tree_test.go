@@ -0,0 +1,96 @@
+package merge
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTrees(t *testing.T) {
+	blobs := map[string]string{
+		"b1":  base3,
+		"o1":  "a\nB\nc\nd\ne\nf\ng\n",
+		"t1":  "a\nb\nc\nd\ne\nF\ng\n",
+		"o2":  "a\nb\nC\nd\ne\nf\ng\n",
+		"t2":  "a\nb\nc!\nd\ne\nf\ng\n",
+		"bin": "\x00\x01",
+		"t3":  "theirs\n",
+	}
+	read := func(sha string) ([]byte, error) {
+		content, ok := blobs[sha]
+		if !ok {
+			return nil, fmt.Errorf("blob %s not found", sha)
+		}
+		return []byte(content), nil
+	}
+	file := func(sha string) Entry { return Entry{Mode: ModeFile, SHA: sha} }
+	base := Tree{
+		"merged":         file("b1"),
+		"conflict":       file("b1"),
+		"deleted":        file("b1"),
+		"modify-delete":  file("b1"),
+		"binary":         file("b1"),
+		"chmod":          file("b1"),
+		"theirs/changed": file("b1"),
+	}
+	ours := Tree{
+		"merged":         file("o1"),
+		"conflict":       file("o2"),
+		"deleted":        file("b1"),
+		"modify-delete":  file("o1"),
+		"binary":         file("bin"),
+		"chmod":          file("b1"),
+		"theirs/changed": file("b1"),
+		"ours-only":      file("o1"),
+	}
+	theirs := Tree{
+		"merged":         file("t1"),
+		"conflict":       file("t2"),
+		"binary":         file("t1"),
+		"chmod":          {Mode: ModeExecutable, SHA: "b1"},
+		"theirs/changed": file("t1"),
+		"theirs/added":   file("t3"),
+	}
+	result, err := Trees(base, ours, theirs, read)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	wantChanges := []Change{
+		{Path: "chmod", Mode: ModeExecutable, SHA: "b1"},
+		{Path: "deleted", Mode: ModeFile, Delete: true},
+		{Path: "merged", Mode: ModeFile, Content: []byte("a\nB\nc\nd\ne\nF\ng\n")},
+		{Path: "theirs/added", Mode: ModeFile, SHA: "t3"},
+		{Path: "theirs/changed", Mode: ModeFile, SHA: "t1"},
+	}
+	if !reflect.DeepEqual(result.Changes, wantChanges) {
+		t.Errorf("changes = %+v, want %+v", result.Changes, wantChanges)
+	}
+	if want := []string{"binary", "conflict", "modify-delete"}; !reflect.DeepEqual(result.Conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", result.Conflicts, want)
+	}
+}
+
+func TestTrees_FileDirectory(t *testing.T) {
+	ours := Tree{"docs": {Mode: ModeFile, SHA: "a"}}
+	theirs := Tree{"docs/readme": {Mode: ModeFile, SHA: "b"}}
+	result, err := Trees(Tree{}, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if want := []string{"docs"}; !reflect.DeepEqual(result.Conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", result.Conflicts, want)
+	}
+}
+
+func TestTrees_Symlink(t *testing.T) {
+	base := Tree{"link": {Mode: ModeSymlink, SHA: "a"}}
+	ours := Tree{"link": {Mode: ModeSymlink, SHA: "b"}}
+	theirs := Tree{"link": {Mode: ModeSymlink, SHA: "c"}}
+	result, err := Trees(base, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(result.Changes) != 0 || !reflect.DeepEqual(result.Conflicts, []string{"link"}) {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,125 @@
+package pick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/olekukonko/tablewriter"
+	"github.com/sirupsen/logrus"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type GCOption struct {
+	Repo string
+	// MaxAge keeps the temporary branches of the runs started less than MaxAge ago, they may
+	// still be in use
+	MaxAge time.Duration
+	// IncludeLegacy deletes the branches of the norn versions without run ids too. Their age is
+	// unknown, so they are deleted regardless of MaxAge and only if no such version is running.
+	IncludeLegacy bool
+	// DryRun lists the branches to delete, nothing is deleted
+	DryRun bool
+}
+
+type GCResult struct {
+	Branch  string
+	Created time.Time // the time of the run, zero for a branch of an older norn
+	Status  Status
+	Reason  string
+}
+
+// legacyTempBranch matches the pick-<branch>-<sha> branches of the norn versions without run ids,
+// the other names are too likely to be branches of the users
+var legacyTempBranch = regexp.MustCompile(`^pick-.+-[0-9a-f]{9}$`)
+
+// GC deletes the temporary branches left behind by the runs which crashed, timed out or failed to
+// delete them. The branches of runs younger than MaxAge are skipped. The branches of the norn
+// versions without run ids have no time, they are skipped unless IncludeLegacy is set.
+func (s *Service) GC(ctx context.Context, opt *GCOption) ([]*GCResult, error) {
+	if opt == nil || opt.MaxAge < 0 {
+		return nil, tp.ErrInvalidOptions
+	}
+	refs, err := s.tempRefs(ctx, opt.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []*GCResult
+	for _, ref := range refs {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		_, created, _ := tp.ParseTempRef(ref.Ref)
+		r := &GCResult{Branch: branchName(ref.Ref), Created: created}
+		result = append(result, r)
+		switch {
+		case created.IsZero() && !opt.IncludeLegacy:
+			r.Status, r.Reason = SkipStatus, "branch of an older norn, age unknown"
+			continue
+		case !created.IsZero() && now.Sub(created) < opt.MaxAge:
+			r.Status, r.Reason = SkipStatus, "newer than "+opt.MaxAge.String()
+			continue
+		}
+		if opt.DryRun {
+			r.Status = PendingStatus
+			continue
+		}
+		err = s.provider.Reference().Delete(ctx, &tp.DeleteRefOption{Repo: opt.Repo, Ref: ref.Ref})
+		switch {
+		case err == nil:
+			r.Status = SucceedStatus
+		case errors.Is(err, tp.NotFound):
+			// deleted by its run in the meantime
+			r.Status, r.Reason = SkipStatus, "already deleted"
+		default:
+			logrus.Warnf("Delete temporary branch %s failed: %s", r.Branch, err)
+			r.Status, r.Reason = FailedStatus, err.Error()
+		}
+	}
+	return result, nil
+}
+
+// tempRefs lists the temporary branches of every norn version
+func (s *Service) tempRefs(ctx context.Context, repo string) ([]*tp.Reference, error) {
+	refs, err := s.provider.Reference().List(ctx, &tp.ListRefOption{Repo: repo, Prefix: tp.TempRefPrefix})
+	if err != nil {
+		logrus.Errorf("List temporary branches failed: %s", err)
+		return nil, err
+	}
+	legacy, err := s.provider.Reference().List(ctx, &tp.ListRefOption{Repo: repo, Prefix: "pick-"})
+	if err != nil {
+		logrus.Errorf("List temporary branches failed: %s", err)
+		return nil, err
+	}
+	for _, ref := range legacy {
+		if legacyTempBranch.MatchString(branchName(ref.Ref)) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// branchName trims the ref prefix, "refs/heads/main" returns "main"
+func branchName(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// PrintGC prints the result of GC as a table
+func PrintGC(out io.Writer, result []*GCResult) {
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Branch", "Created", "Status", "Reason"})
+	table.SetAutoWrapText(false)
+	for _, i := range result {
+		created := "unknown"
+		if !i.Created.IsZero() {
+			created = i.Created.Format(time.RFC3339)
+		}
+		table.Append([]string{i.Branch, created, fmt.Sprintf("%s %s", getStateEmoji(i.Status), i.Status), i.Reason})
+	}
+	table.Render()
+}
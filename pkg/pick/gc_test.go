@@ -0,0 +1,76 @@
+package pick
+
+import (
+	"context"
+	"errors"
+	"github.com/kentio/norn/pkg/fake"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	old := "norn/tmp/" + time.Now().Add(-2*time.Hour).UTC().Format("20060102T150405Z") + "-1a2b3c4d/pick-r2-" + sha[:9]
+	recent := strings.TrimPrefix(tp.TempRef(tp.NewRunID(), "pick-r2-"+sha[:9]), "refs/heads/")
+	legacy := "pick-release/1.0-" + sha[:9]
+	user := "revert-release/1.0-" + sha[:9]
+	for _, branch := range []string{old, recent, legacy, "pick-list", user} {
+		provider.CreateBranch(branch, sha)
+	}
+	var out strings.Builder
+	pick := NewPickService(provider)
+	pick.SetOutput(&out)
+
+	result, err := pick.GC(ctx, &GCOption{Repo: "kentio/norn", MaxAge: time.Hour, DryRun: true})
+	if err != nil || len(result) != 3 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	status := map[string]Status{}
+	for _, r := range result {
+		status[r.Branch] = r.Status
+	}
+	if status[old] != PendingStatus || status[recent] != SkipStatus || status[legacy] != SkipStatus {
+		t.Fatalf("status: %v", status)
+	}
+	if provider.Branch(old) == "" {
+		t.Fatalf("dry run deleted %s", old)
+	}
+
+	// the legacy branch has no time, it is kept unless included
+	result, err = pick.GC(ctx, &GCOption{Repo: "kentio/norn", MaxAge: time.Hour})
+	if err != nil || len(result) != 3 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if provider.Branch(old) != "" || provider.Branch(legacy) == "" {
+		t.Fatalf("old: %s legacy: %s", provider.Branch(old), provider.Branch(legacy))
+	}
+	PrintGC(&out, result)
+	if !strings.Contains(out.String(), old) || !strings.Contains(out.String(), "unknown") {
+		t.Fatalf("table: %s", out.String())
+	}
+	result, err = pick.GC(ctx, &GCOption{Repo: "kentio/norn", MaxAge: time.Hour, IncludeLegacy: true})
+	if err != nil || len(result) != 2 || provider.Branch(legacy) != "" {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	// the branch of a running pick and the branches of the user are kept
+	if provider.Branch(recent) == "" || provider.Branch("pick-list") == "" || provider.Branch(user) == "" {
+		t.Fatalf("recent or user branch is deleted")
+	}
+}
+
+func TestGC_DeleteError(t *testing.T) {
+	provider, sha := newFakeRepo()
+	provider.CreateBranch("pick-r2-"+sha[:9], sha)
+	provider.SetError(fake.OpReferenceDelete, errors.New("forbidden"))
+
+	result, err := NewPickService(provider).GC(context.Background(), &GCOption{Repo: "kentio/norn", IncludeLegacy: true})
+	if err != nil || len(result) != 1 || result[0].Status != FailedStatus || result[0].Reason != "forbidden" {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if _, err = NewPickService(provider).GC(context.Background(), &GCOption{MaxAge: -time.Hour}); err != tp.ErrInvalidOptions {
+		t.Fatalf("err = %v, want invalid options", err)
+	}
+}
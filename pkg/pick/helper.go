@@ -6,6 +6,7 @@ import (
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sirupsen/logrus"
+	"io"
 	"strings"
 	"text/template"
 )
@@ -65,14 +66,12 @@ func EqualSlice(a, b []string) bool {
 	return true
 }
 
-// GetCheckConflictMode returns the check conflict mode for the provider
+// GetCheckConflictMode returns the check conflict mode for the provider, every provider checks by its API.
+//
+// Deprecated: the mode no longer depends on the provider, use tp.WithAPI, or tp.WithCommand to check a
+// GitHub pull request by its patch in a clone.
 func GetCheckConflictMode(provider tp.ProviderType) tp.CheckConflictMode {
-	switch provider {
-	case tp.GitHubProvider:
-		return tp.WithCommand
-	default:
-		return tp.WithAPI
-	}
+	return tp.WithAPI
 }
 
 // NewResultComment generate comment content
@@ -84,8 +83,17 @@ func NewResultComment(layout string, result []*TaskResult) (string, error) {
 	}
 	table := tablewriter.NewWriter(&resultContent)
 	table.SetHeader([]string{"Branch", "Status", "Reason"})
+	// markdown cells can not span lines, wrapping breaks the table and links
+	table.SetAutoWrapText(false)
 	for _, i := range result {
 		s := fmt.Sprintf("%s %s", getStateEmoji(i.Status), i.Status)
+		if i.MergeRequestID != "" {
+			// link the backport merge request instead
+			s = fmt.Sprintf("%s Backport #%s", getStateEmoji(i.Status), i.MergeRequestID)
+			if i.MergeRequestUrl != "" {
+				s = fmt.Sprintf("%s [Backport #%s](%s)", getStateEmoji(i.Status), i.MergeRequestID, i.MergeRequestUrl)
+			}
+		}
 		table.Append([]string{i.Branch, s, i.Reason})
 	}
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
@@ -104,6 +112,17 @@ func NewResultComment(layout string, result []*TaskResult) (string, error) {
 	return content.String(), nil
 }
 
+// PrintPlan prints the result of a dry run as a table
+func PrintPlan(out io.Writer, result []*TaskResult) {
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Branch", "Status", "Tree", "Reason"})
+	table.SetAutoWrapText(false)
+	for _, i := range result {
+		table.Append([]string{i.Branch, fmt.Sprintf("%s %s", getStateEmoji(i.Status), i.Status), i.Tree, i.Reason})
+	}
+	table.Render()
+}
+
 // NewSummaryComment NewSelectComment generate comment content
 func NewSummaryComment(layout string, branches []string) (string, error) {
 	var taskBranchLine strings.Builder
@@ -126,6 +145,14 @@ func NewSummaryComment(layout string, branches []string) (string, error) {
 	return content.String(), nil
 }
 
+// shortSHA returns the abbreviated sha
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 // getStateEmoji returns the emoji for the state
 func getStateEmoji(state Status) string {
 	switch state {
@@ -37,3 +37,11 @@ func TestNewResultComment(t *testing.T) {
 	}
 	t.Logf("comment: \n%s", comment)
 }
+
+func TestGetCheckConflictMode(t *testing.T) {
+	for _, provider := range []tp.ProviderType{tp.GitHubProvider, tp.GitlabProvider, tp.GiteaProvider} {
+		if mode := GetCheckConflictMode(provider); mode != tp.WithAPI {
+			t.Errorf("GetCheckConflictMode(%s) = %v, want %v", provider, mode, tp.WithAPI)
+		}
+	}
+}
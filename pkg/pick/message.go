@@ -0,0 +1,119 @@
+package pick
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// MessageData is the data of the message template of the pick commits
+type MessageData struct {
+	Message      string       // the message of the picked commit
+	Subject      string       // the first line of the message
+	Body         string       // the message without the subject and the trailers
+	Trailers     []string     // the trailer lines of the message, such as "Signed-off-by: name <email>"
+	SHA          string       // the full sha of the picked commit
+	ShortSHA     string       // the sha abbreviated to 7 characters
+	MergeRequest string       // the merge request id
+	Source       string       // the source branch
+	Target       string       // the target branch
+	Author       tp.Signature // the author of the picked commit
+}
+
+// trailerLine matches a line of a trailer block, like git interpret-trailers
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: .+$|^\(cherry picked from commit [0-9a-f]+\)$`)
+
+// NewMessageTemplate parses the message template of the pick commits, see MessageData
+func NewMessageTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return tpl, nil
+}
+
+// NewMessageData returns the data of the message of the commit picked to the target
+func NewMessageData(commit tp.Commit, task *Task, target string) *MessageData {
+	subject, body, trailers := splitMessage(commit.Message())
+	return &MessageData{
+		Message:      strings.TrimSpace(commit.Message()),
+		Subject:      subject,
+		Body:         body,
+		Trailers:     trailers,
+		SHA:          commit.SHA(),
+		ShortSHA:     shortSHA(commit.SHA()),
+		MergeRequest: task.MergeRequestID,
+		Source:       task.From,
+		Target:       target,
+		Author:       commit.Author(),
+	}
+}
+
+// RenderMessage renders the message, the blank lines left by empty fields are removed
+func RenderMessage(tpl *template.Template, data *MessageData) (string, error) {
+	var message strings.Builder
+	if err := tpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("render message of %s: %w", data.ShortSHA, err)
+	}
+	return cleanMessage(message.String()), nil
+}
+
+// pickMessages renders the messages of the commits picked to the branch, nil without a template
+func (s *Service) pickMessages(ctx context.Context, task *Task, branch string, shas []string) ([]string, error) {
+	if task.MessageTemplate == nil {
+		return nil, nil
+	}
+	messages := make([]string, 0, len(shas))
+	for _, sha := range shas {
+		commit, err := s.provider.Commit().Get(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: sha})
+		if err != nil {
+			return nil, err
+		}
+		message, err := RenderMessage(task.MessageTemplate, NewMessageData(commit, task, branch))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// splitMessage splits the message into the subject, the body and the trailers of its last paragraph
+func splitMessage(message string) (string, string, []string) {
+	subject, rest, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	paragraphs := strings.Split(strings.TrimSpace(rest), "\n\n")
+	last := strings.Split(strings.TrimSpace(paragraphs[len(paragraphs)-1]), "\n")
+	for _, line := range last {
+		if !trailerLine.MatchString(strings.TrimSpace(line)) {
+			return strings.TrimSpace(subject), strings.TrimSpace(rest), nil
+		}
+	}
+	trailers := make([]string, 0, len(last))
+	for _, line := range last {
+		trailers = append(trailers, strings.TrimSpace(line))
+	}
+	body := strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body), trailers
+}
+
+// cleanMessage strips trailing spaces and repeated blank lines, like git commit --cleanup=whitespace
+func cleanMessage(message string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,113 @@
+package pick
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/common"
+	"github.com/kentio/norn/pkg/fake"
+	tp "github.com/kentio/norn/pkg/types"
+	"reflect"
+	"testing"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		subject  string
+		body     string
+		trailers []string
+	}{
+		{message: "fix: bug", subject: "fix: bug"},
+		{message: "fix: bug\n\nbody\n", subject: "fix: bug", body: "body"},
+		{
+			message:  "fix: bug\n\nfirst\n\nsecond\n\nSigned-off-by: a <a@example.com>\nCo-authored-by: b <b@example.com>\n",
+			subject:  "fix: bug",
+			body:     "first\n\nsecond",
+			trailers: []string{"Signed-off-by: a <a@example.com>", "Co-authored-by: b <b@example.com>"},
+		},
+		{message: "fix: bug\n\n(cherry picked from commit 0123456)", subject: "fix: bug", trailers: []string{"(cherry picked from commit 0123456)"}},
+		{message: "fix: bug\n\nnote: not a trailer\nbecause of this line", subject: "fix: bug", body: "note: not a trailer\nbecause of this line"},
+	}
+	for _, tt := range tests {
+		subject, body, trailers := splitMessage(tt.message)
+		if subject != tt.subject || body != tt.body || !reflect.DeepEqual(trailers, tt.trailers) {
+			t.Errorf("splitMessage(%q) = %q, %q, %q", tt.message, subject, body, trailers)
+		}
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	tpl, err := NewMessageTemplate("[{{ .Target }}] {{ .Subject }}\n\n{{ .Body }}\n\n" +
+		"{{ range .Trailers }}{{ . }}\n{{ end }}" +
+		"Backport-Of: {{ .SHA }} (!{{ .MergeRequest }} from {{ .Source }})\n" +
+		"Co-authored-by: {{ .Author.Name }} <{{ .Author.Email }}>\n")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	data := &MessageData{
+		Subject:      "fix: bug",
+		Trailers:     []string{"Signed-off-by: a <a@example.com>"},
+		SHA:          "0123456789",
+		MergeRequest: "12",
+		Source:       "main",
+		Target:       "release/1.0",
+		Author:       tp.Signature{Name: "b", Email: "b@example.com"},
+	}
+	message, err := RenderMessage(tpl, data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the empty body leaves no blank lines, the trailers are one block
+	want := "[release/1.0] fix: bug\n\n" +
+		"Signed-off-by: a <a@example.com>\n" +
+		"Backport-Of: 0123456789 (!12 from main)\n" +
+		"Co-authored-by: b <b@example.com>"
+	if message != want {
+		t.Fatalf("message = %q, want %q", message, want)
+	}
+
+	if _, err = NewMessageTemplate("{{ .Subject "); err == nil {
+		t.Fatalf("invalid template is parsed")
+	}
+	tpl, _ = NewMessageTemplate("{{ .Missing }}")
+	if _, err = RenderMessage(tpl, data); err == nil {
+		t.Fatalf("unknown field is rendered")
+	}
+}
+
+func TestPerformPickToBranches_MessageTemplate(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	tpl, err := NewMessageTemplate("{{ .Message }}\n\nBackport-Of: {{ .SHA }}\nSigned-off-by: {{ .Author.Name }} <{{ .Author.Email }}>\n(cherry picked from commit {{ .ShortSHA }})")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	task := &Task{
+		Repo:            "kentio/norn",
+		Branches:        []string{"r1", "r2"},
+		From:            "r1",
+		SHA:             common.String(sha),
+		MergeRequestID:  "1",
+		MessageTemplate: tpl,
+	}
+	pick := NewPickService(provider)
+	if err = pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 1 || result[0].Status != SucceedStatus {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	want := "fix: bug\n\nBackport-Of: " + sha + "\nSigned-off-by: " + fake.Author.Name + " <" + fake.Author.Email + ">\n(cherry picked from commit " + sha[:7] + ")"
+	if message := provider.CommitMessage("r2"); message != want {
+		t.Fatalf("message = %q, want %q", message, want)
+	}
+
+	// the pick is found by the trailer of the template
+	r2 := provider.Branch("r2")
+	result, err = pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || result[0].Status != SkipStatus || provider.Branch("r2") != r2 {
+		t.Fatalf("err: %v result: %+v", err, result[0])
+	}
+}
@@ -3,15 +3,22 @@ package pick
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/kentio/norn/internal"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 )
 
 type Service struct {
 	provider tp.Provider
+	out      io.Writer // the plan of a dry run is printed to
 }
 
 type CherryPickOptions struct {
@@ -20,13 +27,23 @@ type CherryPickOptions struct {
 	Target   string // target branch
 	RepoPath string
 	Pr       int
+	Mainline int // parent number of a merge commit
+	DryRun   bool
+	Revert   bool   // revert the commit instead of picking it
+	Message  string // message of the pick commit, the default of the provider if empty
+	// Committer and DateMode of the pick commit, see types.PickOption
+	Committer *tp.Signature
+	DateMode  tp.DateMode
 }
 
+// maxPickAttempts bounds the picks of a commit when the branch is updated concurrently
+const maxPickAttempts = 3
+
 type Mode int
 
 const (
-	MergeRequest Mode = iota
-	CheeryPick
+	CheeryPick   Mode = iota // pick the commit of Task.SHA
+	MergeRequest             // pick every commit of the merge request in order
 )
 
 type Task struct {
@@ -38,6 +55,27 @@ type Task struct {
 	IsSummary      bool // generate summary comment
 	PickMode       Mode
 	RepoPath       string
+	Mainline       int // parent number to pick merge commits against, like git cherry-pick -m
+	// Backport picks to backport/<merge request id>-<branch> and opens a merge request against the branch
+	// instead of updating the branch.
+	Backport bool
+	// Concurrency is the number of branches picked in parallel, 1 if not set
+	Concurrency int
+	// Cascade picks to the branches in order, each branch picks the commits picked to the previous
+	// branch. The branches are picked one by one, Concurrency is ignored.
+	Cascade bool
+	// DryRun computes the picks and prints the plan, no branch is updated and no comment is posted.
+	// Each commit is checked against the head of the branch, so a commit depending on an earlier
+	// commit of the merge request may be planned as a conflict.
+	DryRun bool
+	// MessageTemplate renders the message of the pick commits from MessageData, the provider
+	// default "<message>\n\n(cherry picked from commit <sha7>)" if nil. Keep the trailer in the
+	// template, commits already picked are found by it. The local engine appends it if missing.
+	MessageTemplate *template.Template
+	// Committer commits the picks instead of the committer of the picked commit, the author is kept
+	Committer *tp.Signature
+	// DateMode sets the committer date of the picks, the time of the pick if empty
+	DateMode tp.DateMode
 }
 
 type Status string
@@ -53,10 +91,21 @@ type TaskResult struct {
 	Status Status
 	Branch string
 	Reason string
+	Commit string // the commit failed to pick
+	Tree   string // the tree of the last pick, reported in dry run
+
+	// the merge request opened in backport mode
+	MergeRequestID  string
+	MergeRequestUrl string
 }
 
 func NewPickService(provider tp.Provider) *Service {
-	return &Service{provider: provider}
+	return &Service{provider: provider, out: os.Stdout}
+}
+
+// SetOutput sets the writer the plan of a dry run is printed to, os.Stdout by default
+func (s *Service) SetOutput(out io.Writer) {
+	s.out = out
 }
 
 func (s *Service) FindCommentWithTask(ctx context.Context, task *Task, flag string) ([]tp.Comment, tp.Comment, error) {
@@ -88,9 +137,25 @@ func (s *Service) PerformPickToBranches(ctx context.Context, task *Task, comment
 
 	logrus.Infof("Selected branches: %s", selected)
 
-	// PerformPick commits from one branch to another
+	// commits to pick, in order
+	shas, err := s.commitsOfTask(ctx, task)
+	if err != nil {
+		logrus.Errorf("Get commits to pick failed: %s", err)
+		return nil, err
+	}
+
+	// the backport merge requests are derived from the source merge request
+	var source tp.MergeRequest
+	if task.Backport && !task.DryRun {
+		source, err = s.provider.MergeRequest().Get(ctx, &tp.GetMergeRequestOption{Repo: task.Repo, MergeID: task.MergeRequestID})
+		if err != nil {
+			logrus.Errorf("Get merge request %s failed: %s", task.MergeRequestID, err)
+			return nil, err
+		}
+	}
+
+	var branches []string
 	for _, branch := range selected {
-		var status Status
 		if branch == task.From {
 			logrus.Debugf("Skip form branch: %s", branch)
 			continue // skip the branch, and pick commits from the next branch
@@ -101,37 +166,21 @@ func (s *Service) PerformPickToBranches(ctx context.Context, task *Task, comment
 			logrus.Debugf("Skip pick: %s, not in defined %s", branch, task.Branches)
 			continue
 		}
+		branches = append(branches, branch)
+	}
 
-		logrus.Debugf("Picking %s to %s", *task.SHA, branch)
-		// PerformPick commits
-		pr, _ := strconv.Atoi(task.MergeRequestID)
-		err = s.PerformPick(ctx, &CherryPickOptions{
-			SHA:      *task.SHA,
-			Repo:     task.Repo,
-			Target:   branch,
-			RepoPath: task.RepoPath,
-			Pr:       pr,
-		})
-		if err != nil {
-			status = FailedStatus
-			if errors.Is(err, tp.NotFound) {
-				status = SkipStatus
-			}
-			var e *tp.ProviderError
-			if !errors.As(err, &e) { // 如果不是 ProviderError 需要对信息做处理
-				// format error message, 如果能够通过空格分割 1 次，取后面的部分
-				message := strings.Split(err.Error(), " ")
-				if len(message) > 1 {
-					logrus.Warnf("source error: %s", err)
-					err = errors.New(strings.Join(message[1:], " "))
-				}
-			}
-			result = append(result, &TaskResult{Status: status, Branch: branch, Reason: err.Error()})
-		} else {
-			status = SucceedStatus
-			result = append(result, &TaskResult{Status: status, Branch: branch})
-		}
-		logrus.Infof("Pick %s to %s %s", *task.SHA, branch, status)
+	// commits picked before, by norn or by hand, are not picked again
+	sources := s.loadSources(ctx, task, shas)
+
+	// PerformPick commits from one branch to another
+	if task.Cascade {
+		result, err = s.cascadeBranches(ctx, task, source, sources, branches, shas)
+	} else {
+		result, err = s.pickBranches(ctx, task, source, sources, branches, shas)
+	}
+	if err != nil {
+		logrus.Errorf("Pick canceled: %s", err)
+		return nil, err
 	}
 	logrus.Infof("Picke Result %v", result)
 
@@ -140,6 +189,12 @@ func (s *Service) PerformPickToBranches(ctx context.Context, task *Task, comment
 		return nil, nil
 	}
 
+	if task.DryRun {
+		logrus.Infof("Dry run, print the plan instead of the result comment")
+		PrintPlan(s.out, result)
+		return result, nil
+	}
+
 	// generate content
 	logrus.Infof("Generate pick result content")
 	content, err := NewResultComment(tp.PickResultTemplate, result)
@@ -161,21 +216,345 @@ func (s *Service) PerformPickToBranches(ctx context.Context, task *Task, comment
 	return result, nil
 }
 
-func (s *Service) PerformPick(ctx context.Context, opt *CherryPickOptions) error {
+// pickBranches picks the commits to the branches with up to Task.Concurrency workers,
+// the results are in the order of the branches. It stops starting picks when ctx is done.
+func (s *Service) pickBranches(ctx context.Context, task *Task, source tp.MergeRequest, sources []*sourceCommit, branches []string, shas []string) ([]*TaskResult, error) {
+	concurrency := task.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	result := make([]*TaskResult, len(branches))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(branches)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					continue // canceled while queued
+				}
+				result[i], _ = s.pickBranch(ctx, task, source, sources, branches[i], shas)
+			}
+		}()
+	}
+dispatch:
+	for i := range branches {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	// the picks in progress fail with the context error, no result is reported
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cascadeBranches picks the commits to the first branch, then the picked commits to the next branch
+// and so on, like forward-porting by hand. The chain stops at the first branch failed to pick, the
+// branches after it are skipped.
+func (s *Service) cascadeBranches(ctx context.Context, task *Task, source tp.MergeRequest, sources []*sourceCommit, branches []string, shas []string) ([]*TaskResult, error) {
+	result := make([]*TaskResult, 0, len(branches))
+	var stopped string
+	for _, branch := range branches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if stopped != "" {
+			result = append(result, &TaskResult{
+				Status: SkipStatus,
+				Branch: branch,
+				Reason: fmt.Sprintf("cascade stopped at %s", stopped),
+			})
+			continue
+		}
+		r, picked := s.pickBranch(ctx, task, source, sources, branch, shas)
+		result = append(result, r)
+		if picked == nil {
+			stopped = branch
+			continue
+		}
+		shas = cascadeSHAs(shas, picked)
+	}
+	return result, nil
+}
+
+// cascadeSHAs returns the picked commits to pick to the next branch. A dry run does not create
+// commits, so the next branch is checked with the commits of the task instead.
+func cascadeSHAs(shas []string, picked []*tp.PickResult) []string {
+	next := make([]string, 0, len(picked))
+	for _, p := range picked {
+		if p == nil || p.SHA == "" {
+			return shas
+		}
+		next = append(next, p.SHA)
+	}
+	return next
+}
+
+// pickBranch picks the commits to the branch, or to its backport branch, and returns the picks.
+// The commits already present on the branch are not picked, their picks are the present commits.
+func (s *Service) pickBranch(ctx context.Context, task *Task, source tp.MergeRequest, sources []*sourceCommit, branch string, shas []string) (*TaskResult, []*tp.PickResult) {
+	found := s.findPicked(ctx, task, branch, shas, sources)
+	var todo []string
+	var index []int // index of the commits to pick in shas
+	for i, sha := range shas {
+		if found[i] == "" {
+			todo = append(todo, sha)
+			index = append(index, i)
+		}
+	}
+	present := len(shas) - len(todo)
+
+	var backport tp.MergeRequest
+	var picked []*tp.PickResult
+	failed := -1
+	messages, err := s.pickMessages(ctx, task, branch, todo)
+	switch {
+	case err != nil:
+	case len(todo) == 0:
+		// nothing to pick, not even a backport merge request
+	case task.Backport && !task.DryRun:
+		backport, picked, failed, err = s.backportCommits(ctx, task, source, branch, todo, messages)
+	default:
+		// the backport branch starts at the branch, so a dry run plans against the branch
+		picked, failed, err = s.pickCommits(ctx, task, branch, todo, messages)
+	}
+	if err != nil {
+		status := Status(FailedStatus)
+		if errors.Is(err, tp.NotFound) || errors.Is(err, tp.ErrAlreadyExists) {
+			// nothing to pick from, or the backport merge request is already open
+			status = SkipStatus
+		}
+		var e *tp.ProviderError
+		var conflict *tp.ConflictError
+		if !errors.As(err, &e) && !errors.As(err, &conflict) { // 如果不是 ProviderError 需要对信息做处理
+			// format error message, 如果能够通过空格分割 1 次，取后面的部分
+			message := strings.Split(err.Error(), " ")
+			if len(message) > 1 {
+				logrus.Warnf("source error: %s", err)
+				err = errors.New(strings.Join(message[1:], " "))
+			}
+		}
+		r := &TaskResult{Status: status, Branch: branch, Reason: err.Error()}
+		if failed >= 0 {
+			failed = index[failed]
+			r.Commit = shas[failed]
+			if len(shas) > 1 {
+				// tell which commit of the merge request failed, the commits before it are picked
+				r.Reason = fmt.Sprintf("commit %s (%d/%d): %s", shortSHA(shas[failed]), failed+1, len(shas), r.Reason)
+			}
+		}
+		logrus.Infof("Pick %s to %s %s", shas, branch, status)
+		return r, nil
+	}
+
+	// merge the present commits and the picks in the order of the commits
+	r := &TaskResult{Status: SucceedStatus, Branch: branch}
+	results := make([]*tp.PickResult, len(shas))
+	for i, sha := range found {
+		if sha != "" {
+			results[i] = &tp.PickResult{SHA: sha}
+		}
+	}
+	empty := 0
+	for i, p := range picked {
+		if p == nil {
+			empty++
+			continue
+		}
+		results[index[i]] = p
+		r.Tree = p.Tree
+	}
+
+	if present+empty == len(shas) {
+		r.Status = SkipStatus
+	}
+	r.Reason = skipReason(present, empty, len(shas))
+	if backport != nil {
+		r.MergeRequestID, r.MergeRequestUrl = backport.MergeId(), backport.WebUrl()
+	}
+	logrus.Infof("Pick %s to %s %s", shas, branch, r.Status)
+	return r, results
+}
+
+// commitsOfTask returns the commits to pick, every commit of the merge request in MergeRequest mode
+func (s *Service) commitsOfTask(ctx context.Context, task *Task) ([]string, error) {
+	if task.PickMode != MergeRequest {
+		if task.SHA == nil || *task.SHA == "" {
+			return nil, tp.ErrInvalidOptions
+		}
+		return []string{*task.SHA}, nil
+	}
+
+	commits, err := s.provider.MergeRequest().ListCommits(ctx, &tp.ListMergeRequestCommitsOption{
+		Repo:    task.Repo,
+		MergeID: task.MergeRequestID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("merge request %s has no commits", task.MergeRequestID)
+	}
+	shas := make([]string, 0, len(commits))
+	for _, c := range commits {
+		shas = append(shas, c.SHA())
+	}
+	logrus.Infof("Commits of merge request %s: %s", task.MergeRequestID, shas)
+	return shas, nil
+}
+
+// backportCommits picks the commits to a backport branch created from the branch and opens a merge
+// request against the branch, so protected branches are not pushed to. A backport branch left by an
+// earlier run is picked onto, and a branch created here is deleted if the backport fails. It returns
+// the index of the failed commit or -1 if the failure is not caused by a commit.
+func (s *Service) backportCommits(ctx context.Context, task *Task, source tp.MergeRequest, branch string, shas, messages []string) (tp.MergeRequest, []*tp.PickResult, int, error) {
+	target, err := s.provider.Reference().Get(ctx, &tp.GetRefOption{Repo: task.Repo, Ref: "refs/heads/" + branch})
+	if err != nil {
+		return nil, nil, -1, err
+	}
+
+	head := BackportBranch(task.MergeRequestID, branch)
+	created := true
+	_, err = s.provider.Reference().Create(ctx, &tp.CreateRefOption{Repo: task.Repo, Ref: "refs/heads/" + head, SHA: target.SHA})
+	switch {
+	case errors.Is(err, tp.ErrAlreadyExists):
+		logrus.Infof("Backport branch %s exists, picking onto it", head)
+		created = false
+	case err != nil:
+		return nil, nil, -1, fmt.Errorf("backport branch %s: %w", head, err)
+	}
+
+	picked, failed, err := s.pickCommits(ctx, task, head, shas, messages)
+	if err != nil {
+		s.deleteBackportBranch(ctx, task, head, created)
+		return nil, nil, failed, err
+	}
+	if created && slices.IndexFunc(picked, func(p *tp.PickResult) bool { return p != nil }) < 0 {
+		// every pick is empty, there is nothing to merge
+		logrus.Infof("Nothing to backport to %s, no merge request is opened for %s", branch, head)
+		s.deleteBackportBranch(ctx, task, head, created)
+		return nil, picked, -1, nil
+	}
+
+	origin := source.WebUrl()
+	if origin == "" {
+		origin = "#" + source.MergeId()
+	}
+	mr, err := s.provider.MergeRequest().Create(ctx, &tp.CreateMergeRequestOption{
+		Repo:        task.Repo,
+		Title:       fmt.Sprintf("[Backport %s] %s", branch, source.Title()),
+		Description: fmt.Sprintf("Backport of %s to `%s`.\n\n%s", origin, branch, source.Description()),
+		Head:        head,
+		Base:        branch,
+		Labels:      source.Labels(),
+	})
+	if errors.Is(err, tp.ErrAlreadyExists) {
+		// the merge request of an earlier run is open and shows the picks
+		logrus.Infof("Backport merge request of %s to %s is already open", head, branch)
+		return nil, nil, -1, fmt.Errorf("merge request of %s is already open: %w", head, err)
+	}
+	if err != nil {
+		logrus.Errorf("Create backport merge request %s to %s failed: %s", head, branch, err)
+		s.deleteBackportBranch(ctx, task, head, created)
+		return nil, nil, -1, err
+	}
+	logrus.Infof("Backport %s to %s: %s", task.MergeRequestID, branch, mr.WebUrl())
+	return mr, picked, -1, nil
+}
+
+// deleteBackportBranch deletes the backport branch if it is created by this run, a failure is logged
+func (s *Service) deleteBackportBranch(ctx context.Context, task *Task, head string, created bool) {
+	if !created {
+		return
+	}
+	if err := s.provider.Reference().Delete(ctx, &tp.DeleteRefOption{Repo: task.Repo, Ref: "refs/heads/" + head}); err != nil {
+		logrus.Warnf("Delete backport branch %s failed: %s", head, err)
+	}
+}
+
+// BackportBranch returns the branch the picks to the branch are pushed to in backport mode
+func BackportBranch(mergeRequestID, branch string) string {
+	return fmt.Sprintf("backport/%s-%s", mergeRequestID, branch)
+}
+
+// pickCommits picks the commits to the branch in order, it stops at the first failure and
+// returns the index of the failed commit, -1 if every commit is picked. The results are the picks of the commits, nil for a
+// commit whose change already exists on the branch. The messages of the pick commits are optional.
+func (s *Service) pickCommits(ctx context.Context, task *Task, branch string, shas, messages []string) ([]*tp.PickResult, int, error) {
+	pr, _ := strconv.Atoi(task.MergeRequestID)
+	results := make([]*tp.PickResult, 0, len(shas))
+	for i, sha := range shas {
+		logrus.Debugf("Picking %s to %s", sha, branch)
+		var message string
+		if messages != nil {
+			message = messages[i]
+		}
+		result, err := s.PerformPick(ctx, &CherryPickOptions{
+			SHA:       sha,
+			Repo:      task.Repo,
+			Target:    branch,
+			RepoPath:  task.RepoPath,
+			Pr:        pr,
+			Mainline:  task.Mainline,
+			DryRun:    task.DryRun,
+			Message:   message,
+			Committer: task.Committer,
+			DateMode:  task.DateMode,
+		})
+		if errors.Is(err, tp.ErrEmptyPick) {
+			logrus.Infof("Pick %s to %s is empty, skipped", sha, branch)
+			results = append(results, nil)
+			continue
+		}
+		if err != nil {
+			return nil, i, err
+		}
+		results = append(results, result)
+	}
+	return results, -1, nil
+}
+
+func (s *Service) PerformPick(ctx context.Context, opt *CherryPickOptions) (*tp.PickResult, error) {
 	if s.provider == nil || opt == nil {
 		logrus.Error("provider or opt is nil")
-		return tp.ErrInvalidOptions
+		return nil, tp.ErrInvalidOptions
 	}
 
-	err := s.provider.Pick().Pick(ctx, opt.Repo, &tp.PickOption{
-		Branch: opt.Target,
-		SHA:    opt.SHA,
-	})
+	pickOpt := &tp.PickOption{
+		Branch:    opt.Target,
+		SHA:       opt.SHA,
+		Mainline:  opt.Mainline,
+		DryRun:    opt.DryRun,
+		Message:   opt.Message,
+		Committer: opt.Committer,
+		DateMode:  opt.DateMode,
+	}
+	var result *tp.PickResult
+	var err error
+	for attempt := 1; attempt <= maxPickAttempts; attempt++ {
+		// the provider reads the branch again, so the pick is redone on top of the new head
+		if opt.Revert {
+			result, err = s.provider.Pick().Revert(ctx, opt.Repo, pickOpt)
+		} else {
+			result, err = s.provider.Pick().Pick(ctx, opt.Repo, pickOpt)
+		}
+		if !errors.Is(err, tp.ErrStaleRef) {
+			break
+		}
+		logrus.Warnf("Branch %s is updated while picking %s, attempt %d/%d", opt.Target, opt.SHA, attempt, maxPickAttempts)
+	}
 	if err != nil {
 		logrus.Warnf("Pick failed: %s", err)
-		return err
+		return nil, err
 	}
-	return nil
+	return result, nil
 }
 
 // CreateSummaryWithTask submit pick summary comment
@@ -229,9 +608,10 @@ func (s *Service) CreateSummaryWithTask(ctx context.Context, task *Task) error {
 		// update the comment
 		logrus.Info("pick comment already exists, regenerate summary comment.")
 		_, err = s.provider.Comment().Update(ctx, &tp.UpdateCommentOption{
-			CommentID: comment.CommentID(),
-			Body:      summaryComment,
-			Repo:      task.Repo,
+			CommentID:      comment.CommentID(),
+			Body:           summaryComment,
+			Repo:           task.Repo,
+			MergeRequestID: task.MergeRequestID,
 		})
 		if err != nil {
 			return err
@@ -243,7 +623,9 @@ func (s *Service) CreateSummaryWithTask(ctx context.Context, task *Task) error {
 
 func (s *Service) ProcessPick(ctx context.Context, task *Task) error {
 	var err error
-	if task.IsSummary {
+	if task.IsSummary && task.DryRun {
+		err = s.PrintSummary(task)
+	} else if task.IsSummary {
 		err = s.CreateSummaryWithTask(ctx, task)
 		if err != nil {
 			logrus.Errorf("create summary err: %s", err)
@@ -251,7 +633,7 @@ func (s *Service) ProcessPick(ctx context.Context, task *Task) error {
 	} else {
 		// check if pick result is exist, if existed, skip
 		comments, result, err := s.FindCommentWithTask(ctx, task, tp.CherryPickResultFlag)
-		if result != nil {
+		if result != nil && !task.DryRun {
 			logrus.Warnf("pick result is exist %s.", result)
 			return nil
 		}
@@ -275,6 +657,21 @@ func (s *Service) ProcessPick(ctx context.Context, task *Task) error {
 	return err
 }
 
+// PrintSummary prints the summary comment the task would submit, for dry run
+func (s *Service) PrintSummary(task *Task) error {
+	targets := generateTargetBranches(task)
+	if len(targets) == 0 {
+		_, err := fmt.Fprintln(s.out, "No cherry-pick branches")
+		return err
+	}
+	summaryComment, err := NewSummaryComment(tp.CherryPickTaskSummaryTemplate, targets)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(s.out, summaryComment)
+	return err
+}
+
 // CheckSummaryExist check if summary comment is exist
 func (s *Service) CheckSummaryExist(ctx context.Context, repo string, mergeRequestID string) (tp.Comment, error) {
 	comments, err := s.provider.Comment().Find(ctx, &tp.FindCommentOption{MergeRequestID: mergeRequestID, Repo: repo})
@@ -307,8 +704,9 @@ func (s *Service) DeleteSummaryWithFlag(ctx context.Context, task *Task) {
 	comment := FindSummaryWithFlag(comments, tp.CherryPickSummaryFlag)
 	if comment != nil {
 		err = s.provider.Comment().Delete(ctx, &tp.DeleteCommentOption{
-			CommentID: comment.CommentID(),
-			Repo:      task.Repo,
+			CommentID:      comment.CommentID(),
+			Repo:           task.Repo,
+			MergeRequestID: task.MergeRequestID,
 		})
 		if err != nil {
 			logrus.Warnf("Delete summary comment failed: %s", err)
@@ -2,17 +2,32 @@ package pick
 
 import (
 	"context"
+	"errors"
 	"github.com/kentio/norn/internal"
 	"github.com/kentio/norn/pkg/common"
+	"github.com/kentio/norn/pkg/fake"
 	"github.com/kentio/norn/pkg/github"
 	tp "github.com/kentio/norn/pkg/types"
 	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+// newFakeRepo creates release branches r1, r2 and master, returns a commit on r1 to pick
+func newFakeRepo() (*fake.Provider, string) {
+	provider := fake.NewProvider()
+	root := provider.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	provider.CreateBranch("r1", root)
+	provider.CreateBranch("r2", root)
+	sha := provider.CommitFiles("r1", "fix: bug", map[string]string{"b.txt": "b"})
+	return provider, sha
+}
+
 func TestPick_CreateSummaryWithTask(t *testing.T) {
 	ctx := context.Background()
-	provider := github.NewProvider(ctx, "")
+	provider, sha := newFakeRepo()
 	pickOpt := &Task{
 		Repo: "kentio/pick",
 		Branches: []string{
@@ -20,22 +35,46 @@ func TestPick_CreateSummaryWithTask(t *testing.T) {
 			"r2",
 			"master",
 		},
-		Form:           "r1",
+		From:           "r1",
 		IsSummary:      false,
-		SHA:            common.String(""),
+		SHA:            common.String(sha),
 		MergeRequestID: "64",
 	}
-	pick := NewPickService(provider, pickOpt.Branches)
+	pick := NewPickService(provider)
 	err := pick.CreateSummaryWithTask(ctx, pickOpt)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	t.Logf("err: %v", err)
+	comments := provider.Comments("64")
+	if len(comments) != 1 || !EqualSlice(parseSelectedBranches(comments[0]), []string{"r2", "master"}) {
+		t.Fatalf("summary: %+v", comments)
+	}
+
+	// same branches, the summary is kept
+	if err = pick.CreateSummaryWithTask(ctx, pickOpt); err != nil || len(provider.Comments("64")) != 1 {
+		t.Fatalf("err: %v comments: %+v", err, provider.Comments("64"))
+	}
+
+	// branches changed, the summary is regenerated
+	pickOpt.From = "r2"
+	if err = pick.CreateSummaryWithTask(ctx, pickOpt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	comments = provider.Comments("64")
+	if len(comments) != 1 || !EqualSlice(parseSelectedBranches(comments[0]), []string{"master"}) {
+		t.Fatalf("summary: %+v", comments)
+	}
+
+	// no target branches, the summary is deleted
+	pickOpt.From = "master"
+	if err = pick.CreateSummaryWithTask(ctx, pickOpt); err != nil || len(provider.Comments("64")) != 0 {
+		t.Fatalf("err: %v comments: %+v", err, provider.Comments("64"))
+	}
 }
 
 func TestPick(t *testing.T) {
 	ctx := context.Background()
-	provider := github.NewProvider(ctx, "")
+	provider, sha := newFakeRepo()
 	task := &Task{
 		Repo: "kentio/pick",
 		Branches: []string{
@@ -43,15 +82,14 @@ func TestPick(t *testing.T) {
 			"r2",
 			"master",
 		},
-		Form:           "r1",
+		From:           "r1",
 		IsSummary:      false,
-		SHA:            common.String(""),
+		SHA:            common.String(sha),
 		MergeRequestID: "2",
 	}
-	pick := NewPickService(provider, task.Branches)
+	pick := NewPickService(provider)
 
-	//err := pick.ProcessPick(ctx, task)
-	err := pick.PerformPick(ctx, &CherryPickOptions{
+	_, err := pick.PerformPick(ctx, &CherryPickOptions{
 		SHA:    *task.SHA,
 		Repo:   task.Repo,
 		Target: "master",
@@ -61,26 +99,31 @@ func TestPick(t *testing.T) {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	t.Logf("err: %v", err)
+	if content, _ := provider.File("master", "b.txt"); content != "b" {
+		t.Fatalf("b.txt: %q", content)
+	}
 }
 
 func TestPick_CheckSummaryExist(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx := context.Background()
-	provider := github.NewProvider(ctx, "")
+	provider, sha := newFakeRepo()
 	pickOpt := &Task{
 		Repo: "kentio/test_cherry_pick",
 		Branches: []string{
-			"release/23.03",
-			"release/23.04",
+			"r1",
+			"r2",
 			"master",
 		},
-		Form:           "release/23.03",
+		From:           "r1",
 		IsSummary:      false,
-		SHA:            common.String(""),
+		SHA:            common.String(sha),
 		MergeRequestID: "54",
 	}
-	pick := NewPickService(provider, pickOpt.Branches)
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, pickOpt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 	// Is Exist
 	comment, err := pick.CheckSummaryExist(ctx, pickOpt.Repo, pickOpt.MergeRequestID)
 	if err != nil {
@@ -131,34 +174,695 @@ func TestParseSelectedBranches(t *testing.T) {
 func TestPerformPickToBranches(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx := context.Background()
-	token := ""
-	provider, _ := common.NewProvider(ctx, "github", token)
+	provider, sha := newFakeRepo()
+	provider.SetPickError("master", &tp.ConflictError{Files: []string{"a.txt", "b.txt"}})
 
 	pickOpt := &Task{
 		Repo: "kentio/test_cherry_pick",
 		Branches: []string{
-			"release/23.03",
-			"release/23.04",
+			"r1",
+			"r2",
+			"r3",
 			"master",
 		},
-		Form:           "release/23.03",
+		From:           "r1",
 		IsSummary:      true,
-		SHA:            common.String(""),
+		SHA:            common.String(sha),
 		MergeRequestID: "66",
 	}
-	pick := NewPickService(provider, pickOpt.Branches)
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, pickOpt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
 	_, comment, err := pick.FindCommentWithTask(ctx, pickOpt, tp.CherryPickSummaryFlag)
+	if err != nil || comment == nil {
+		t.Fatalf("err: %v comment: %v", err, comment)
+	}
 
 	// test is summary task
 	result, err := pick.PerformPickToBranches(ctx, pickOpt, comment)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	t.Logf("result %v", &result)
+	want := map[string]Status{"r2": SucceedStatus, "r3": SkipStatus, "master": FailedStatus}
+	if len(result) != len(want) {
+		t.Fatalf("result: %+v", result)
+	}
+	for _, r := range result {
+		if want[r.Branch] != r.Status {
+			t.Errorf("%s status = %s, want %s", r.Branch, r.Status, want[r.Branch])
+		}
+		if r.Branch == "master" && r.Reason != "conflict: a.txt, b.txt" {
+			t.Errorf("reason = %q, want the conflicting files", r.Reason)
+		}
+	}
 
 	// test done comment
+	comments := provider.Comments("66")
+	if len(comments) != 2 || !strings.Contains(comments[1], tp.CherryPickResultFlag) {
+		t.Fatalf("comments: %+v", comments)
+	}
+}
+
+func TestPerformPickToBranches_MergeRequest(t *testing.T) {
+	ctx := context.Background()
+	provider, first := newFakeRepo()
+	second := provider.CommitFiles("r1", "fix: c", map[string]string{"c.txt": "c"})
+	third := provider.CommitFiles("r1", "fix: a", map[string]string{"a.txt": "x"})
+	provider.CommitFiles("master", "change a", map[string]string{"a.txt": "m"})
+	provider.SetMergeRequestCommits("7", first, second, third)
+
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		MergeRequestID: "7",
+		IsSummary:      true,
+		PickMode:       MergeRequest,
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, err := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+	if err != nil || comment == nil {
+		t.Fatalf("err: %v comment: %v", err, comment)
+	}
+
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if result[0].Branch != "r2" || result[0].Status != SucceedStatus {
+		t.Fatalf("r2: %+v", result[0])
+	}
+	for file, content := range map[string]string{"b.txt": "b", "c.txt": "c", "a.txt": "x"} {
+		if got, _ := provider.File("r2", file); got != content {
+			t.Errorf("r2 %s = %q, want %q", file, got, content)
+		}
+	}
+	if !strings.HasPrefix(provider.CommitMessage("r2"), "fix: a") {
+		t.Errorf("r2 message: %q", provider.CommitMessage("r2"))
+	}
+
+	// the third commit conflicts on master, the first two are picked
+	if result[1].Branch != "master" || result[1].Status != FailedStatus || result[1].Commit != third {
+		t.Fatalf("master: %+v", result[1])
+	}
+	if !strings.Contains(result[1].Reason, third[:7]) || !strings.Contains(result[1].Reason, "3/3") {
+		t.Errorf("reason: %s", result[1].Reason)
+	}
+	if c, _ := provider.File("master", "c.txt"); c != "c" {
+		t.Errorf("master is not picked up to the failed commit")
+	}
+	if a, _ := provider.File("master", "a.txt"); a != "m" {
+		t.Errorf("master a.txt = %q, want m", a)
+	}
+}
+
+func TestPerformPickToBranches_Backport(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	provider.AddMergeRequest("9", "fix: bug", "fix the bug", tp.MergeRequestStateMerged)
+	provider.SetMergeRequestLabels("9", "bug")
+	provider.CreateBranch("backport/9-master", provider.Branch("master")) // left over from an earlier run
+
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "9",
+		IsSummary:      true,
+		Backport:       true,
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, err := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+	if err != nil || comment == nil {
+		t.Fatalf("err: %v comment: %v", err, comment)
+	}
+
+	head := provider.Branch("r2")
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+
+	// r2 is not updated, the pick is on the backport branch with a merge request
+	if provider.Branch("r2") != head {
+		t.Fatalf("r2 is updated")
+	}
+	if content, _ := provider.File("backport/9-r2", "b.txt"); content != "b" {
+		t.Fatalf("backport branch b.txt: %q", content)
+	}
+	ids := provider.MergeRequests("backport/9-r2", "r2")
+	if len(ids) != 1 || result[0].Status != SucceedStatus || result[0].MergeRequestID != ids[0] {
+		t.Fatalf("r2: %+v merge requests: %v", result[0], ids)
+	}
+	mr, err := provider.MergeRequest().Get(ctx, &tp.GetMergeRequestOption{MergeID: ids[0]})
+	if err != nil || mr.Title() != "[Backport r2] fix: bug" || !strings.Contains(mr.Description(), "fix the bug") ||
+		!EqualSlice(mr.Labels(), []string{"bug"}) {
+		t.Fatalf("err: %v merge request: %+v", err, mr)
+	}
+
+	// the backport branch of master left over is picked onto
+	masters := provider.MergeRequests("backport/9-master", "master")
+	if result[1].Branch != "master" || result[1].Status != SucceedStatus || len(masters) != 1 || result[1].MergeRequestID != masters[0] {
+		t.Fatalf("master: %+v merge requests: %v", result[1], masters)
+	}
+	if content, _ := provider.File("backport/9-master", "b.txt"); content != "b" {
+		t.Fatalf("backport branch b.txt: %q", content)
+	}
+
+	// the result comment links the merge request
+	comments := provider.Comments("9")
+	if !strings.Contains(comments[len(comments)-1], "[Backport #"+ids[0]+"]("+mr.WebUrl()+")") {
+		t.Fatalf("result comment: %s", comments[len(comments)-1])
+	}
+}
+
+func TestPerformPickToBranches_BackportRerun(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	provider.AddMergeRequest("9", "fix: bug", "fix the bug", tp.MergeRequestStateMerged)
+	task := &Task{Repo: "kentio/norn", Branches: []string{"r1", "r2"}, From: "r1", SHA: common.String(sha), MergeRequestID: "9", IsSummary: true, Backport: true}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, err := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+	if err != nil || comment == nil {
+		t.Fatalf("err: %v comment: %v", err, comment)
+	}
+	run := func() *TaskResult {
+		t.Helper()
+		result, err := pick.PerformPickToBranches(ctx, task, comment)
+		if err != nil || len(result) != 1 {
+			t.Fatalf("err: %v result: %+v", err, result)
+		}
+		return result[0]
+	}
 
+	// the failed pick and the failed merge request leave no backport branch
+	provider.SetPickError("backport/9-r2", tp.ErrConflict)
+	if r := run(); r.Status != FailedStatus || provider.Branch("backport/9-r2") != "" {
+		t.Fatalf("result: %+v branch: %s", r, provider.Branch("backport/9-r2"))
+	}
+	provider.SetPickError("backport/9-r2", nil)
+	provider.SetError(fake.OpMergeRequestCreate, errors.New("boom"))
+	if r := run(); r.Status != FailedStatus || provider.Branch("backport/9-r2") != "" {
+		t.Fatalf("result: %+v branch: %s", r, provider.Branch("backport/9-r2"))
+	}
+	provider.SetError(fake.OpMergeRequestCreate, nil)
+
+	if r := run(); r.Status != SucceedStatus || len(provider.MergeRequests("backport/9-r2", "r2")) != 1 {
+		t.Fatalf("result: %+v", r)
+	}
+	head := provider.Branch("backport/9-r2")
+
+	// a rerun finds the branch and its merge request
+	r := run()
+	if r.Status != SkipStatus || !strings.Contains(r.Reason, "already open") {
+		t.Fatalf("result: %+v", r)
+	}
+	if provider.Branch("backport/9-r2") != head || len(provider.MergeRequests("backport/9-r2", "r2")) != 1 {
+		t.Fatalf("rerun changed the backport")
+	}
+}
+
+// hookProvider calls the hook before each pick, a hook error fails the pick
+type hookProvider struct {
+	*fake.Provider
+	hook func(opt *tp.PickOption) error
+}
+
+type hookPickService struct {
+	p *hookProvider
+}
+
+func (p *hookProvider) Pick() tp.PickService { return &hookPickService{p: p} }
+
+func (s *hookPickService) Pick(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	if err := s.p.hook(opt); err != nil {
+		return nil, err
+	}
+	return s.p.Provider.Pick().Pick(ctx, repo, opt)
+}
+
+func (s *hookPickService) Revert(ctx context.Context, repo string, opt *tp.PickOption) (*tp.PickResult, error) {
+	if err := s.p.hook(opt); err != nil {
+		return nil, err
+	}
+	return s.p.Provider.Pick().Revert(ctx, repo, opt)
+}
+
+func TestPerformPick_StaleRef(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	// the first picks fail like a branch updated while picking
+	picks, races := 0, 2
+	pick := NewPickService(&hookProvider{Provider: provider, hook: func(*tp.PickOption) error {
+		picks++
+		if picks <= races {
+			return tp.ErrStaleRef
+		}
+		return nil
+	}})
+
+	// retried on top of the new head
+	result, err := pick.PerformPick(ctx, &CherryPickOptions{SHA: sha, Repo: "kentio/norn", Target: "r2"})
+	if err != nil || picks != 3 || result.SHA != provider.Branch("r2") {
+		t.Fatalf("err: %v picks: %d result: %+v", err, picks, result)
+	}
+
+	// bounded retries
+	picks, races = 0, 10
+	if _, err = pick.PerformPick(ctx, &CherryPickOptions{SHA: sha, Repo: "kentio/norn", Target: "master"}); err != tp.ErrStaleRef {
+		t.Fatalf("err = %v, want stale ref", err)
+	}
+	if picks != maxPickAttempts {
+		t.Fatalf("picks: %d", picks)
+	}
+}
+
+func TestPerformPickToBranches_Committer(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	var picks []*tp.PickOption
+	pick := NewPickService(&hookProvider{Provider: provider, hook: func(opt *tp.PickOption) error {
+		picks = append(picks, opt)
+		return nil
+	}})
+	committer := &tp.Signature{Name: "bot", Email: "bot@example.com"}
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+		Committer:      committer,
+		DateMode:       tp.DateOriginal,
+	}
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	if _, err := pick.PerformPickToBranches(ctx, task, comment); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(picks) != 1 || picks[0].Committer != committer || picks[0].DateMode != tp.DateOriginal {
+		t.Fatalf("picks: %+v", picks)
+	}
+}
+
+func TestPerformPickToBranches_Concurrency(t *testing.T) {
+	ctx := context.Background()
+	provider := fake.NewProvider()
+	root := provider.CommitFiles("master", "init", map[string]string{"a.txt": "a"})
+	branches := []string{"r0", "r1", "r2", "r3", "r4", "r5", "r6", "r7"}
+	for _, branch := range branches {
+		provider.CreateBranch(branch, root)
+	}
+	sha := provider.CommitFiles("r0", "fix: bug", map[string]string{"b.txt": "b"})
+	provider.SetPickError("r5", tp.ErrConflict)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	pick := NewPickService(&hookProvider{Provider: provider, hook: func(*tp.PickOption) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}})
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       branches,
+		From:           "r0",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+		Concurrency:    3,
+	}
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 7 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	// ordered like the branches
+	for i, r := range result {
+		want := Status(SucceedStatus)
+		if r.Branch == "r5" {
+			want = FailedStatus
+		}
+		if r.Branch != branches[i+1] || r.Status != want {
+			t.Fatalf("result %d: %+v", i, r)
+		}
+	}
+	if peak < 2 || peak > 3 {
+		t.Fatalf("peak concurrency: %d", peak)
+	}
+}
+
+func TestPerformPickToBranches_Cascade(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+		Cascade:        true,
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 || result[0].Status != SucceedStatus || result[1].Status != SucceedStatus {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	// master picks the commit picked to r2
+	r2 := provider.Branch("r2")
+	if message := provider.CommitMessage("master"); !strings.HasSuffix(message, "(cherry picked from commit "+r2[:7]+")") {
+		t.Fatalf("master message: %q", message)
+	}
+	if content, _ := provider.File("master", "b.txt"); content != "b" {
+		t.Fatalf("master b.txt: %q", content)
+	}
+}
+
+func TestPerformPickToBranches_CascadeConflict(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	provider.SetPickError("r2", tp.ErrConflict)
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+		Cascade:        true,
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	head := provider.Branch("master")
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if result[0].Status != FailedStatus || result[1].Status != SkipStatus || result[1].Reason != "cascade stopped at r2" {
+		t.Fatalf("result: %+v %+v", result[0], result[1])
+	}
+	if provider.Branch("master") != head {
+		t.Fatalf("master is picked")
+	}
+}
+
+func TestPerformPickToBranches_AlreadyPresent(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	// master has the fix backported by hand, without the trailer
+	provider.CommitFiles("master", "fix: bug", map[string]string{"b.txt": "b"})
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	master := provider.Branch("master")
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if result[0].Status != SucceedStatus || result[1].Status != SkipStatus || result[1].Reason != "already present" {
+		t.Fatalf("result: %+v %+v", result[0], result[1])
+	}
+	if provider.Branch("master") != master {
+		t.Fatalf("master is picked again")
+	}
+
+	// running again finds the pick on r2 by its trailer
+	r2 := provider.Branch("r2")
+	result, err = pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || result[0].Status != SkipStatus || result[0].Reason != "already present" || provider.Branch("r2") != r2 {
+		t.Fatalf("err: %v result: %+v", err, result[0])
+	}
+	comments := provider.Comments("1")
+	if !strings.Contains(comments[len(comments)-1], "already present") {
+		t.Fatalf("result comment: %s", comments[len(comments)-1])
+	}
+}
+
+func TestPerformPickToBranches_AlreadyPresentReworded(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	// master has the fix backported by hand under another subject, behind a newer commit
+	backport := provider.CommitFiles("master", "[backport] fix the bug of r1", map[string]string{"b.txt": "b"})
+	provider.CommitFiles("master", "docs: readme", map[string]string{"README.md": "norn"})
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	found := pick.findPicked(ctx, task, "master", []string{sha}, pick.loadSources(ctx, task, []string{sha}))
+	if found[0] != backport {
+		t.Fatalf("found: %v, want %s", found, backport)
+	}
+	master := provider.Branch("master")
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 1 || result[0].Status != SkipStatus || result[0].Reason != "already present" {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if provider.Branch("master") != master {
+		t.Fatalf("master is picked again")
+	}
+}
+
+func TestPerformPickToBranches_EmptyPick(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	// master has the change within a larger commit, it is not found but the pick is empty
+	provider.CommitFiles("master", "add b and c", map[string]string{"b.txt": "b", "c.txt": "c"})
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+	}
+	pick := NewPickService(provider)
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	master := provider.Branch("master")
+	result, err := pick.PerformPickToBranches(ctx, task, comment)
+	if err != nil || len(result) != 2 {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if result[0].Status != SucceedStatus || result[1].Status != SkipStatus || !strings.Contains(result[1].Reason, "empty pick") {
+		t.Fatalf("result: %+v %+v", result[0], result[1])
+	}
+	if provider.Branch("master") != master {
+		t.Fatalf("empty commit is picked to master")
+	}
+}
+
+func TestPickCommits(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	second := provider.CommitFiles("r1", "fix: more", map[string]string{"c.txt": "c"})
+	pick := NewPickService(provider)
+	task := &Task{Repo: "kentio/norn", MergeRequestID: "9"}
+
+	picked, failed, err := pick.pickCommits(ctx, task, "r2", []string{sha, second}, nil)
+	if err != nil || failed != -1 || len(picked) != 2 {
+		t.Fatalf("err: %v failed: %d picked: %v", err, failed, picked)
+	}
+	provider.SetPickError("master", tp.ErrConflict)
+	if _, failed, err = pick.pickCommits(ctx, task, "master", []string{sha, second}, nil); err == nil || failed != 0 {
+		t.Fatalf("err: %v failed: %d", err, failed)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		present, empty, total int
+		want                  string
+	}{
+		{0, 0, 2, ""},
+		{2, 0, 2, "already present"},
+		{1, 0, 2, "1 of 2 commits already present"},
+		{0, 1, 1, "empty pick, change already exists on the branch"},
+		{1, 1, 3, "1 of 3 commits already present; 1 of 3 commits empty, change already exists on the branch"},
+	}
+	for _, tt := range tests {
+		if got := skipReason(tt.present, tt.empty, tt.total); got != tt.want {
+			t.Errorf("skipReason(%d, %d, %d) = %q, want %q", tt.present, tt.empty, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestPerformPickToBranches_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	provider, sha := newFakeRepo()
+	// cancel while picking the first branch
+	pick := NewPickService(&hookProvider{Provider: provider, hook: func(*tp.PickOption) error {
+		cancel()
+		return nil
+	}})
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+	}
+	if err := pick.CreateSummaryWithTask(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, comment, _ := pick.FindCommentWithTask(ctx, task, tp.CherryPickSummaryFlag)
+
+	head := provider.Branch("master")
+	if _, err := pick.PerformPickToBranches(ctx, task, comment); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want canceled", err)
+	}
+	// master is not started and no result is posted
+	if provider.Branch("master") != head || len(provider.Comments("1")) != 1 {
+		t.Fatalf("canceled pick continued")
+	}
+}
+
+func TestProcessPick_DryRun(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	provider.SetPickError("master", tp.ErrConflict)
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+		IsSummary:      true,
+		DryRun:         true,
+	}
+	var out strings.Builder
+	pick := NewPickService(provider)
+	pick.SetOutput(&out)
+
+	// the summary is printed, not posted
+	if err := pick.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("summary err: %v", err)
+	}
+	if len(provider.Comments("1")) != 0 || !strings.Contains(out.String(), "- [x] r2") {
+		t.Fatalf("comments: %v out: %s", provider.Comments("1"), out.String())
+	}
+
+	task.IsSummary, task.DryRun = true, false
+	if err := pick.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("summary err: %v", err)
+	}
+	out.Reset()
+	head := provider.Branch("r2")
+	task.IsSummary, task.DryRun = false, true
+	if err := pick.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("pick err: %v", err)
+	}
+	if provider.Branch("r2") != head || len(provider.Comments("1")) != 1 {
+		t.Fatalf("dry run changed the repo")
+	}
+	for _, want := range []string{"r2", "Succeed", "master", "Failed", "conflict"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("plan does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestProcessPick(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(sha),
+		MergeRequestID: "1",
+	}
+	pick := NewPickService(provider)
+
+	// no summary, nothing to pick
+	head := provider.Branch("r2")
+	if err := pick.ProcessPick(ctx, task); err != nil || provider.Branch("r2") != head {
+		t.Fatalf("err: %v", err)
+	}
+	if len(provider.Comments("1")) != 0 {
+		t.Fatalf("comments: %+v", provider.Comments("1"))
+	}
+
+	task.IsSummary = true
+	if err := pick.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("summary err: %v", err)
+	}
+	task.IsSummary = false
+	if err := pick.ProcessPick(ctx, task); err != nil {
+		t.Fatalf("pick err: %v", err)
+	}
+	for _, branch := range []string{"r2", "master"} {
+		if content, _ := provider.File(branch, "b.txt"); content != "b" {
+			t.Fatalf("%s b.txt: %q", branch, content)
+		}
+	}
+
+	// the result exists, picking again does nothing
+	head = provider.Branch("master")
+	if err := pick.ProcessPick(ctx, task); err != nil || provider.Branch("master") != head {
+		t.Fatalf("err: %v", err)
+	}
+	if comments := provider.Comments("1"); len(comments) != 2 {
+		t.Fatalf("comments: %+v", comments)
+	}
+
+	// comment failures are returned
+	provider.SetError(fake.OpCommentFind, tp.NotFound)
+	if err := pick.ProcessPick(ctx, task); !errors.Is(err, tp.NotFound) {
+		t.Fatalf("err = %v, want not found", err)
+	}
 }
 
 func Test_GetTree(t *testing.T) {
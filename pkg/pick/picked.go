@@ -0,0 +1,150 @@
+package pick
+
+import (
+	"context"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+// historyDepth is the number of recent commits of a branch searched for commits already picked
+const historyDepth = 50
+
+// pickedTrailer matches the line Pick appends to the message of a pick commit
+var pickedTrailer = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{7,40})\)`)
+
+// sourceCommit is a commit to pick, with what identifies its change on other branches
+type sourceCommit struct {
+	SHA     string
+	Subject string
+	PatchID string
+}
+
+// loadSources reads the commits to pick, nil if they can not be read so nothing is detected as picked
+func (s *Service) loadSources(ctx context.Context, task *Task, shas []string) []*sourceCommit {
+	sources := make([]*sourceCommit, 0, len(shas))
+	for _, sha := range shas {
+		commit, err := s.provider.Commit().Get(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: sha})
+		if err != nil {
+			logrus.Warnf("Get commit %s failed, picked commits are not detected: %s", sha, err)
+			return nil
+		}
+		diff, err := s.provider.Commit().Diff(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: sha})
+		if err != nil {
+			logrus.Warnf("Get diff of %s failed, picked commits are not detected: %s", sha, err)
+			return nil
+		}
+		sources = append(sources, &sourceCommit{SHA: sha, Subject: subject(commit.Message()), PatchID: tp.PatchID(diff)})
+	}
+	return sources
+}
+
+// findPicked returns for each commit the commit of the branch that already has its change, "" if
+// there is none. A commit is found by the trailer of a pick, or by the patch id of any commit of the
+// history, like a commit backported by hand under another subject. The commits with the same subject
+// are compared first. The shas are the sources, or the commits picked from them in cascade mode.
+func (s *Service) findPicked(ctx context.Context, task *Task, branch string, shas []string, sources []*sourceCommit) []string {
+	found := make([]string, len(shas))
+	if len(sources) != len(shas) {
+		return found
+	}
+	history, err := s.provider.Commit().List(ctx, &tp.ListCommitOption{Repo: task.Repo, Branch: branch, Limit: historyDepth})
+	if err != nil {
+		logrus.Warnf("List commits of %s failed, picked commits are not detected: %s", branch, err)
+		return found
+	}
+
+	// the patch ids of the history are read once, on demand
+	patchIDs := make(map[string]string, len(history))
+	patchID := func(sha string) string {
+		if id, ok := patchIDs[sha]; ok {
+			return id
+		}
+		diff, err := s.provider.Commit().Diff(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: sha})
+		if err != nil {
+			logrus.Warnf("Get diff of %s failed: %s", sha, err)
+		}
+		patchIDs[sha] = tp.PatchID(diff)
+		return patchIDs[sha]
+	}
+
+	for i, source := range sources {
+		for _, commit := range history {
+			if commit.SHA() == shas[i] || commit.SHA() == source.SHA || pickedFrom(commit.Message(), shas[i], source.SHA) {
+				found[i] = commit.SHA()
+				break
+			}
+		}
+		if found[i] == "" && source.PatchID != "" {
+			found[i] = findPatch(history, source, patchID)
+		}
+		if found[i] != "" {
+			logrus.Infof("Commit %s is already present on %s as %s", shas[i], branch, found[i])
+		}
+	}
+	return found
+}
+
+// findPatch returns the commit of the history with the patch id of the source, the commits with the
+// subject of the source are compared first as they are the most likely to match
+func findPatch(history []tp.Commit, source *sourceCommit, patchID func(sha string) string) string {
+	ordered := make([]tp.Commit, 0, len(history))
+	for _, commit := range history {
+		if subject(commit.Message()) == source.Subject {
+			ordered = append(ordered, commit)
+		}
+	}
+	for _, commit := range history {
+		if subject(commit.Message()) != source.Subject {
+			ordered = append(ordered, commit)
+		}
+	}
+	for _, commit := range ordered {
+		if patchID(commit.SHA()) == source.PatchID {
+			return commit.SHA()
+		}
+	}
+	return ""
+}
+
+// pickedFrom check if the message has the trailer of a pick of one of the commits
+func pickedFrom(message string, shas ...string) bool {
+	for _, match := range pickedTrailer.FindAllStringSubmatch(message, -1) {
+		for _, sha := range shas {
+			if strings.HasPrefix(sha, match[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subject returns the first line of the message
+func subject(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(line)
+}
+
+// presentReason describes the commits found on the branch
+func presentReason(present, total int) string {
+	if present == total {
+		return "already present"
+	}
+	return fmt.Sprintf("%d of %d commits already present", present, total)
+}
+
+// skipReason tells why commits are not picked, the present commits and the empty picks
+func skipReason(present, empty, total int) string {
+	var reasons []string
+	if present > 0 {
+		reasons = append(reasons, presentReason(present, total))
+	}
+	if empty == total {
+		reasons = append(reasons, "empty pick, "+tp.ErrEmptyPick.Error())
+	} else if empty > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d of %d commits empty, %s", empty, total, tp.ErrEmptyPick))
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,161 @@
+package pick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+// revertTrailer matches the line git revert appends to the message of a revert commit
+var revertTrailer = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// ProcessRevert reverts the picks of the commit reverted by Task.SHA on the branches of the task,
+// then submits the result comment, or prints the plan in dry run.
+func (s *Service) ProcessRevert(ctx context.Context, task *Task) error {
+	if task.SHA == nil || *task.SHA == "" {
+		return tp.ErrInvalidOptions
+	}
+	if !task.DryRun {
+		// check if revert result is exist, if existed, skip
+		_, result, err := s.FindCommentWithTask(ctx, task, tp.CherryPickRevertFlag)
+		if err != nil {
+			logrus.Warnf("get revert result err: %s", err)
+			return err
+		}
+		if result != nil {
+			logrus.Warnf("revert result is exist %s.", result)
+			return nil
+		}
+	}
+
+	result, err := s.PerformRevertToBranches(ctx, task)
+	if err != nil {
+		logrus.Errorf("perform revert err: %s", err)
+		return err
+	}
+	if len(result) == 0 {
+		logrus.Warnf("No branch to revert")
+		return nil
+	}
+	if task.DryRun {
+		PrintPlan(s.out, result)
+		return nil
+	}
+
+	content, err := NewResultComment(tp.RevertResultTemplate, result)
+	if err != nil {
+		logrus.Errorf("Generate revert result content failed: %s", err)
+		return err
+	}
+	_, err = s.provider.Comment().Create(ctx, &tp.CreateCommentOption{
+		Repo:           task.Repo,
+		MergeRequestID: task.MergeRequestID,
+		Body:           content,
+	})
+	logrus.Infof("Submit Revert Result Comment: \n%s", content)
+	return err
+}
+
+// PerformRevertToBranches reverts the picks of the reverted commit on every branch of the task
+// except Task.From. A pick is found by its trailer in the recent commits of the branch.
+func (s *Service) PerformRevertToBranches(ctx context.Context, task *Task) ([]*TaskResult, error) {
+	original, err := s.revertedCommit(ctx, task)
+	if err != nil {
+		logrus.Errorf("Get commit %s failed: %s", *task.SHA, err)
+		return nil, err
+	}
+	logrus.Infof("Revert the picks of %s", original)
+
+	var result []*TaskResult
+	for _, branch := range task.Branches {
+		if branch == task.From {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		result = append(result, s.revertBranch(ctx, task, branch, original))
+	}
+	return result, nil
+}
+
+// revertedCommit returns the commit reverted by Task.SHA, or Task.SHA if it is not a revert commit
+func (s *Service) revertedCommit(ctx context.Context, task *Task) (string, error) {
+	commit, err := s.provider.Commit().Get(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: *task.SHA})
+	if err != nil {
+		return "", err
+	}
+	if match := revertTrailer.FindStringSubmatch(commit.Message()); match != nil {
+		return match[1], nil
+	}
+	return commit.SHA(), nil
+}
+
+// revertBranch reverts the pick of the commit on the branch
+func (s *Service) revertBranch(ctx context.Context, task *Task, branch, original string) *TaskResult {
+	pick, reverted, err := s.findPick(ctx, task, branch, original)
+	switch {
+	case err != nil:
+		return &TaskResult{Status: FailedStatus, Branch: branch, Reason: err.Error()}
+	case pick == "":
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: "not picked"}
+	case reverted:
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: "already reverted"}
+	}
+	return s.revertPick(ctx, task, branch, pick)
+}
+
+// revertPick reverts the pick on the branch
+func (s *Service) revertPick(ctx context.Context, task *Task, branch, pick string) *TaskResult {
+	result, err := s.PerformPick(ctx, &CherryPickOptions{
+		SHA:      pick,
+		Repo:     task.Repo,
+		Target:   branch,
+		RepoPath: task.RepoPath,
+		Mainline: task.Mainline,
+		DryRun:   task.DryRun,
+		Revert:   true,
+	})
+	switch {
+	case errors.Is(err, tp.ErrEmptyPick):
+		// the change of the pick is gone from the branch
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: "already reverted"}
+	case errors.Is(err, tp.NotFound):
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: err.Error()}
+	case err != nil:
+		return &TaskResult{Status: FailedStatus, Branch: branch, Reason: err.Error(), Commit: pick}
+	}
+	logrus.Infof("Revert %s on %s: %s", pick, branch, result.SHA)
+	return &TaskResult{Status: SucceedStatus, Branch: branch, Reason: fmt.Sprintf("reverted %s", shortSHA(pick)), Tree: result.Tree}
+}
+
+// findPick returns the commit of the branch picked from the original, "" if there is none, and
+// whether a revert of it is on the branch. The original itself counts as its pick.
+func (s *Service) findPick(ctx context.Context, task *Task, branch, original string) (string, bool, error) {
+	history, err := s.provider.Commit().List(ctx, &tp.ListCommitOption{Repo: task.Repo, Branch: branch, Limit: historyDepth})
+	if err != nil {
+		logrus.Warnf("List commits of %s failed: %s", branch, err)
+		return "", false, err
+	}
+	// the history is newest first, so the reverts of a pick are seen before it
+	var reverts []string
+	for _, commit := range history {
+		if match := revertTrailer.FindStringSubmatch(commit.Message()); match != nil {
+			reverts = append(reverts, match[1])
+		}
+		if !strings.HasPrefix(commit.SHA(), original) && !pickedFrom(commit.Message(), original) {
+			continue
+		}
+		for _, reverted := range reverts {
+			if strings.HasPrefix(commit.SHA(), reverted) {
+				return commit.SHA(), true, nil
+			}
+		}
+		return commit.SHA(), false, nil
+	}
+	return "", false, nil
+}
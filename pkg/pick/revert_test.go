@@ -0,0 +1,112 @@
+package pick
+
+import (
+	"context"
+	"github.com/kentio/norn/pkg/common"
+	"github.com/kentio/norn/pkg/fake"
+	tp "github.com/kentio/norn/pkg/types"
+	"strings"
+	"testing"
+)
+
+// newRevertedRepo picks the fix of r1 to r2, then reverts the fix on r1 and returns the revert commit
+func newRevertedRepo(t *testing.T) (*fake.Provider, string) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	if _, err := provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	revert, err := provider.Pick().Revert(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r1"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return provider, revert.SHA
+}
+
+func TestProcessRevert(t *testing.T) {
+	ctx := context.Background()
+	provider, revert := newRevertedRepo(t)
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2", "master"},
+		From:           "r1",
+		SHA:            common.String(revert),
+		MergeRequestID: "2",
+	}
+	pick := NewPickService(provider)
+
+	if err := pick.ProcessRevert(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := provider.File("r2", "b.txt"); ok {
+		t.Fatalf("the pick on r2 is not reverted")
+	}
+	comments := provider.Comments("2")
+	if len(comments) != 1 || !strings.Contains(comments[0], tp.CherryPickRevertFlag) {
+		t.Fatalf("comments: %v", comments)
+	}
+	for _, want := range []string{"r2", "Succeed", "master", "not picked"} {
+		if !strings.Contains(comments[0], want) {
+			t.Fatalf("result comment does not contain %q:\n%s", want, comments[0])
+		}
+	}
+
+	// the result comment is submitted once
+	if err := pick.ProcessRevert(ctx, task); err != nil || len(provider.Comments("2")) != 1 {
+		t.Fatalf("err: %v comments: %v", err, provider.Comments("2"))
+	}
+
+	// the revert is found on the branch
+	r2 := provider.Branch("r2")
+	result, err := pick.PerformRevertToBranches(ctx, task)
+	if err != nil || len(result) != 2 || result[0].Status != SkipStatus || result[0].Reason != "already reverted" {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if provider.Branch("r2") != r2 {
+		t.Fatalf("r2 is reverted again")
+	}
+}
+
+func TestProcessRevert_DryRun(t *testing.T) {
+	ctx := context.Background()
+	provider, revert := newRevertedRepo(t)
+	task := &Task{
+		Repo:           "kentio/norn",
+		Branches:       []string{"r1", "r2"},
+		From:           "r1",
+		SHA:            common.String(revert),
+		MergeRequestID: "2",
+		DryRun:         true,
+	}
+	var out strings.Builder
+	pick := NewPickService(provider)
+	pick.SetOutput(&out)
+
+	r2 := provider.Branch("r2")
+	if err := pick.ProcessRevert(ctx, task); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.Branch("r2") != r2 || len(provider.Comments("2")) != 0 {
+		t.Fatalf("dry run changed the repo")
+	}
+	if !strings.Contains(out.String(), "r2") || !strings.Contains(out.String(), "Succeed") {
+		t.Fatalf("plan: %s", out.String())
+	}
+}
+
+func TestPerformRevertToBranches_Conflict(t *testing.T) {
+	ctx := context.Background()
+	provider, revert := newRevertedRepo(t)
+	provider.SetPickError("r2", tp.ErrConflict)
+	task := &Task{
+		Repo:     "kentio/norn",
+		Branches: []string{"r1", "r2"},
+		From:     "r1",
+		SHA:      common.String(revert),
+	}
+
+	result, err := NewPickService(provider).PerformRevertToBranches(ctx, task)
+	if err != nil || len(result) != 1 || result[0].Status != FailedStatus || result[0].Reason != tp.ErrConflict.Error() {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+}
@@ -0,0 +1,150 @@
+package pick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tp "github.com/kentio/norn/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+type RollbackOption struct {
+	Repo     string
+	SHA      string   // the pick, or the commit it is picked from
+	Branches []string // the branches the pick is rolled back on
+	// Shared are the other branches the pick may be on, such as the branches of the profile. A pick
+	// found on one of them is reverted instead of reset.
+	Shared []string
+	// MergeRequestID is the merge request the result is commented on, no comment if empty
+	MergeRequestID string
+	RepoPath       string
+	// DryRun prints the plan, no branch is updated and no comment is posted
+	DryRun bool
+}
+
+// ProcessRollback undoes the pick of the commit on the branches, then submits the result comment,
+// or prints the plan in dry run. A pick at the head of a branch which no other branch contains is
+// removed by resetting the branch to its parent, any other pick is reverted.
+func (s *Service) ProcessRollback(ctx context.Context, opt *RollbackOption) error {
+	if opt == nil || opt.SHA == "" || len(opt.Branches) == 0 {
+		return tp.ErrInvalidOptions
+	}
+	result, err := s.PerformRollbackToBranches(ctx, opt)
+	if err != nil {
+		logrus.Errorf("perform rollback err: %s", err)
+		return err
+	}
+	if opt.DryRun {
+		PrintPlan(s.out, result)
+		return nil
+	}
+	if opt.MergeRequestID == "" {
+		return nil
+	}
+
+	content, err := NewResultComment(tp.RollbackResultTemplate, result)
+	if err != nil {
+		logrus.Errorf("Generate rollback result content failed: %s", err)
+		return err
+	}
+	_, err = s.provider.Comment().Create(ctx, &tp.CreateCommentOption{
+		Repo:           opt.Repo,
+		MergeRequestID: opt.MergeRequestID,
+		Body:           content,
+	})
+	logrus.Infof("Submit Rollback Result Comment: \n%s", content)
+	return err
+}
+
+// PerformRollbackToBranches undoes the pick of the commit on every branch of the option
+func (s *Service) PerformRollbackToBranches(ctx context.Context, opt *RollbackOption) ([]*TaskResult, error) {
+	task := &Task{Repo: opt.Repo, SHA: &opt.SHA, MergeRequestID: opt.MergeRequestID, RepoPath: opt.RepoPath, DryRun: opt.DryRun}
+	var result []*TaskResult
+	for _, branch := range opt.Branches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result = append(result, s.rollbackBranch(ctx, task, branch, opt.Shared))
+	}
+	return result, nil
+}
+
+// rollbackBranch resets the branch to the parent of the pick if the pick is its unshared head,
+// and reverts the pick otherwise
+func (s *Service) rollbackBranch(ctx context.Context, task *Task, branch string, shared []string) *TaskResult {
+	pick, reverted, err := s.findPick(ctx, task, branch, *task.SHA)
+	switch {
+	case err != nil:
+		return &TaskResult{Status: FailedStatus, Branch: branch, Reason: err.Error()}
+	case pick == "":
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: "not picked"}
+	case reverted:
+		return &TaskResult{Status: SkipStatus, Branch: branch, Reason: "already reverted"}
+	}
+
+	parent, err := s.resetTarget(ctx, task, branch, pick, shared)
+	if err != nil {
+		return &TaskResult{Status: FailedStatus, Branch: branch, Reason: err.Error(), Commit: pick}
+	}
+	if parent == "" {
+		return s.revertPick(ctx, task, branch, pick)
+	}
+	reason := fmt.Sprintf("reset to %s", shortSHA(parent))
+	if task.DryRun {
+		return &TaskResult{Status: SucceedStatus, Branch: branch, Reason: reason}
+	}
+	_, err = s.provider.Reference().Update(ctx, &tp.UpdateOption{
+		Repo:        task.Repo,
+		Ref:         "refs/heads/" + branch,
+		SHA:         parent,
+		ExpectedSHA: pick,
+		Force:       true,
+	})
+	switch {
+	case errors.Is(err, tp.ErrNotSupported), errors.Is(err, tp.ErrStaleRef):
+		// the provider can not reset, or a commit landed on the pick in the meantime
+		logrus.Infof("Reset %s to %s failed: %s, revert %s instead", branch, parent, err, pick)
+		return s.revertPick(ctx, task, branch, pick)
+	case err != nil:
+		return &TaskResult{Status: FailedStatus, Branch: branch, Reason: err.Error(), Commit: pick}
+	}
+	logrus.Infof("Reset %s to %s, %s is removed", branch, parent, pick)
+	return &TaskResult{Status: SucceedStatus, Branch: branch, Reason: reason}
+}
+
+// resetTarget returns the parent the branch is reset to, "" if the pick is not the head of the
+// branch, is a merge commit, or may be on one of the shared branches. A shared branch the provider
+// can not tell the ancestry of is taken as containing the pick.
+func (s *Service) resetTarget(ctx context.Context, task *Task, branch, pick string, shared []string) (string, error) {
+	head, err := s.provider.Reference().Get(ctx, &tp.GetRefOption{Repo: task.Repo, Ref: "refs/heads/" + branch})
+	if err != nil {
+		return "", err
+	}
+	if head.SHA != pick {
+		return "", nil
+	}
+	commit, err := s.provider.Commit().Get(ctx, &tp.GetCommitOption{Repo: task.Repo, SHA: pick})
+	if err != nil {
+		return "", err
+	}
+	if len(commit.Parents()) != 1 {
+		return "", nil
+	}
+	for _, other := range shared {
+		if other == branch {
+			continue
+		}
+		contains, err := s.provider.Commit().IsAncestor(ctx, &tp.AncestorOption{Repo: task.Repo, SHA: pick, Branch: other})
+		switch {
+		case errors.Is(err, tp.NotFound):
+			continue
+		case err != nil:
+			logrus.Infof("Can not tell if %s is on %s: %s, revert it on %s", pick, other, err, branch)
+			return "", nil
+		case contains:
+			logrus.Infof("%s is on %s too, revert it on %s", pick, other, branch)
+			return "", nil
+		}
+	}
+	return commit.Parents()[0], nil
+}
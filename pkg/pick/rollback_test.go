@@ -0,0 +1,205 @@
+package pick
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gh "github.com/google/go-github/v62/github"
+	"github.com/kentio/norn/pkg/fake"
+	"github.com/kentio/norn/pkg/github"
+	tp "github.com/kentio/norn/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProcessRollback_Reset(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	root := provider.Branch("r2")
+	if _, err := provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	opt := &RollbackOption{Repo: "kentio/norn", SHA: sha, Branches: []string{"r2"}, Shared: []string{"r1", "r2", "master"}, MergeRequestID: "1"}
+
+	if err := NewPickService(provider).ProcessRollback(ctx, opt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.Branch("r2") != root {
+		t.Fatalf("r2 is at %s, want %s", provider.Branch("r2"), root)
+	}
+	comments := provider.Comments("1")
+	if len(comments) != 1 || !strings.Contains(comments[0], tp.CherryPickRollbackFlag) || !strings.Contains(comments[0], "reset to "+root[:7]) {
+		t.Fatalf("comments: %v", comments)
+	}
+
+	// the pick is gone
+	result, err := NewPickService(provider).PerformRollbackToBranches(ctx, opt)
+	if err != nil || result[0].Status != SkipStatus || result[0].Reason != "not picked" {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+}
+
+func TestProcessRollback_Revert(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	picked, err := provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the pick is shared with a branch created from it
+	provider.CreateBranch("r3", picked.SHA)
+	opt := &RollbackOption{Repo: "kentio/norn", SHA: picked.SHA, Branches: []string{"r2"}, Shared: []string{"r2", "r3"}}
+
+	result, err := NewPickService(provider).PerformRollbackToBranches(ctx, opt)
+	if err != nil || result[0].Status != SucceedStatus || result[0].Reason != "reverted "+picked.SHA[:7] {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if _, ok := provider.File("r2", "b.txt"); ok || provider.Branch("r3") != picked.SHA {
+		t.Fatalf("the pick is not reverted on r2 or r3 is changed")
+	}
+
+	// the pick is deep in the history of the shared branch
+	provider, sha = newFakeRepo()
+	if picked, err = provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	provider.CreateBranch("r3", picked.SHA)
+	for i := 0; i < historyDepth+10; i++ {
+		provider.CommitFiles("r3", fmt.Sprintf("feat: %d", i), map[string]string{"c.txt": fmt.Sprint(i)})
+	}
+	opt = &RollbackOption{Repo: "kentio/norn", SHA: picked.SHA, Branches: []string{"r2"}, Shared: []string{"r2", "r3"}}
+	result, err = NewPickService(provider).PerformRollbackToBranches(ctx, opt)
+	if err != nil || result[0].Reason != "reverted "+picked.SHA[:7] {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+
+	// the ancestry can not be told
+	provider, sha = newFakeRepo()
+	if picked, err = provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	provider.SetError(fake.OpCommitIsAncestor, tp.ErrNotSupported)
+	opt = &RollbackOption{Repo: "kentio/norn", SHA: picked.SHA, Branches: []string{"r2"}, Shared: []string{"r1", "r2"}}
+	result, err = NewPickService(provider).PerformRollbackToBranches(ctx, opt)
+	if err != nil || result[0].Reason != "reverted "+picked.SHA[:7] {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+
+	// the pick is not the head
+	provider, sha = newFakeRepo()
+	if _, err = provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	provider.CommitFiles("r2", "feat: c", map[string]string{"c.txt": "c"})
+	opt = &RollbackOption{Repo: "kentio/norn", SHA: sha, Branches: []string{"r2"}}
+	result, err = NewPickService(provider).PerformRollbackToBranches(ctx, opt)
+	if err != nil || result[0].Status != SucceedStatus || !strings.HasPrefix(result[0].Reason, "reverted") {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if _, ok := provider.File("r2", "c.txt"); !ok {
+		t.Fatalf("the later commit is removed")
+	}
+}
+
+func TestProcessRollback_DryRun(t *testing.T) {
+	ctx := context.Background()
+	provider, sha := newFakeRepo()
+	if _, err := provider.Pick().Pick(ctx, "kentio/norn", &tp.PickOption{SHA: sha, Branch: "r2"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	r2 := provider.Branch("r2")
+	var out strings.Builder
+	pick := NewPickService(provider)
+	pick.SetOutput(&out)
+
+	err := pick.ProcessRollback(ctx, &RollbackOption{Repo: "kentio/norn", SHA: sha, Branches: []string{"r2", "master"}, MergeRequestID: "1", DryRun: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if provider.Branch("r2") != r2 || len(provider.Comments("1")) != 0 {
+		t.Fatalf("dry run changed the repo")
+	}
+	for _, want := range []string{"reset to", "not picked"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("plan does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if err = pick.ProcessRollback(ctx, &RollbackOption{Repo: "kentio/norn", Branches: []string{"r2"}}); err != tp.ErrInvalidOptions {
+		t.Fatalf("err = %v, want invalid options", err)
+	}
+}
+
+// TestProcessRollback_GitHubReset rolls back a pick on GitHub, the branch is reset by the
+// compare-and-swap of the GraphQL API and no revert commit is written
+func TestProcessRollback_GitHubReset(t *testing.T) {
+	const source, root, picked = "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222", "3333333333333333333333333333333333333333"
+	head := picked
+	commit := func(sha, message string, parents ...string) map[string]any {
+		ps := []map[string]string{}
+		for _, p := range parents {
+			ps = append(ps, map[string]string{"sha": p})
+		}
+		return map[string]any{"sha": sha, "commit": map[string]any{"message": message, "tree": map[string]string{"sha": "t" + sha[:1]}}, "parents": ps}
+	}
+	commits := map[string]map[string]any{
+		picked: commit(picked, "fix: bug\n\n(cherry picked from commit "+source[:7]+")", root),
+		root:   commit(root, "init"),
+	}
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"node_id": "R_1"})
+	})
+	mux.HandleFunc("GET /repos/o/r/commits", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{commits[head], commits[root]})
+	})
+	mux.HandleFunc("GET /repos/o/r/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, commits[r.PathValue("sha")])
+	})
+	mux.HandleFunc("GET /repos/o/r/git/ref/heads/r2", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"ref": "refs/heads/r2", "object": map[string]string{"sha": head}})
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Variables struct {
+				Input struct {
+					RefUpdates []struct {
+						Name, AfterOid, BeforeOid string
+						Force                     bool
+					}
+				}
+			}
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		update := body.Variables.Input.RefUpdates[0]
+		if update.Name != "refs/heads/r2" || update.BeforeOid != head || !update.Force {
+			writeJSON(w, map[string]any{"errors": []map[string]string{{"message": "rejected"}}})
+			return
+		}
+		head = update.AfterOid
+		writeJSON(w, map[string]any{"data": map[string]any{}})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client := gh.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	opt := &RollbackOption{Repo: "o/r", SHA: source, Branches: []string{"r2"}}
+	result, err := NewPickService(github.NewProviderWithClient(client)).PerformRollbackToBranches(context.Background(), opt)
+	if err != nil || result[0].Status != SucceedStatus || result[0].Reason != "reset to "+root[:7] {
+		t.Fatalf("err: %v result: %+v", err, result)
+	}
+	if head != root {
+		t.Fatalf("r2 is at %s, want %s", head, root)
+	}
+}
@@ -0,0 +1,163 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/ssh"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const payload = "tree 4b825dc642cb6eb9a060e54bf8d69288fbce04d9\n" +
+	"author norn <norn@example.com> 1704164645 +0000\n" +
+	"committer norn <norn@example.com> 1704164645 +0000\n\nfix: bug\n"
+
+// verifySSH checks the signature of the payload is made by the key in the git namespace
+func verifySSH(t *testing.T, signature string, key ssh.PublicKey) {
+	body := strings.TrimPrefix(strings.TrimSpace(signature), "-----BEGIN SSH SIGNATURE-----\n")
+	body = strings.TrimSuffix(body, "\n-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+	if err != nil || !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		t.Fatalf("err: %v signature: %s", err, signature)
+	}
+	sig := struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{}
+	if err = ssh.Unmarshal(blob[len(sshSigMagic):], &sig); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if sig.Version != 1 || !bytes.Equal(sig.PublicKey, key.Marshal()) || sig.Namespace != Namespace || sig.HashAlgorithm != "sha512" {
+		t.Fatalf("signature: %+v", sig)
+	}
+	raw := &ssh.Signature{}
+	if err = ssh.Unmarshal(sig.Signature, raw); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	hash := sha512.Sum512([]byte(payload))
+	signed := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          string
+	}{Namespace, "", "sha512", string(hash[:])})...)
+	if err = key.Verify(signed, raw); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestSSHSigner(t *testing.T) {
+	_, ed, _ := ed25519.GenerateKey(rand.Reader)
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for name, key := range map[string]any{"ed25519": ed, "rsa": rk} {
+		t.Run(name, func(t *testing.T) {
+			block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "norn", []byte("secret"))
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "id")
+			if err = os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if _, err = LoadSigner(path, []byte("wrong")); err == nil {
+				t.Fatalf("the key is decrypted by a wrong passphrase")
+			}
+			signer, err := LoadSigner(path, []byte("secret"))
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			var signature strings.Builder
+			if err = signer.Sign(&signature, strings.NewReader(payload)); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			verifySSH(t, signature.String(), signer.(*SSHSigner).signer.PublicKey())
+		})
+	}
+}
+
+func TestSSHSigner_SSHKeygen(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	block, _ := ssh.MarshalPrivateKey(key, "norn")
+	signer, err := NewSigner(pem.EncodeToMemory(block), nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var signature strings.Builder
+	if err = signer.Sign(&signature, strings.NewReader(payload)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, "payload.sig"), []byte(signature.String()), 0o600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cmd := exec.Command("ssh-keygen", "-Y", "check-novalidate", "-n", Namespace, "-s", filepath.Join(dir, "payload.sig"))
+	cmd.Stdin = strings.NewReader(payload)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("err: %v %s", err, out)
+	}
+}
+
+func TestOpenPGPSigner(t *testing.T) {
+	entity, err := openpgp.NewEntity("norn", "", "norn@example.com", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err = entity.EncryptPrivateKeys([]byte("secret"), nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var key bytes.Buffer
+	w, _ := armor.Encode(&key, openpgp.PrivateKeyType, nil)
+	if err = entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_ = w.Close()
+
+	if _, err = NewSigner(key.Bytes(), []byte("wrong")); err == nil {
+		t.Fatalf("the key is decrypted by a wrong passphrase")
+	}
+	signer, err := NewSigner(key.Bytes(), []byte("secret"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var signature strings.Builder
+	if err = signer.Sign(&signature, strings.NewReader(payload)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.HasPrefix(signature.String(), "-----BEGIN PGP SIGNATURE-----") {
+		t.Fatalf("signature: %s", signature.String())
+	}
+	_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, strings.NewReader(payload), strings.NewReader(signature.String()), nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestNewSigner_Invalid(t *testing.T) {
+	if _, err := NewSigner([]byte("not a key"), nil); err == nil {
+		t.Fatalf("invalid key is parsed")
+	}
+	if _, err := LoadSigner(filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Fatalf("missing key is loaded")
+	}
+}
@@ -1,12 +1,111 @@
 package types
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type PickOption struct {
 	SHA    string
 	Branch string
+	// Mainline is the 1-based parent number of a merge commit to diff against, like git cherry-pick -m.
+	// It is required to pick a merge commit.
+	Mainline int
+	// DryRun computes the pick without updating the branch, conflicts are still reported.
+	DryRun bool
+	// Message is the message of the pick commit, the message of the commit with the
+	// "(cherry picked from commit <sha7>)" trailer if empty. The local engine appends the
+	// trailer to the message if it is missing. Revert ignores it.
+	Message string
+	// Committer is the committer of the pick commit, the committer of the commit if nil.
+	// The author of the commit is kept.
+	Committer *Signature
+	// DateMode sets the committer date of the pick commit, DateNow if empty. Revert ignores
+	// both, the revert is committed now.
+	DateMode DateMode
+}
+
+// DateMode is how the committer date of a pick commit is set, the author date is always kept
+type DateMode string
+
+const (
+	DateNow      DateMode = "now"      // the time of the pick
+	DateOriginal DateMode = "original" // the committer date of the picked commit
+)
+
+// ParseDateMode parses the date mode, empty is DateNow
+func ParseDateMode(mode string) (DateMode, error) {
+	switch DateMode(mode) {
+	case "", DateNow:
+		return DateNow, nil
+	case DateOriginal:
+		return DateOriginal, nil
+	default:
+		return "", fmt.Errorf("%w: date mode %q, expected now or original", ErrInvalidOptions, mode)
+	}
+}
+
+// CommitterDate returns the committer date of a pick of a commit committed at original
+func (m DateMode) CommitterDate(original time.Time) time.Time {
+	if m == DateOriginal {
+		return original
+	}
+	return time.Now()
+}
+
+// PickResult is the outcome of a pick
+type PickResult struct {
+	SHA  string // the pick commit, empty in dry run
+	Tree string // the tree of the pick commit, empty if the provider does not report it
 }
 
 type PickService interface {
-	Pick(ctx context.Context, repo string, opt *PickOption) error
+	Pick(ctx context.Context, repo string, opt *PickOption) (*PickResult, error)
+	// Revert creates a commit on the branch undoing the change of the commit, like git revert.
+	// Mainline selects the parent of a merge commit the change is undone against.
+	Revert(ctx context.Context, repo string, opt *PickOption) (*PickResult, error)
+}
+
+// PickMessage returns the message of the pick commit of the commit, the message with the
+// "(cherry picked from commit <sha7>)" trailer appended unless it has the trailer of the commit
+func PickMessage(sha, message string) string {
+	for rest := message; ; {
+		_, after, ok := strings.Cut(rest, "(cherry picked from commit ")
+		if !ok {
+			break
+		}
+		picked, _, _ := strings.Cut(after, ")")
+		if len(picked) >= 7 && strings.HasPrefix(sha, picked) {
+			return message
+		}
+		rest = after
+	}
+	return fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimRight(message, "\n"), sha[:7])
+}
+
+// RevertMessage returns the message of the commit reverting the commit, like git revert
+func RevertMessage(sha, message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	return fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", strings.TrimSpace(subject), sha)
+}
+
+// MainlineParent returns the index of the parent the commit is picked against.
+// A merge commit requires the mainline, other commits accept no mainline or 1.
+func MainlineParent(parents int, mainline int) (int, error) {
+	switch {
+	case parents == 0:
+		return 0, fmt.Errorf("%w: root commit can not be picked", ErrInvalidOptions)
+	case mainline < 0:
+		return 0, fmt.Errorf("%w: invalid mainline %d", ErrInvalidOptions, mainline)
+	case parents > 1 && mainline == 0:
+		return 0, ErrMainlineRequired
+	case mainline > parents:
+		return 0, fmt.Errorf("%w: mainline %d but the commit has %d parents", ErrInvalidOptions, mainline, parents)
+	case mainline == 0:
+		return 0, nil
+	default:
+		return mainline - 1, nil
+	}
 }
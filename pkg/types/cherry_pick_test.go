@@ -0,0 +1,81 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMainlineParent(t *testing.T) {
+	tests := []struct {
+		parents  int
+		mainline int
+		want     int
+		wantErr  error
+	}{
+		{parents: 1, mainline: 0, want: 0},
+		{parents: 1, mainline: 1, want: 0},
+		{parents: 1, mainline: 2, wantErr: ErrInvalidOptions},
+		{parents: 2, mainline: 0, wantErr: ErrMainlineRequired},
+		{parents: 2, mainline: 2, want: 1},
+		{parents: 2, mainline: 3, wantErr: ErrInvalidOptions},
+		{parents: 2, mainline: -1, wantErr: ErrInvalidOptions},
+		{parents: 0, mainline: 0, wantErr: ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		got, err := MainlineParent(tt.parents, tt.mainline)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MainlineParent(%d, %d) err = %v, want %v", tt.parents, tt.mainline, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("MainlineParent(%d, %d) = %d, %v, want %d", tt.parents, tt.mainline, got, err, tt.want)
+		}
+	}
+}
+
+func TestRevertMessage(t *testing.T) {
+	want := "Revert \"fix: bug\"\n\nThis reverts commit abc."
+	if got := RevertMessage("abc", "fix: bug \n\nbody\n"); got != want {
+		t.Fatalf("RevertMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestParseDateMode(t *testing.T) {
+	for mode, want := range map[string]DateMode{"": DateNow, "now": DateNow, "original": DateOriginal} {
+		if got, err := ParseDateMode(mode); err != nil || got != want {
+			t.Errorf("ParseDateMode(%q) = %q, %v, want %q", mode, got, err, want)
+		}
+	}
+	if _, err := ParseDateMode("author"); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("err = %v, want invalid options", err)
+	}
+
+	original := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := DateOriginal.CommitterDate(original); !got.Equal(original) {
+		t.Errorf("original date = %v", got)
+	}
+	if got := DateNow.CommitterDate(original); got.Before(time.Now().Add(-time.Minute)) {
+		t.Errorf("now date = %v", got)
+	}
+}
+
+func TestPickMessage(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"fix: bug\n", "fix: bug\n\n(cherry picked from commit 0123456)"},
+		{"fix: bug\n\n(cherry picked from commit 0123456789)", "fix: bug\n\n(cherry picked from commit 0123456789)"},
+		// the trailer of another commit is kept
+		{"fix: bug\n\n(cherry picked from commit fedcba9)", "fix: bug\n\n(cherry picked from commit fedcba9)\n\n(cherry picked from commit 0123456)"},
+	}
+	for _, tt := range tests {
+		if got := PickMessage(sha, tt.message); got != tt.want {
+			t.Errorf("PickMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"io"
 )
 
 type GetCommitOption struct {
@@ -9,6 +10,18 @@ type GetCommitOption struct {
 	SHA  string
 }
 
+type ListCommitOption struct {
+	Repo   string
+	Branch string
+	Limit  int // the number of most recent commits
+}
+
+type AncestorOption struct {
+	Repo   string
+	SHA    string
+	Branch string
+}
+
 type CreateCommitOption struct {
 	Repo        string
 	Tree        Tree
@@ -16,6 +29,9 @@ type CreateCommitOption struct {
 	PickMessage string
 	Target      string
 	Parents     []string
+	// Committer and DateMode work like in PickOption, the author of SHA is kept
+	Committer *Signature
+	DateMode  DateMode
 }
 
 type CheckConflictMode int
@@ -29,15 +45,32 @@ type CheckConflictOption struct {
 	Repo     string
 	Commit   string
 	Target   string
-	RepoPath string // only used for GitHub, because GitHub not support api for check conflict
+	RepoPath string // only used for GitHub WithCommand, the clone the patch is applied in
+	Remote   string // only used for GitHub WithCommand, the remote of the target branch, "origin" if empty
 	Mode     CheckConflictMode
 	Pr       int
+	Mainline int // parent number of a merge commit, see PickOption.Mainline
 }
 
 type Commit interface {
 	SHA() string
 	Tree() Tree
 	Message() string
+	Author() Signature
+	// Parents returns the shas of the parents, the first parent first
+	Parents() []string
+}
+
+// Signature is the name and email of an author or a committer
+type Signature struct {
+	Name  string
+	Email string
+}
+
+// Signer signs the payload of a commit read from r, the armored signature is written to w.
+// It works like the gpg.program of git, see the sign package for OpenPGP and SSH keys.
+type Signer interface {
+	Sign(w io.Writer, r io.Reader) error
 }
 
 type Tree interface {
@@ -58,6 +91,13 @@ type TreeEntry interface {
 
 type CommitService interface {
 	Get(ctx context.Context, opt *GetCommitOption) (Commit, error)
+	// List returns the recent commits of the branch, newest first
+	List(ctx context.Context, opt *ListCommitOption) ([]Commit, error)
+	// Diff returns the diff of the commit against its first parent in the git diff format
+	Diff(ctx context.Context, opt *GetCommitOption) (string, error)
 	Create(ctx context.Context, opt *CreateCommitOption) (Commit, error)
 	CheckConflict(ctx context.Context, opt *CheckConflictOption) error
+	// IsAncestor reports whether the commit is in the history of the branch, NotFound if the branch
+	// does not exist, ErrNotSupported if the provider can not tell
+	IsAncestor(ctx context.Context, opt *AncestorOption) (bool, error)
 }
@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 type ProviderError struct {
 	Message string
 }
@@ -14,9 +16,31 @@ func NewProviderError(message string) *ProviderError {
 	}
 }
 
+// ConflictError is a conflict of a pick whose conflicting files are known
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return "conflict: " + strings.Join(e.Files, ", ")
+}
+
+// Is makes errors.Is(err, ErrConflict) true
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 var (
 	ErrInvalidOptions = NewProviderError("invalid parameter")
 	ErrConflict       = NewProviderError("conflict")
+	ErrNotSupported   = NewProviderError("not supported")
+	ErrAlreadyExists  = NewProviderError("already exists")
+	// ErrMainlineRequired a merge commit is picked without the mainline parent
+	ErrMainlineRequired = NewProviderError("merge commit requires a mainline parent")
+	// ErrStaleRef the ref is updated by someone else after it is read
+	ErrStaleRef = NewProviderError("reference is updated concurrently")
+	// ErrEmptyPick the change of the picked commit already exists on the branch
+	ErrEmptyPick = NewProviderError("change already exists on the branch")
 
 	NotFound = NewProviderError("not found")
 
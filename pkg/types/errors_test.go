@@ -0,0 +1,20 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestConflictError(t *testing.T) {
+	err := fmt.Errorf("pick: %w", &ConflictError{Files: []string{"a.go", "b.go"}})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("%v is not a conflict", err)
+	}
+	if errors.Is(err, ErrStaleRef) {
+		t.Errorf("%v is a stale ref", err)
+	}
+	if want := "pick: conflict: a.go, b.go"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
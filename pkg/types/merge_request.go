@@ -6,6 +6,10 @@ type MergeRequestState int
 
 type MergeRequestService interface {
 	Get(ctx context.Context, opt *GetMergeRequestOption) (MergeRequest, error)
+	// ListCommits returns the commits of the merge request, oldest first
+	ListCommits(ctx context.Context, opt *ListMergeRequestCommitsOption) ([]Commit, error)
+	// Create opens a merge request, ErrAlreadyExists if one of the head to the base is open
+	Create(ctx context.Context, opt *CreateMergeRequestOption) (MergeRequest, error)
 }
 
 type MergeRequest interface {
@@ -13,6 +17,8 @@ type MergeRequest interface {
 	MergeId() string
 	Title() string
 	Description() string
+	Labels() []string
+	WebUrl() string
 }
 
 type GetMergeRequestOption struct {
@@ -20,6 +26,20 @@ type GetMergeRequestOption struct {
 	MergeID string
 }
 
+type CreateMergeRequestOption struct {
+	Repo        string
+	Title       string
+	Description string
+	Head        string // source branch
+	Base        string // target branch
+	Labels      []string
+}
+
+type ListMergeRequestCommitsOption struct {
+	Repo    string
+	MergeID string
+}
+
 type CreateCommentOption struct {
 	// MergeRequestID is the ID of the merge request to comment on. also known as IssueID
 	MergeRequestID string
@@ -37,6 +57,7 @@ type UpdateCommentOption struct {
 
 type DeleteCommentOption struct {
 	// CommentID is the ID of the comment to delete.
-	CommentID string
-	Repo      string
+	CommentID      string
+	Repo           string
+	MergeRequestID string
 }
@@ -4,8 +4,5 @@ type FindOptions struct {
 	// Tags is the tags of the BranchRef.
 }
 
-type CreateOptions struct {
-}
-
 type DeleteOptions struct {
 }
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PatchID returns an id of the change of a diff in the git diff format, like git patch-id --stable.
+// Line numbers, blob ids, whitespace and the order of the files are ignored, so a commit and
+// its cherry-pick have the same id. An empty diff returns "".
+func PatchID(diff string) string {
+	var files []string
+	var file strings.Builder
+	flush := func() {
+		if file.Len() > 0 {
+			files = append(files, file.String())
+			file.Reset()
+		}
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+		case strings.HasPrefix(line, "index "), strings.HasPrefix(line, "@@"),
+			strings.HasPrefix(line, "old mode "), strings.HasPrefix(line, "new mode "),
+			strings.HasPrefix(line, "similarity index "), strings.HasPrefix(line, "\\ No newline"):
+			continue
+		}
+		if line = strings.Join(strings.Fields(line), ""); line != "" {
+			file.WriteString(line)
+			file.WriteByte('\n')
+		}
+	}
+	flush()
+	if len(files) == 0 {
+		return ""
+	}
+
+	sort.Strings(files)
+	h := sha1.New()
+	for _, f := range files {
+		h.Write([]byte(f))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package types
+
+import "testing"
+
+const patchA = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++fix
+ c
+diff --git a/b.txt b/b.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1 @@
++b
+`
+
+// patchB is patchA picked to another branch, the files are in another order
+const patchB = `diff --git a/b.txt b/b.txt
+new file mode 100644
+index 0000000..4444444
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1 @@
++b
+diff --git a/a.txt b/a.txt
+index 5555555..6666666 100644
+--- a/a.txt
++++ b/a.txt
+@@ -10,3 +10,3 @@ func main() {
+ a
+-b
++fix
+ c
+`
+
+func TestPatchID(t *testing.T) {
+	if PatchID(patchA) == "" || PatchID(patchA) != PatchID(patchB) {
+		t.Fatalf("patch ids differ: %s %s", PatchID(patchA), PatchID(patchB))
+	}
+	changed := patchA[:len(patchA)-len("+b\n")] + "+c\n"
+	if PatchID(changed) == PatchID(patchA) {
+		t.Fatalf("different changes have the same patch id")
+	}
+	if PatchID("") != "" {
+		t.Fatalf("empty diff has patch id")
+	}
+}
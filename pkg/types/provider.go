@@ -1,16 +1,63 @@
 package types
 
+import "fmt"
+
 type ProviderType string
 
+// PickEngine is how the picks are made
+type PickEngine string
+
+const (
+	EngineAPI   PickEngine = "api"   // by the API of the vendor
+	EngineLocal PickEngine = "local" // by git in a local clone, pushed to the vendor
+	// EngineMerge merges the trees read by the API in process and creates the pick commit only,
+	// GitHub only
+	EngineMerge PickEngine = "merge"
+)
+
+// ParsePickEngine parses the engine, empty is EngineAPI
+func ParsePickEngine(engine string) (PickEngine, error) {
+	switch PickEngine(engine) {
+	case "", EngineAPI:
+		return EngineAPI, nil
+	case EngineLocal, EngineMerge:
+		return PickEngine(engine), nil
+	default:
+		return "", fmt.Errorf("%w: pick engine %q, expected api, local or merge", ErrInvalidOptions, engine)
+	}
+}
+
 const (
 	GitHubProvider ProviderType = "github"
 	GitlabProvider ProviderType = "gitlab"
+	GiteaProvider  ProviderType = "gitea"
+	LocalProvider  ProviderType = "local"
 )
 
 type CreateProviderOption struct {
 	Token     string
 	BaseUrl   *string
-	UploadUrl *string // GitHub Enterprise only
+	UploadUrl *string           // GitHub Enterprise only
+	RepoPath  string            // local clone, only used for the local provider
+	Extra     map[string]string // options of providers registered outside norn
+
+	// GitHub App, used instead of the token when AppID is set
+	AppID             int64
+	AppInstallationID int64
+	AppPrivateKey     []byte // PEM encoded
+
+	// Signer signs the commits of the picks, unsigned if nil. GitLab and Gitea sign the commits
+	// by the key of the instance when it is configured, they ignore it.
+	Signer Signer
+
+	// Engine makes the picks and reverts, EngineAPI if empty. EngineLocal picks with git in the
+	// clone at RepoPath and pushes to Remote, the other services still use the API of the vendor.
+	// EngineMerge is GitHub only.
+	Engine PickEngine
+	Remote string // remote of the clone the local engine fetches from and pushes to, "origin" if empty
+	// StrategyOptions are passed to git cherry-pick as -X, such as "find-renames=30%" or
+	// "ignore-space-change". The local engine and the local provider only.
+	StrategyOptions []string
 }
 
 type Provider interface {
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePickEngine(t *testing.T) {
+	for engine, want := range map[string]PickEngine{"": EngineAPI, "api": EngineAPI, "local": EngineLocal, "merge": EngineMerge} {
+		if got, err := ParsePickEngine(engine); err != nil || got != want {
+			t.Errorf("ParsePickEngine(%q) = %q, %v, want %q", engine, got, err, want)
+		}
+	}
+	if _, err := ParsePickEngine("clone"); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("err = %v, want invalid options", err)
+	}
+}
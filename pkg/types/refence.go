@@ -13,13 +13,43 @@ type GetRefOption struct {
 	Ref  string
 }
 
+type CreateRefOption struct {
+	Repo string
+	Ref  string // branch to create, such as refs/heads/backport/1-main
+	SHA  string
+}
+
 type UpdateOption struct {
 	Repo string
 	Ref  string
 	SHA  string
+	// ExpectedSHA is the commit the ref must point to, the update fails with ErrStaleRef otherwise.
+	// The check is atomic with the update, a compare-and-swap. Empty skips the check, the update
+	// must still be a fast forward.
+	ExpectedSHA string
+	// Force allows an update which is not a fast forward, such as a reset to the parent of the head.
+	// ExpectedSHA is required, the update of a moved ref fails with ErrStaleRef. A provider which
+	// can not check ExpectedSHA atomically with the update returns ErrNotSupported.
+	Force bool
+}
+
+type ListRefOption struct {
+	Repo   string
+	Prefix string // the prefix of the branch names, such as "norn/tmp/", every branch if empty
+}
+
+type DeleteRefOption struct {
+	Repo string
+	Ref  string // branch to delete, such as refs/heads/norn/tmp/<run id>/pick-main-0123456789
 }
 
 type ReferenceService interface {
 	Get(ctx context.Context, opt *GetRefOption) (*Reference, error)
 	Update(ctx context.Context, opt *UpdateOption) (*Reference, error)
+	// Create creates the branch at the commit, ErrAlreadyExists if the branch exists
+	Create(ctx context.Context, opt *CreateRefOption) (*Reference, error)
+	// List returns the branches whose name starts with the prefix
+	List(ctx context.Context, opt *ListRefOption) ([]*Reference, error)
+	// Delete deletes the branch, NotFound if the branch does not exist
+	Delete(ctx context.Context, opt *DeleteRefOption) error
 }
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// TempRefPrefix is the prefix of the temporary branches created while picking, under refs/heads
+const TempRefPrefix = "norn/tmp/"
+
+// runIDLayout is the layout of the time a run id starts with
+const runIDLayout = "20060102T150405Z"
+
+// NewRunID returns a unique id of a run, the time of the run followed by random hex digits
+// such as "20240102T030405Z-1a2b3c4d". The temporary branches of concurrent runs never collide,
+// and the time tells how old a branch left behind by a crashed run is.
+func NewRunID() string {
+	random := make([]byte, 4)
+	_, _ = rand.Read(random)
+	return time.Now().UTC().Format(runIDLayout) + "-" + hex.EncodeToString(random)
+}
+
+// TempRef returns the temporary branch of the run, refs/heads/norn/tmp/<run id>/<name>
+func TempRef(runID, name string) string {
+	return "refs/heads/" + TempRefPrefix + runID + "/" + name
+}
+
+// ParseTempRef returns the run id of the temporary branch and the time of the run,
+// ok is false if the branch is not a temporary branch
+func ParseTempRef(ref string) (runID string, created time.Time, ok bool) {
+	name := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/"), "heads/")
+	if !strings.HasPrefix(name, TempRefPrefix) {
+		return "", time.Time{}, false
+	}
+	runID, _, ok = strings.Cut(strings.TrimPrefix(name, TempRefPrefix), "/")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	at, _, _ := strings.Cut(runID, "-")
+	created, err := time.Parse(runIDLayout, at)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return runID, created, true
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTempRef(t *testing.T) {
+	runID := NewRunID()
+	if runID == NewRunID() {
+		t.Fatalf("run ids collide")
+	}
+	ref := TempRef(runID, "pick-release/1.0-012345678")
+	got, created, ok := ParseTempRef(ref)
+	if !ok || got != runID || time.Since(created) > time.Minute {
+		t.Fatalf("ParseTempRef(%q) = %q, %v, %v", ref, got, created, ok)
+	}
+	for _, ref := range []string{
+		"refs/heads/main",
+		"refs/heads/pick-main-012345678",
+		"refs/heads/norn/tmp/run",
+		"refs/heads/norn/tmp/not-a-time/pick-main-012345678",
+	} {
+		if _, _, ok = ParseTempRef(ref); ok {
+			t.Errorf("ParseTempRef(%q) is a temporary ref", ref)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package types
 const (
 	CherryPickSummaryFlag         = "<!-- Do not edit or delete , This is a cherry-pick summary flag. | o((>ω< ))o -->"
 	CherryPickResultFlag          = "<!-- Do not edit or delete , This is a cherry-pick result flag. | o((>ω< ))o -->"
+	CherryPickRevertFlag          = "<!-- Do not edit or delete , This is a cherry-pick revert flag. | o((>ω< ))o -->"
+	CherryPickRollbackFlag        = "<!-- Do not edit or delete , This is a cherry-pick rollback flag. | o((>ω< ))o -->"
 	CherryPickTaskSummaryTemplate = "" +
 		"Will be cherry-picked to the following branches:\n\n" +
 		"{{ .Message }}\n\n" +
@@ -11,4 +13,12 @@ const (
 		"Pick Result: \n" +
 		"{{ .Message }}\n\n" +
 		CherryPickResultFlag
+	RevertResultTemplate = "" +
+		"Revert Result: \n" +
+		"{{ .Message }}\n\n" +
+		CherryPickRevertFlag
+	RollbackResultTemplate = "" +
+		"Rollback Result: \n" +
+		"{{ .Message }}\n\n" +
+		CherryPickRollbackFlag
 )